@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Geun-Oh/lx/internal/buffer"
+	"github.com/Geun-Oh/lx/internal/sink"
+	"github.com/Geun-Oh/lx/internal/source"
+)
+
+// persistRotation keeps the --persist file from growing without bound: it is
+// rotated at 256 MB, keeping one older file.
+var persistRotation = sink.Rotation{MaxSize: 256 << 20, MaxBackups: 1}
+
+// openPersist restores ring from the --persist file a previous session
+// wrote, if there is one, and opens the file to append this session's
+// lines to. The file is a JSONL capture, so --replay plays it too.
+func openPersist(ctx context.Context, path string, ring *buffer.Ring) (sink.Sink, error) {
+	if _, err := os.Stat(path); err == nil {
+		ch, err := source.NewReplaySource(path, 0).Start(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("--persist: %w", err)
+		}
+		for e := range ch {
+			ring.Push(e)
+		}
+	}
+	s, err := sink.NewFileSink(path, "json", persistRotation)
+	if err != nil {
+		return nil, fmt.Errorf("--persist: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/filter"
+	"github.com/Geun-Oh/lx/internal/monitor"
+)
+
+// report is the end-of-run summary written by --report, for CI jobs and
+// scripts. Durations are in milliseconds.
+type report struct {
+	Started      string                   `json:"started"`
+	DurationMS   float64                  `json:"duration_ms"`
+	TotalLines   uint64                   `json:"total_lines"`
+	MatchedLines uint64                   `json:"matched_lines"`
+	Levels       map[string]uint64        `json:"levels"`
+	Sources      []reportSource           `json:"sources,omitempty"`
+	Filters      []reportFilter           `json:"filters"`
+	Alerts       []reportAlert            `json:"alerts,omitempty"`
+	Firings      []reportFiring           `json:"firings,omitempty"`
+	Templates    []reportTemplate         `json:"templates,omitempty"`
+	TopMessages  []reportTemplate         `json:"top_messages,omitempty"`
+	Latency      *reportLatency           `json:"latency,omitempty"`
+	CountBy      map[string][]reportValue `json:"count_by,omitempty"`
+}
+
+type reportSource struct {
+	Source   string `json:"source"`
+	Lines    uint64 `json:"lines"`
+	Matched  uint64 `json:"matched"`
+	Filtered uint64 `json:"filtered"`
+}
+
+type reportFilter struct {
+	Name string `json:"name"`
+	Hits uint64 `json:"hits"`
+}
+
+type reportAlert struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+	Firing   bool   `json:"firing"` // still firing at the end of the run
+}
+
+type reportFiring struct {
+	Rule     string       `json:"rule"`
+	Severity string       `json:"severity"`
+	Time     string       `json:"time"`
+	Line     string       `json:"line"`
+	Resolved string       `json:"resolved,omitempty"`
+	Before   []reportLine `json:"before,omitempty"`
+	After    []reportLine `json:"after,omitempty"`
+}
+
+// reportLine is a context line of an alert firing.
+type reportLine struct {
+	Time    string `json:"time"`
+	Level   string `json:"level,omitempty"`
+	Source  string `json:"source,omitempty"`
+	Message string `json:"message"`
+}
+
+type reportTemplate struct {
+	Template string `json:"template"`
+	Count    uint64 `json:"count"`
+}
+
+type reportLatency struct {
+	Field string  `json:"field"`
+	Count uint64  `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+}
+
+type reportValue struct {
+	Value string `json:"value"`
+	Count uint64 `json:"count"`
+}
+
+// maxReportTemplates bounds the templates listed in a report.
+const maxReportTemplates = 50
+
+// writeReport writes the --report file from the session's statistics,
+// filter chain, alerts and templates (the last two may be nil).
+func writeReport(path string, stats *monitor.Stats, chain *filter.Chain, alerts *monitor.AlertEngine, templates *monitor.Drain) error {
+	elapsed := stats.Elapsed()
+	r := report{
+		Started:      time.Now().Add(-elapsed).Format(time.RFC3339Nano),
+		DurationMS:   milliseconds(elapsed),
+		TotalLines:   stats.Total(),
+		MatchedLines: stats.Matched(),
+		Levels:       make(map[string]uint64),
+		Filters:      []reportFilter{},
+	}
+	for l := entry.LevelUnknown; l <= entry.LevelFatal; l++ {
+		if n := stats.LevelCount(l); n > 0 {
+			r.Levels[l.String()] = n
+		}
+	}
+	if sources := stats.Sources(); len(sources) > 1 {
+		for _, s := range sources {
+			r.Sources = append(r.Sources, reportSource{Source: s.Source, Lines: s.Lines, Matched: s.Matched, Filtered: s.Filtered})
+		}
+	}
+	for _, h := range chain.Hits() {
+		r.Filters = append(r.Filters, reportFilter{Name: h.Name, Hits: h.Hits})
+	}
+
+	if alerts != nil {
+		counts := alerts.Counts()
+		firing := make(map[string]bool)
+		for _, name := range alerts.Active() {
+			firing[name] = true
+		}
+		for _, name := range alerts.Rules() {
+			severity, _ := alerts.Routing(name)
+			r.Alerts = append(r.Alerts, reportAlert{Rule: name, Severity: severityName(severity), Count: counts[name], Firing: firing[name]})
+		}
+		for _, f := range alerts.Firings() {
+			rf := reportFiring{
+				Rule:     f.Rule,
+				Severity: severityName(f.Severity),
+				Time:     f.Time.Format(time.RFC3339Nano),
+				Line:     f.Line,
+				Before:   reportLines(f.Before),
+				After:    reportLines(f.After),
+			}
+			if !f.Resolved.IsZero() {
+				rf.Resolved = f.Resolved.Format(time.RFC3339Nano)
+			}
+			r.Firings = append(r.Firings, rf)
+		}
+	}
+
+	if templates != nil {
+		for i, t := range templates.Templates() {
+			if i == maxReportTemplates {
+				break
+			}
+			r.Templates = append(r.Templates, reportTemplate{Template: t.Template, Count: t.Count})
+		}
+	}
+	for _, t := range stats.TopMessages() {
+		r.TopMessages = append(r.TopMessages, reportTemplate{Template: t.Template, Count: t.Count})
+	}
+
+	if lat := stats.Latency(); lat.Count() > 0 {
+		r.Latency = &reportLatency{
+			Field: lat.Field(),
+			Count: lat.Count(),
+			P50:   milliseconds(lat.Quantile(0.5)),
+			P90:   milliseconds(lat.Quantile(0.9)),
+			P99:   milliseconds(lat.Quantile(0.99)),
+		}
+	}
+	for _, g := range stats.Groups() {
+		if r.CountBy == nil {
+			r.CountBy = make(map[string][]reportValue)
+		}
+		values := []reportValue{}
+		for _, vc := range g.Counts() {
+			values = append(values, reportValue{Value: vc.Value, Count: vc.Count})
+		}
+		r.CountBy[g.Field()] = values
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep <num> and <*> readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("--report: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("--report: %w", err)
+	}
+	return nil
+}
+
+func reportLines(entries []entry.LogEntry) []reportLine {
+	var lines []reportLine
+	for _, e := range entries {
+		l := reportLine{Time: e.Timestamp.Format(time.RFC3339Nano), Source: e.Source, Message: e.Message}
+		if e.Level != entry.LevelUnknown {
+			l.Level = e.Level.String()
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// severityName spells out the default severity of rules without one.
+func severityName(s string) string {
+	if s == "" {
+		return "warning"
+	}
+	return s
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
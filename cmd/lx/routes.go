@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/filter"
+	"github.com/Geun-Oh/lx/internal/pipeline"
+	"github.com/Geun-Oh/lx/internal/sink"
+)
+
+// applyRoutes moves the sinks named by --route flags into routes carrying
+// their own filter chain. A route spec is "<sink>:<cond>[;<cond>...]" where
+// <sink> is a sink kind (stdout, file, webhook, clickhouse, statsd, ...) and
+// each <cond> is one of level=ERROR,FATAL, keyword=..., regex=...,
+// exclude=... or source=<pattern>; all conditions must match.
+func applyRoutes(sinks []sink.Sink) ([]sink.Sink, []pipeline.Route, error) {
+	if len(routeSpecs) == 0 {
+		return sinks, nil, nil
+	}
+
+	chains := make(map[string]*filter.Chain)
+	var order []string
+	for _, spec := range routeSpecs {
+		target, conds, ok := strings.Cut(spec, ":")
+		target = strings.TrimSpace(target)
+		if !ok || target == "" || strings.TrimSpace(conds) == "" {
+			return nil, nil, fmt.Errorf("invalid --route %q (want sink:key=value[;key=value])", spec)
+		}
+		chain, seen := chains[target]
+		if !seen {
+			chain = filter.NewChain(filter.MatchAll)
+			chains[target] = chain
+			order = append(order, target)
+		}
+		for _, cond := range strings.Split(conds, ";") {
+			f, err := parseRouteCondition(strings.TrimSpace(cond))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid --route %q: %w", spec, err)
+			}
+			chain.Add(f)
+		}
+	}
+
+	var plain []sink.Sink
+	var routes []pipeline.Route
+	used := make(map[string]bool)
+	for i, s := range sinks {
+		kind, _, _ := strings.Cut(s.Name(), ":")
+		target := ""
+		switch {
+		case chains[kind] != nil:
+			target = kind
+		case i == 0 && chains["stdout"] != nil:
+			target = "stdout"
+		}
+		if target == "" {
+			plain = append(plain, s)
+			continue
+		}
+		used[target] = true
+		routes = append(routes, pipeline.Route{Sink: s, Filter: chains[target]})
+	}
+
+	for _, target := range order {
+		if !used[target] {
+			return nil, nil, fmt.Errorf("--route: no %q sink is configured", target)
+		}
+	}
+	return plain, routes, nil
+}
+
+// parseRouteCondition builds the filter for one key=value route condition;
+// a leading "!" negates it.
+func parseRouteCondition(cond string) (filter.Filter, error) {
+	if rest, ok := strings.CutPrefix(strings.TrimSpace(cond), "!"); ok {
+		f, err := parseRouteCondition(rest)
+		if err != nil {
+			return nil, err
+		}
+		return filter.NewNegateFilter(f), nil
+	}
+
+	key, value, ok := strings.Cut(cond, "=")
+	if !ok || value == "" {
+		return nil, fmt.Errorf("condition %q is not key=value", cond)
+	}
+	switch strings.TrimSpace(key) {
+	case "level":
+		var lvls []entry.Level
+		for _, l := range strings.Split(value, ",") {
+			parsed := entry.ParseLevel(strings.TrimSpace(l))
+			if parsed == entry.LevelUnknown {
+				return nil, fmt.Errorf("unknown log level: %q", l)
+			}
+			lvls = append(lvls, parsed)
+		}
+		return filter.NewLevelFilter(lvls...), nil
+	case "keyword":
+		if ignoreCase {
+			return filter.NewKeywordFilterFold(value), nil
+		}
+		return filter.NewKeywordFilter(value), nil
+	case "regex":
+		return filter.NewRegexFilter(caseRegex(value))
+	case "exclude":
+		if ignoreCase {
+			return filter.NewExcludeFilterFold(value), nil
+		}
+		return filter.NewExcludeFilter(value), nil
+	case "source":
+		return filter.NewSourceFilter(value), nil
+	case "stream":
+		return filter.NewStreamFilter(strings.Split(value, ",")...), nil
+	case "glob":
+		return filter.NewGlobFilter(value)
+	case "field":
+		return filter.ParseFieldFilter(value)
+	case "cidr":
+		return filter.NewCIDRFilter(strings.Split(value, ","), ipField)
+	default:
+		return nil, fmt.Errorf("unknown condition %q (want level, keyword, regex, glob, exclude, source, stream, field or cidr)", key)
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/filter"
+	"github.com/Geun-Oh/lx/internal/monitor"
+)
+
+// rulesPollInterval is how often the filters file is checked for changes.
+const rulesPollInterval = 2 * time.Second
+
+// fileRules holds the filter sets and alert patterns loaded from
+// --filters-file. They are reloaded on SIGHUP or when the file changes,
+// without restarting the pipeline.
+type fileRules struct {
+	path   string
+	mode   filter.MatchMode
+	filter *filter.DynamicFilter
+	alerts []string // alert patterns from the file
+	engine *monitor.AlertEngine
+	mtime  time.Time
+}
+
+// loadRules reads --filters-file, or returns nil if it is not set.
+func loadRules() (*fileRules, error) {
+	if filtersFile == "" {
+		if len(filterSets) > 0 {
+			return nil, fmt.Errorf("--filter-set requires --filters-file")
+		}
+		return nil, nil
+	}
+
+	r := &fileRules{path: filtersFile, mode: filter.MatchAny}
+	if strings.EqualFold(matchMode, "and") {
+		r.mode = filter.MatchAll
+	}
+	f, fileAlerts, err := r.read()
+	if err != nil {
+		return nil, fmt.Errorf("--filters-file: %w", err)
+	}
+	r.filter = filter.NewDynamicFilter(f)
+	r.alerts = fileAlerts
+	return r, nil
+}
+
+// read compiles the selected sets into one filter, combined by --match-mode.
+func (r *fileRules) read() (filter.Filter, []string, error) {
+	if info, err := os.Stat(r.path); err == nil {
+		r.mtime = info.ModTime()
+	}
+	rf, err := filter.ReadRulesFile(r.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sets, err := rf.Compile(filterSets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", r.path, err)
+	}
+	chain := filter.NewChain(r.mode)
+	for _, set := range sets {
+		chain.Add(set.Chain)
+	}
+	return chain, rf.Alerts, nil
+}
+
+// reload re-reads the file and swaps in the new filters and alert rules.
+// On error the running rules are kept.
+func (r *fileRules) reload() error {
+	f, fileAlerts, err := r.read()
+	if err != nil {
+		return err
+	}
+	if r.engine != nil {
+		if err := r.engine.SetRules(append(append([]string(nil), alerts...), fileAlerts...)); err != nil {
+			return err
+		}
+	}
+	r.filter.Set(f)
+	r.alerts = fileAlerts
+	return nil
+}
+
+// watch reloads on SIGHUP and when the file's modification time changes.
+func (r *fileRules) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(rulesPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-ticker.C:
+			info, err := os.Stat(r.path)
+			if err != nil || info.ModTime().Equal(r.mtime) {
+				continue
+			}
+		}
+		if err := r.reload(); err != nil {
+			fmt.Fprintln(os.Stderr, "lx: reload filters:", err)
+			continue
+		}
+		fmt.Fprintln(os.Stderr, "lx: reloaded", r.path)
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/source"
+)
+
+// resolveSource determines the input source(s) from flags and args.
+// Several source flags may be combined; they are merged into a MultiSource.
+func resolveSource(args []string) (source.Source, error) {
+	if orderWindow < 0 || (orderWindow > 0 && orderWindow < time.Millisecond) {
+		return nil, fmt.Errorf("--order-window must be 0 or at least 1ms")
+	}
+	sources, err := resolveSources(args)
+	if err != nil {
+		return nil, err
+	}
+	// Per source, before merging, so dumps are not interleaved.
+	for i, s := range sources {
+		if groupPanics {
+			s = source.NewPanicGroupSource(s)
+		}
+		if groupTraces {
+			s = source.NewTracebackGroupSource(s)
+		}
+		sources[i] = s
+	}
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	return source.NewMultiSource(orderWindow, sources...), nil
+}
+
+// resolveSources collects every source configured via flags, plus stdin or
+// an exec command from args.
+func resolveSources(args []string) ([]source.Source, error) {
+	var sources []source.Source
+
+	bounds, err := resolveTimeRange()
+	if err != nil {
+		return nil, err
+	}
+
+	// File source.
+	if inputFile != "" {
+		fs := source.NewFileSource(inputFile, follow)
+		fs.SetTimeRange(bounds)
+		fs.SetTail(tailLines)
+		if checkpointPath != "" {
+			cp, err := source.LoadCheckpoint(checkpointPath)
+			if err != nil {
+				return nil, fmt.Errorf("--checkpoint: %w", err)
+			}
+			fs.SetCheckpoint(cp)
+		}
+		sources = append(sources, fs)
+	} else if checkpointPath != "" {
+		return nil, fmt.Errorf("--checkpoint needs -f")
+	}
+
+	// Slow query log.
+	if slowLogFile != "" {
+		sources = append(sources, source.NewSlowQuerySource(slowLogFile, follow))
+	}
+
+	// Docker source(s).
+	containerOpts := source.ContainerOptions{Follow: follow, Since: bounds.Since, Until: bounds.Until, Tail: tailLines}
+	for _, c := range dockerContainers {
+		sources = append(sources, source.NewDockerSource(c, containerOpts))
+	}
+
+	// Podman / auto-detected container runtime.
+	if podmanContainer != "" {
+		sources = append(sources, source.NewPodmanSource(podmanContainer, containerOpts))
+	}
+	if containerName != "" {
+		cs, err := source.NewContainerSource(containerName, containerOpts)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, cs)
+	}
+
+	// containerd / CRI logs.
+	if criTarget != "" {
+		sources = append(sources, source.NewCRISource(criTarget, containerOpts))
+	}
+
+	// Kubernetes pods by label selector.
+	if k8sSelector != "" {
+		sources = append(sources, source.NewKubernetesSource(k8sSelector, k8sNamespace, containerOpts))
+	}
+
+	// systemd journal.
+	if systemdUnit != "" {
+		sources = append(sources, source.NewJournalSource(systemdUnit, follow))
+	}
+
+	// HTTP ingestion source.
+	if httpAddr != "" {
+		sources = append(sources, source.NewHTTPSource(httpAddr))
+	}
+
+	// Raw line listeners.
+	newListener := source.NewListenSource
+	if parseGELF {
+		newListener = source.NewGELFListenSource
+	}
+	if tcpAddr != "" {
+		sources = append(sources, newListener("tcp", tcpAddr))
+	}
+	if udpAddr != "" {
+		sources = append(sources, newListener("udp", udpAddr))
+	}
+
+	// Fluentd forward protocol.
+	if forwardAddr != "" {
+		sources = append(sources, source.NewForwardSource(forwardAddr))
+	}
+
+	// Heroku logplex drain.
+	if herokuAddr != "" {
+		if (tlsCert == "") != (tlsKey == "") {
+			return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+		}
+		sources = append(sources, source.NewHerokuDrainSource(herokuAddr, tlsCert, tlsKey, herokuToken))
+	}
+
+	// Redis pub/sub channels or stream.
+	if len(redisChannels) > 0 {
+		sources = append(sources, source.NewRedisPubSubSource(redisAddr, redisChannels))
+	}
+	if redisStream != "" {
+		sources = append(sources, source.NewRedisStreamSource(redisAddr, redisStream, follow))
+	}
+
+	// NATS subjects.
+	if len(natsSubjects) > 0 {
+		sources = append(sources, source.NewNATSSource(natsURL, natsSubjects))
+	}
+
+	// Google Cloud Logging.
+	if gcpLogFilter != "" {
+		sources = append(sources, source.NewGCPLoggingSource(gcpLogFilter, gcpProject, follow))
+	}
+
+	// Object storage (S3 / GCS).
+	if objectURL != "" {
+		obj, err := source.NewObjectSource(objectURL)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, obj)
+	}
+
+	// GCP Pub/Sub subscription.
+	if pubsubSub != "" {
+		sources = append(sources, source.NewPubSubSource(pubsubSub, gcpProject, follow))
+	}
+
+	// AWS Kinesis data stream.
+	if kinesisStream != "" {
+		sources = append(sources, source.NewKinesisSource(kinesisStream, follow))
+	}
+
+	// Elasticsearch / OpenSearch query.
+	if esURL != "" {
+		es, err := source.NewElasticsearchSource(esURL, esQuery, follow)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, es)
+	}
+
+	// Replay of a JSONL capture.
+	if replayFile != "" {
+		speed, err := source.ParseReplaySpeed(replaySpeed)
+		if err != nil {
+			return nil, err
+		}
+		rs := source.NewReplaySource(replayFile, speed)
+		rs.SetTimeRange(bounds)
+		sources = append(sources, rs)
+	}
+
+	// Exec source.
+	if len(args) > 0 {
+		policy, err := source.ParseRestartPolicy(restartPolicy)
+		if err != nil {
+			return nil, err
+		}
+		es := source.NewExecSource(args[0], args[1:])
+		es.SetRestart(policy)
+		sources = append(sources, es)
+	}
+
+	// Stdin: explicit with --stdin, implicit when nothing else was given.
+	if readStdin || len(sources) == 0 {
+		stat, _ := os.Stdin.Stat()
+		if (stat.Mode() & os.ModeCharDevice) == 0 {
+			sources = append(sources, source.NewStdinSource())
+		} else if len(sources) == 0 {
+			return nil, fmt.Errorf("no command provided and stdin is not a pipe\nUsage: lx [flags] -- <command> [args...]\n   or: <command> | lx [flags]")
+		}
+	}
+
+	return sources, nil
+}
+
+// resolveTimeRange parses --since and --until.
+func resolveTimeRange() (source.TimeRange, error) {
+	var r source.TimeRange
+	var err error
+	if sinceFlag != "" {
+		if r.Since, err = parseTimeBound(sinceFlag); err != nil {
+			return r, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if untilFlag != "" {
+		if r.Until, err = parseTimeBound(untilFlag); err != nil {
+			return r, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && r.Until.Before(r.Since) {
+		return r, fmt.Errorf("--until is before --since")
+	}
+	return r, nil
+}
+
+// timeBoundLayouts are the absolute formats accepted by --since/--until.
+var timeBoundLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeOfDayLayouts are accepted by --since/--until as a time today.
+var timeOfDayLayouts = []string{"15:04:05", "15:04"}
+
+// parseTimeBound parses an absolute time, a time of day (today), or a
+// duration relative to now (e.g. "2h" or "-2h" = two hours ago).
+func parseTimeBound(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			d = -d
+		}
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range timeBoundLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range timeOfDayLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			now := time.Now()
+			return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
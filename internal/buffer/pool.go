@@ -23,6 +23,7 @@ func Get() *entry.LogEntry {
 	for k := range e.Fields {
 		delete(e.Fields, k)
 	}
+	e.Values = nil
 	e.Message = ""
 	e.Raw = e.Raw[:0]
 	e.Stream = ""
@@ -3,12 +3,16 @@ package buffer
 
 import (
 	"sync"
+	"time"
+	"unsafe"
 
 	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/filter"
 )
 
-// Ring is a fixed-capacity circular buffer for LogEntry values.
-// When full, the oldest entries are silently evicted.
+// Ring is a circular buffer for LogEntry values, bounded either by entry
+// count (NewRing) or by the approximate memory its entries take
+// (NewByteRing). When full, the oldest entries are silently evicted.
 // All operations are goroutine-safe.
 type Ring struct {
 	mu       sync.RWMutex
@@ -17,6 +21,10 @@ type Ring struct {
 	count    int // current number of entries
 	capacity int
 	dropped  uint64 // total evicted entries
+
+	maxBytes int64 // byte-bounded: the limit, 0 for a count-bounded ring
+	bytes    int64 // byte-bounded: the size of the buffered entries
+	sizes    []int // byte-bounded: the size of each slot's entry
 }
 
 // NewRing creates a ring buffer with the given capacity.
@@ -30,8 +38,48 @@ func NewRing(capacity int) *Ring {
 	}
 }
 
+// minByteRingSlots is the initial slot count of a byte-bounded ring, which
+// grows as needed.
+const minByteRingSlots = 64
+
+// NewByteRing creates a ring buffer holding as many entries as fit in
+// maxBytes (see Size), so that a burst of multi-kilobyte stack traces cannot
+// take more memory than a quiet stream of short lines. The newest entry is
+// always kept, even if it alone is larger.
+func NewByteRing(maxBytes int64) *Ring {
+	if maxBytes <= 0 {
+		maxBytes = 64 << 20
+	}
+	return &Ring{
+		entries:  make([]entry.LogEntry, minByteRingSlots),
+		sizes:    make([]int, minByteRingSlots),
+		capacity: minByteRingSlots,
+		maxBytes: maxBytes,
+	}
+}
+
+// entrySize is the memory a LogEntry value takes before its contents.
+const entrySize = int(unsafe.Sizeof(entry.LogEntry{}))
+
+// Size estimates the memory e takes in a ring: the entry itself, its
+// message, raw bytes, source and stream, and its fields and typed values.
+func Size(e *entry.LogEntry) int {
+	n := entrySize + len(e.Message) + cap(e.Raw) + len(e.Source) + len(e.Stream)
+	for k, v := range e.Fields {
+		n += len(k) + len(v) + 32 // header and bucket overhead, roughly
+	}
+	for k := range e.Values {
+		n += len(k) + 48
+	}
+	return n
+}
+
 // Push adds an entry to the ring buffer. If full, the oldest entry is evicted.
 func (r *Ring) Push(e entry.LogEntry) {
+	if r.maxBytes > 0 {
+		r.pushBytes(e)
+		return
+	}
 	r.mu.Lock()
 	r.entries[r.head] = e
 	r.head = (r.head + 1) % r.capacity
@@ -43,19 +91,98 @@ func (r *Ring) Push(e entry.LogEntry) {
 	r.mu.Unlock()
 }
 
+// pushBytes adds an entry to a byte-bounded ring, evicting the oldest
+// entries until it fits and growing the slots when they run out.
+func (r *Ring) pushBytes(e entry.LogEntry) {
+	size := Size(&e)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.count > 0 && r.bytes+int64(size) > r.maxBytes {
+		oldest := (r.head - r.count + r.capacity) % r.capacity
+		r.bytes -= int64(r.sizes[oldest])
+		r.entries[oldest], r.sizes[oldest] = entry.LogEntry{}, 0 // release it
+		r.count--
+		r.dropped++
+	}
+	if r.count == r.capacity {
+		r.grow()
+	}
+	r.entries[r.head], r.sizes[r.head] = e, size
+	r.head = (r.head + 1) % r.capacity
+	r.count++
+	r.bytes += int64(size)
+}
+
+// grow doubles the slots of a byte-bounded ring, moving the entries to the
+// start. Must be called with the lock held.
+func (r *Ring) grow() {
+	entries := make([]entry.LogEntry, 2*r.capacity)
+	sizes := make([]int, 2*r.capacity)
+	start := (r.head - r.count + r.capacity) % r.capacity
+	for i := 0; i < r.count; i++ {
+		entries[i] = r.entries[(start+i)%r.capacity]
+		sizes[i] = r.sizes[(start+i)%r.capacity]
+	}
+	r.entries, r.sizes = entries, sizes
+	r.head, r.capacity = r.count, len(entries)
+}
+
 // Snapshot returns a copy of all buffered entries in chronological order.
 func (r *Ring) Snapshot() []entry.LogEntry {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	result := make([]entry.LogEntry, r.count)
-	if r.count < r.capacity {
-		copy(result, r.entries[:r.count])
-	} else {
-		// Buffer is full: read from head (oldest) to end, then from start to head.
-		start := r.head % r.capacity
-		n := copy(result, r.entries[start:])
-		copy(result[n:], r.entries[:start])
+	start := (r.head - r.count + r.capacity) % r.capacity
+	// Read from the oldest entry to the end, then from the start.
+	n := copy(result, r.entries[start:min(start+r.count, r.capacity)])
+	copy(result[n:], r.entries[:r.count-n])
+	return result
+}
+
+// Last returns a copy of the n most recent entries in chronological order.
+func (r *Ring) Last(n int) []entry.LogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if n > r.count {
+		n = r.count
+	}
+	result := make([]entry.LogEntry, n)
+	for i := 0; i < n; i++ {
+		result[i] = r.entries[(r.head-n+i+r.capacity)%r.capacity]
+	}
+	return result
+}
+
+// Query returns copies of the buffered entries that match f (nil matches
+// all) and whose timestamp lies within from and to (a zero bound is open),
+// in chronological order. With a positive limit it returns only the limit
+// most recent matches. Only matches are copied, so querying a large buffer
+// for a few lines is cheap; the buffer stays locked for writes meanwhile,
+// and f must not modify the entries it sees.
+func (r *Ring) Query(f filter.Filter, from, to time.Time, limit int) []entry.LogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []entry.LogEntry
+	for i := 1; i <= r.count; i++ {
+		if limit > 0 && len(result) == limit {
+			break
+		}
+		e := &r.entries[(r.head-i+r.capacity)%r.capacity]
+		if !from.IsZero() && e.Timestamp.Before(from) || !to.IsZero() && e.Timestamp.After(to) {
+			continue
+		}
+		if f != nil && !f.Match(e) {
+			continue
+		}
+		result = append(result, *e)
+	}
+	// Collected newest first.
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
 	}
 	return result
 }
@@ -74,7 +201,11 @@ func (r *Ring) Dropped() uint64 {
 	return r.dropped
 }
 
-// Cap returns the buffer capacity.
+// Cap returns the buffer capacity in entries, or 0 for a byte-bounded
+// buffer, whose entry count varies.
 func (r *Ring) Cap() int {
+	if r.maxBytes > 0 {
+		return 0
+	}
 	return r.capacity
 }
@@ -4,6 +4,7 @@ package entry
 import (
 	"fmt"
 	"time"
+	"unicode/utf8"
 )
 
 // Level represents log severity levels.
@@ -57,13 +58,14 @@ func ParseLevel(s string) Level {
 // LogEntry is the normalized log message passed through the pipeline.
 type LogEntry struct {
 	Timestamp time.Time
-	Stream    string            // stdout, stderr, file, docker
-	Level     Level             // auto-detected or parsed
-	Source    string            // source identifier (filename, container, etc.)
-	Message   string            // full message text
-	Fields    map[string]string // structured fields (for JSON logs)
-	Raw       []byte            // original bytes for zero-copy processing
-	Seq       uint64            // monotonic sequence number
+	Stream    string                 // stdout, stderr, file, docker
+	Level     Level                  // auto-detected or parsed
+	Source    string                 // source identifier (filename, container, etc.)
+	Message   string                 // full message text
+	Fields    map[string]string      // structured fields (for JSON logs)
+	Values    map[string]interface{} // typed copies of fields (--types): int64, float64, bool, time.Duration
+	Raw       []byte                 // original bytes for zero-copy processing
+	Seq       uint64                 // monotonic sequence number
 }
 
 // Format returns a formatted string representation of the entry.
@@ -74,3 +76,113 @@ func (e *LogEntry) Format() string {
 	}
 	return fmt.Sprintf("[%s][%s]: %s", ts, e.Stream, e.Message)
 }
+
+// Truncate cuts the message to at most n bytes, on a UTF-8 boundary, and
+// appends a marker noting how much was dropped. n <= 0 leaves it alone.
+func (e *LogEntry) Truncate(n int) {
+	if n <= 0 || len(e.Message) <= n {
+		return
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(e.Message[cut]) {
+		cut--
+	}
+	e.Message = fmt.Sprintf("%s… [truncated %d bytes]", e.Message[:cut], len(e.Message)-cut)
+}
+
+// Sanitize strips ANSI escape sequences (colors, cursor movement, terminal
+// titles) and other non-printable control characters from the message, so
+// colored output from a child process matches keywords and renders cleanly.
+// Tabs and newlines are kept. With keepRaw the original bytes stay in Raw;
+// otherwise Raw is sanitized too.
+func (e *LogEntry) Sanitize(keepRaw bool) {
+	clean, changed := sanitize(e.Message)
+	if keepRaw {
+		if changed && len(e.Raw) == 0 {
+			e.Raw = []byte(e.Message)
+		}
+	} else if len(e.Raw) > 0 {
+		if raw, ok := sanitize(string(e.Raw)); ok {
+			e.Raw = []byte(raw)
+		}
+	}
+	e.Message = clean
+}
+
+// sanitize returns s without escape sequences and control characters, and
+// whether anything was removed.
+func sanitize(s string) (string, bool) {
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isControl(r) {
+			break
+		}
+		i += size
+	}
+	if i == len(s) {
+		return s, false
+	}
+
+	b := make([]byte, 0, len(s))
+	b = append(b, s[:i]...)
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == 0x1b:
+			i = skipEscape(s, i)
+		case r == 0x9b: // C1 control sequence introducer
+			i = skipCSI(s, i+size)
+		case isControl(r):
+			i += size
+		default:
+			b = append(b, s[i:i+size]...)
+			i += size
+		}
+	}
+	return string(b), true
+}
+
+// skipEscape returns the index after the escape sequence starting at s[i].
+func skipEscape(s string, i int) int {
+	i++ // ESC
+	if i >= len(s) {
+		return i
+	}
+	switch s[i] {
+	case '[': // CSI: parameters, then a final byte in 0x40-0x7e
+		return skipCSI(s, i+1)
+	case ']', 'P', '_', '^': // OSC, DCS, APC, PM: up to BEL or ST (ESC \)
+		for i++; i < len(s); i++ {
+			if s[i] == 0x07 {
+				return i + 1
+			}
+			if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return i
+	default: // two-byte sequence such as ESC 7 or ESC (B
+		if s[i] >= 0x20 && s[i] <= 0x2f && i+1 < len(s) {
+			i++
+		}
+		return i + 1
+	}
+}
+
+// skipCSI returns the index after the final byte of a control sequence
+// whose parameters start at s[i].
+func skipCSI(s string, i int) int {
+	for ; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return i
+}
+
+// isControl reports whether r is a C0 or C1 control character other than
+// tab and newline.
+func isControl(r rune) bool {
+	return (r < 0x20 && r != '\t' && r != '\n') || (r >= 0x7f && r <= 0x9f)
+}
@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// CaptureFilter applies a regex with named groups, copies the captures into
+// the entry's Fields and, if a condition is given, matches only when the
+// captures satisfy it. The condition uses the --where language with group
+// names as operands:
+//
+//	pattern:   status=(?P<code>\d{3}) latency=(?P<ms>\d+)ms
+//	condition: code >= 500 || ms > 1000
+type CaptureFilter struct {
+	pattern string
+	re      *regexp.Regexp
+	cond    *ExprFilter
+}
+
+// NewCaptureFilter compiles pattern, which must have at least one named
+// group, and the optional condition.
+func NewCaptureFilter(pattern, condition string) (*CaptureFilter, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	names := make(map[string]bool)
+	for _, n := range re.SubexpNames() {
+		if n != "" {
+			names[n] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("regex %q has no named groups (?P<name>...)", pattern)
+	}
+
+	f := &CaptureFilter{pattern: pattern, re: re}
+	if condition != "" {
+		if f.cond, err = compileExpr(condition, names); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Match returns true if the regex matches and the captures satisfy the
+// condition. Captures are added to Fields on every regex match.
+func (f *CaptureFilter) Match(e *entry.LogEntry) bool {
+	m := f.re.FindStringSubmatchIndex(e.Message)
+	if m == nil {
+		return false
+	}
+	for i, name := range f.re.SubexpNames() {
+		if name == "" || m[2*i] < 0 {
+			continue
+		}
+		if e.Fields == nil {
+			e.Fields = make(map[string]string)
+		}
+		e.Fields[name] = e.Message[m[2*i]:m[2*i+1]]
+	}
+	return f.cond == nil || f.cond.Match(e)
+}
+
+// Name returns the filter description.
+func (f *CaptureFilter) Name() string {
+	if f.cond != nil {
+		return "capture:" + f.pattern + " if " + f.cond.src
+	}
+	return "capture:" + f.pattern
+}
@@ -0,0 +1,91 @@
+package filter
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// CIDRFilter matches entries containing an IP address within any of its
+// ranges. Addresses are extracted from the message, or from one parsed field
+// when a field name is given (values like "10.0.0.1:443" work too). Both IPv4
+// and IPv6 are supported; a bare address is treated as a single-host range.
+type CIDRFilter struct {
+	prefixes []netip.Prefix
+	field    string
+}
+
+// ipCandidate finds tokens that may be IPv4 or IPv6 addresses; they are
+// validated with netip.ParseAddr.
+var ipCandidate = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b|[0-9a-fA-F]{0,4}(?::[0-9a-fA-F]{0,4}){2,7}(?:\.\d{1,3}){0,3}`)
+
+// NewCIDRFilter parses cidrs ("10.0.0.0/8", "2001:db8::/32", "192.168.1.7").
+// field selects a parsed field to read the address from ("" = message).
+func NewCIDRFilter(cidrs []string, field string) (*CIDRFilter, error) {
+	f := &CIDRFilter{field: field}
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if !strings.Contains(c, "/") {
+			addr, err := netip.ParseAddr(c)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", c, err)
+			}
+			f.prefixes = append(f.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", c, err)
+		}
+		f.prefixes = append(f.prefixes, p.Masked())
+	}
+	if len(f.prefixes) == 0 {
+		return nil, fmt.Errorf("no CIDR ranges given")
+	}
+	return f, nil
+}
+
+// Match returns true if any address found in the entry is within a range.
+func (f *CIDRFilter) Match(e *entry.LogEntry) bool {
+	text := e.Message
+	if f.field != "" {
+		v, ok := e.Fields[f.field]
+		if !ok {
+			return false
+		}
+		text = v
+	}
+
+	for _, m := range ipCandidate.FindAllString(text, -1) {
+		addr, err := netip.ParseAddr(m)
+		if err != nil {
+			continue
+		}
+		addr = addr.Unmap()
+		for _, p := range f.prefixes {
+			if p.Contains(addr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Name returns the filter description.
+func (f *CIDRFilter) Name() string {
+	ranges := make([]string, len(f.prefixes))
+	for i, p := range f.prefixes {
+		ranges[i] = p.String()
+	}
+	name := "cidr:" + strings.Join(ranges, ",")
+	if f.field != "" {
+		name += "@" + f.field
+	}
+	return name
+}
@@ -12,7 +12,12 @@ type ContextBuffer struct {
 	afterN     int
 	ringBuf    []entry.LogEntry // circular buffer of recent entries
 	ringPos    int
-	afterCount int // remaining "after" lines to emit
+	afterCount int // remaining "after" lines to emit (or hide, inverted)
+	matched    bool
+	rejected   bool
+
+	invert bool
+	held   []entry.LogEntry // inverted: lines that a match may still hide
 }
 
 // NewContextBuffer creates a context-aware filter wrapper.
@@ -31,10 +36,31 @@ func NewContextBuffer(f Filter, before, after int) *ContextBuffer {
 	}
 }
 
+// NewInverseContextBuffer creates the opposite of NewContextBuffer: it emits
+// every entry except matches and the before/after lines around them, to hide
+// a noisy block along with its surroundings. Entries are held back by up to
+// before lines until it is known that no match follows them; call Flush at
+// the end of the stream to emit the rest.
+func NewInverseContextBuffer(f Filter, before, after int) *ContextBuffer {
+	return &ContextBuffer{
+		filter:  f,
+		beforeN: before,
+		afterN:  after,
+		invert:  true,
+	}
+}
+
 // Process evaluates an entry and returns entries to emit (including context).
 // Returns nil if the entry should not be emitted yet.
 func (cb *ContextBuffer) Process(e *entry.LogEntry) []entry.LogEntry {
 	isMatch := cb.filter.Match(e)
+	cb.matched = isMatch
+	cb.rejected = isMatch == cb.invert
+	if cb.invert {
+		out := cb.processInverse(e, isMatch)
+		cb.matched = len(out) > 0
+		return out
+	}
 
 	// Store in ring buffer.
 	cb.ringBuf[cb.ringPos%len(cb.ringBuf)] = *e
@@ -70,7 +96,67 @@ func (cb *ContextBuffer) Process(e *entry.LogEntry) []entry.LogEntry {
 	return nil
 }
 
+// processInverse drops matches and their context and returns the entries
+// that have left the before window.
+func (cb *ContextBuffer) processInverse(e *entry.LogEntry, isMatch bool) []entry.LogEntry {
+	if isMatch {
+		// Everything held is within the before window of this match.
+		cb.held = cb.held[:0]
+		cb.afterCount = cb.afterN
+		return nil
+	}
+	if cb.afterCount > 0 {
+		cb.afterCount--
+		return nil
+	}
+	if cb.beforeN == 0 {
+		return []entry.LogEntry{*e}
+	}
+
+	cb.held = append(cb.held, *e)
+	if len(cb.held) <= cb.beforeN {
+		return nil
+	}
+	out := cb.held[0]
+	copy(cb.held, cb.held[1:])
+	cb.held = cb.held[:len(cb.held)-1]
+	return []entry.LogEntry{out}
+}
+
+// Flush returns the entries still held back at the end of the stream.
+// Only an inverse buffer holds entries.
+func (cb *ContextBuffer) Flush() []entry.LogEntry {
+	held := cb.held
+	cb.held = nil
+	return held
+}
+
+// Matched reports whether the last entry passed to Process was a match
+// rather than a context line. For an inverse buffer it reports whether
+// Process released an entry, which may be one held back earlier.
+func (cb *ContextBuffer) Matched() bool {
+	return cb.matched
+}
+
+// Rejected reports whether the filter rejected the last entry passed to
+// Process, even if it is emitted as context. For an inverse buffer that is a
+// match.
+func (cb *ContextBuffer) Rejected() bool {
+	return cb.rejected
+}
+
+// Pending returns the number of "after" context lines still to be emitted.
+func (cb *ContextBuffer) Pending() int {
+	if cb.invert {
+		return 0
+	}
+	return cb.afterCount
+}
+
 // Name returns the filter description.
 func (cb *ContextBuffer) Name() string {
+	if cb.invert {
+		return "context:inverse"
+	}
 	return "context"
 }
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"sync/atomic"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// DynamicFilter delegates to a filter that can be replaced while the
+// pipeline is running, e.g. when a filters file is reloaded. Swapping is
+// atomic, so Match never sees a half-built filter.
+type DynamicFilter struct {
+	cur atomic.Pointer[Filter]
+}
+
+// NewDynamicFilter creates a dynamic filter starting with f.
+func NewDynamicFilter(f Filter) *DynamicFilter {
+	d := &DynamicFilter{}
+	d.Set(f)
+	return d
+}
+
+// Set replaces the current filter.
+func (d *DynamicFilter) Set(f Filter) {
+	d.cur.Store(&f)
+}
+
+// Match evaluates the current filter.
+func (d *DynamicFilter) Match(e *entry.LogEntry) bool {
+	return (*d.cur.Load()).Match(e)
+}
+
+// Name returns the current filter's description.
+func (d *DynamicFilter) Name() string {
+	return (*d.cur.Load()).Name()
+}
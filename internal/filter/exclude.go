@@ -11,6 +11,7 @@ import (
 // (i.e., does NOT contain any excluded pattern).
 type ExcludeFilter struct {
 	patterns []string
+	fold     []*foldMatcher
 }
 
 // NewExcludeFilter creates a filter that rejects entries containing any of the patterns.
@@ -18,8 +19,25 @@ func NewExcludeFilter(patterns ...string) *ExcludeFilter {
 	return &ExcludeFilter{patterns: patterns}
 }
 
+// NewExcludeFilterFold is like NewExcludeFilter but ignores case.
+func NewExcludeFilterFold(patterns ...string) *ExcludeFilter {
+	f := &ExcludeFilter{patterns: patterns}
+	for _, p := range patterns {
+		f.fold = append(f.fold, newFoldMatcher(p))
+	}
+	return f
+}
+
 // Match returns true if the entry does NOT contain any excluded pattern.
 func (f *ExcludeFilter) Match(e *entry.LogEntry) bool {
+	if f.fold != nil {
+		for _, m := range f.fold {
+			if m.match(e.Message) {
+				return false
+			}
+		}
+		return true
+	}
 	for _, p := range f.patterns {
 		if strings.Contains(e.Message, p) {
 			return false
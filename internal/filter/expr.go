@@ -0,0 +1,514 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// ExprFilter matches entries against a boolean expression, e.g.
+//
+//	level >= WARN && (msg contains "timeout" || fields.status >= 500)
+//
+// Operands are entry attributes (level, msg/message, source, stream),
+// parsed fields (fields.<name>), JSON paths into a JSON message
+// (.request.duration_ms, .items[0].id), quoted strings, numbers and bare
+// words (WARN, GET). Operators: == != > >= < <= contains matches (or =~, a regex)
+// startswith endswith, combined with && / and, || / or, ! / not and
+// parentheses. An operand on its own is true when it is non-empty, so
+// `fields.user && level == ERROR` tests that the field exists.
+//
+// level compares by severity. Other ordering comparisons are numeric; when
+// either side is not a number they are false rather than an error, so a
+// malformed field never matches `> 500`.
+type ExprFilter struct {
+	src  string
+	root exprNode
+}
+
+// NewExprFilter compiles src.
+func NewExprFilter(src string) (*ExprFilter, error) {
+	return compileExpr(src, nil)
+}
+
+// compileExpr compiles src; bare words listed in fieldNames refer to those
+// fields, as if written fields.<name>.
+func compileExpr(src string, fieldNames map[string]bool) (*ExprFilter, error) {
+	toks, err := lexExpr(src)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	p := &exprParser{toks: toks, fieldNames: fieldNames}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.toks) {
+		err = fmt.Errorf("unexpected %q", p.toks[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", src, err)
+	}
+	return &ExprFilter{src: src, root: root}, nil
+}
+
+// Match evaluates the expression against e.
+func (f *ExprFilter) Match(e *entry.LogEntry) bool {
+	return f.root.eval(&exprCtx{e: e})
+}
+
+// Name returns the filter description.
+func (f *ExprFilter) Name() string {
+	return "where:" + f.src
+}
+
+// --- evaluation ---
+
+type exprNode interface {
+	eval(c *exprCtx) bool
+}
+
+// exprCtx is the entry being evaluated plus its lazily decoded JSON body.
+type exprCtx struct {
+	e       *entry.LogEntry
+	doc     interface{}
+	decoded bool
+}
+
+// json decodes the message as JSON once per evaluation. Text before the
+// first '{' (a timestamp or level prefix) is skipped.
+func (c *exprCtx) json() interface{} {
+	if !c.decoded {
+		c.decoded = true
+		c.doc = decodeJSONMessage(c.e.Message)
+		if c.doc == nil && len(c.e.Raw) > 0 {
+			// --parse-json replaced the message; the line is in Raw.
+			c.doc = decodeJSONMessage(string(c.e.Raw))
+		}
+	}
+	return c.doc
+}
+
+type andNode struct{ l, r exprNode }
+type orNode struct{ l, r exprNode }
+type notNode struct{ n exprNode }
+
+func (n andNode) eval(c *exprCtx) bool { return n.l.eval(c) && n.r.eval(c) }
+func (n orNode) eval(c *exprCtx) bool  { return n.l.eval(c) || n.r.eval(c) }
+func (n notNode) eval(c *exprCtx) bool { return !n.n.eval(c) }
+
+// operand is an attribute, field, JSON path or literal.
+type operand struct {
+	kind  string        // "level", "msg", "source", "stream", "field", "json" or "lit"
+	value string        // field name or literal text
+	path  []interface{} // JSON path: string keys and int indexes
+}
+
+// get resolves the operand for c; ok is false for a missing field or path.
+func (o operand) get(c *exprCtx) (string, bool) {
+	e := c.e
+	switch o.kind {
+	case "level":
+		return entryLevel(e).String(), true
+	case "msg":
+		return e.Message, true
+	case "source":
+		return e.Source, true
+	case "stream":
+		return e.Stream, true
+	case "field":
+		v, ok := e.Fields[o.value]
+		return v, ok
+	case "json":
+		return lookupJSON(c.json(), o.path)
+	default:
+		return o.value, true
+	}
+}
+
+// truthNode is a bare operand: true when it resolves to a non-empty value
+// (and, for JSON paths, not false).
+type truthNode struct{ o operand }
+
+func (n truthNode) eval(c *exprCtx) bool {
+	v, ok := n.o.get(c)
+	if n.o.kind == "json" && v == "false" {
+		return false
+	}
+	return ok && v != ""
+}
+
+type cmpNode struct {
+	l, r operand
+	op   string
+	re   *regexp.Regexp // for matches with a literal pattern
+}
+
+func (n cmpNode) eval(c *exprCtx) bool {
+	if n.l.kind == "level" || n.r.kind == "level" {
+		return n.evalLevel(c)
+	}
+
+	lv, lok := n.l.get(c)
+	rv, rok := n.r.get(c)
+	if !lok || !rok {
+		// A missing field only satisfies "!=".
+		return n.op == "!="
+	}
+
+	switch n.op {
+	case "==":
+		if lf, rf, ok := n.numbers(c, lv, rv); ok {
+			return lf == rf
+		}
+		return lv == rv
+	case "!=":
+		if lf, rf, ok := n.numbers(c, lv, rv); ok {
+			return lf != rf
+		}
+		return lv != rv
+	case "contains":
+		return strings.Contains(lv, rv)
+	case "startswith":
+		return strings.HasPrefix(lv, rv)
+	case "endswith":
+		return strings.HasSuffix(lv, rv)
+	case "matches":
+		if n.re != nil {
+			return n.re.MatchString(lv)
+		}
+		re, err := regexp.Compile(rv)
+		return err == nil && re.MatchString(lv)
+	}
+
+	lf, rf, ok := n.numbers(c, lv, rv)
+	if !ok {
+		return false
+	}
+	return compareOrdered(n.op, lf, rf)
+}
+
+// numbers returns both sides as numbers, if they are. Typed fields use their
+// typed value and literals may be durations, as with --field.
+func (n cmpNode) numbers(c *exprCtx, lv, rv string) (float64, float64, bool) {
+	lf, ok := n.l.number(c, lv)
+	if !ok {
+		return 0, 0, false
+	}
+	rf, ok := n.r.number(c, rv)
+	return lf, rf, ok
+}
+
+// number converts the operand's resolved value v to a number.
+func (o operand) number(c *exprCtx, v string) (float64, bool) {
+	switch o.kind {
+	case "field":
+		return fieldNumber(c.e, o.value, v)
+	case "lit":
+		return literalNumber(v)
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	return f, err == nil
+}
+
+// evalLevel compares severities; the non-level side is a level name.
+func (n cmpNode) evalLevel(c *exprCtx) bool {
+	resolve := func(o operand) entry.Level {
+		if o.kind == "level" {
+			return entryLevel(c.e)
+		}
+		v, _ := o.get(c)
+		return entry.ParseLevel(strings.ToUpper(v))
+	}
+	l, r := resolve(n.l), resolve(n.r)
+	switch n.op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case ">", ">=", "<", "<=":
+		if l == entry.LevelUnknown || r == entry.LevelUnknown {
+			return false
+		}
+		return compareOrdered(n.op, float64(l), float64(r))
+	default:
+		lv, _ := n.l.get(c)
+		rv, _ := n.r.get(c)
+		return cmpNode{l: operand{kind: "lit", value: lv}, r: operand{kind: "lit", value: rv}, op: n.op, re: n.re}.eval(c)
+	}
+}
+
+// entryLevel returns e's level, detecting (and caching) it if unset.
+func entryLevel(e *entry.LogEntry) entry.Level {
+	if e.Level == entry.LevelUnknown {
+		e.Level = DetectLevel(e.Message)
+	}
+	return e.Level
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func compareOrdered(op string, a, b float64) bool {
+	switch op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+// --- lexing ---
+
+type exprToken struct {
+	kind string // "op", "word", "str", "(", ")"
+	text string
+}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var toks []exprToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			toks = append(toks, exprToken{kind: string(c), text: string(c)})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			text := unescapeExpr(src[i+1:j], c)
+			toks = append(toks, exprToken{kind: "str", text: text})
+			i = j + 1
+		case strings.HasPrefix(src[i:], "&&"), strings.HasPrefix(src[i:], "||"),
+			strings.HasPrefix(src[i:], "=="), strings.HasPrefix(src[i:], "!="),
+			strings.HasPrefix(src[i:], ">="), strings.HasPrefix(src[i:], "<="),
+			strings.HasPrefix(src[i:], "=~"):
+			toks = append(toks, exprToken{kind: "op", text: src[i : i+2]})
+			i += 2
+		case c == '>' || c == '<' || c == '!' || c == '=':
+			toks = append(toks, exprToken{kind: "op", text: string(c)})
+			i++
+		default:
+			j := i
+			for j < len(src) && isWordByte(src[j]) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected character %q", c)
+			}
+			toks = append(toks, exprToken{kind: "word", text: src[i:j]})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+// unescapeExpr resolves \<quote>, \\, \n and \t. Other escapes are kept
+// as written so regex patterns like "5\d\d" need no doubled backslashes.
+func unescapeExpr(s string, quote byte) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case quote, '\\':
+			sb.WriteByte(s[i+1])
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		default:
+			sb.WriteByte(s[i])
+			sb.WriteByte(s[i+1])
+		}
+		i++
+	}
+	return sb.String()
+}
+
+func isWordByte(c byte) bool {
+	return c == '.' || c == '_' || c == '-' || c == '/' || c == ':' || c == '*' || c == '[' || c == ']' ||
+		unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// --- parsing ---
+
+type exprParser struct {
+	toks       []exprToken
+	pos        int
+	fieldNames map[string]bool
+}
+
+func (p *exprParser) peek() *exprToken {
+	if p.pos < len(p.toks) {
+		return &p.toks[p.pos]
+	}
+	return nil
+}
+
+// accept consumes the next token if it is one of texts (keywords match
+// case-insensitively).
+func (p *exprParser) accept(texts ...string) (string, bool) {
+	t := p.peek()
+	if t == nil || t.kind == "str" {
+		return "", false
+	}
+	for _, want := range texts {
+		if t.text == want || (t.kind == "word" && strings.EqualFold(t.text, want)) {
+			p.pos++
+			return want, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return l, nil
+		}
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = orNode{l, r}
+	}
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	l, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return l, nil
+		}
+		r, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l = andNode{l, r}
+	}
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	if _, ok := p.accept("("); ok {
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing )")
+		}
+		return n, nil
+	}
+
+	l, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "=", "!=", ">=", "<=", ">", "<", "=~",
+		"contains", "matches", "startswith", "endswith")
+	if !ok {
+		return truthNode{l}, nil
+	}
+	switch op {
+	case "=":
+		op = "=="
+	case "=~":
+		op = "matches"
+	}
+
+	r, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	n := cmpNode{l: l, r: r, op: op}
+	if op == "matches" && r.kind == "lit" {
+		re, err := regexp.Compile(r.value)
+		if err != nil {
+			return nil, fmt.Errorf("bad regex %q: %w", r.value, err)
+		}
+		n.re = re
+	}
+	return n, nil
+}
+
+func (p *exprParser) parseOperand() (operand, error) {
+	t := p.peek()
+	if t == nil {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+	switch t.kind {
+	case "str":
+		p.pos++
+		return operand{kind: "lit", value: t.text}, nil
+	case "word":
+		p.pos++
+		switch strings.ToLower(t.text) {
+		case "level":
+			return operand{kind: "level"}, nil
+		case "msg", "message":
+			return operand{kind: "msg"}, nil
+		case "source":
+			return operand{kind: "source"}, nil
+		case "stream":
+			return operand{kind: "stream"}, nil
+		}
+		if p.fieldNames[t.text] {
+			return operand{kind: "field", value: t.text}, nil
+		}
+		if name, ok := strings.CutPrefix(t.text, "fields."); ok && name != "" {
+			return operand{kind: "field", value: name}, nil
+		}
+		if strings.HasPrefix(t.text, ".") && !isNumber(t.text) {
+			path, err := parseJSONPath(t.text)
+			if err != nil {
+				return operand{}, err
+			}
+			return operand{kind: "json", value: t.text, path: path}, nil
+		}
+		return operand{kind: "lit", value: t.text}, nil
+	default:
+		return operand{}, fmt.Errorf("unexpected %q", t.text)
+	}
+}
@@ -0,0 +1,120 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// FieldFilter matches entries on a parsed field (from Grok, JSON, Kubernetes
+// labels, ...). "=" and "!=" compare as numbers when both sides are numeric
+// ("200.0" == "200") and as strings otherwise; ">", ">=", "<" and "<=" are
+// numeric only, so a non-numeric value such as "-" simply doesn't match.
+// Fields typed with --types compare by their typed value, so a duration
+// field matches "latency>250ms". Entries without the field match only "!=".
+type FieldFilter struct {
+	field string
+	op    string
+	value string
+}
+
+// NewFieldFilter creates a filter matching Fields[field] == value.
+func NewFieldFilter(field, value string) *FieldFilter {
+	return &FieldFilter{field: field, op: "==", value: value}
+}
+
+// ParseFieldFilter parses a spec as given to --field: "status=500",
+// "method!=GET", "latency_ms>250", "bytes<=1024".
+func ParseFieldFilter(spec string) (*FieldFilter, error) {
+	i := strings.IndexAny(spec, "=!<>")
+	if i <= 0 || strings.TrimSpace(spec[:i]) == "" {
+		return nil, fmt.Errorf("invalid field filter %q (want name=value, name>number, ...)", spec)
+	}
+	name, rest := strings.TrimSpace(spec[:i]), spec[i:]
+
+	var op string
+	for _, candidate := range []string{"==", "!=", ">=", "<=", "=", ">", "<"} {
+		if strings.HasPrefix(rest, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid field filter %q: unknown operator", spec)
+	}
+	value := rest[len(op):]
+	if op == "=" {
+		op = "=="
+	}
+
+	if op != "==" && op != "!=" {
+		value = strings.TrimSpace(value)
+		if _, ok := literalNumber(value); !ok {
+			return nil, fmt.Errorf("invalid field filter %q: %s needs a number or duration", spec, op)
+		}
+	}
+	return &FieldFilter{field: name, op: op, value: value}, nil
+}
+
+// Match returns true if the entry's field satisfies the comparison.
+func (f *FieldFilter) Match(e *entry.LogEntry) bool {
+	v, ok := e.Fields[f.field]
+	if !ok {
+		return f.op == "!="
+	}
+	a, aok := fieldNumber(e, f.field, v)
+	b, bok := literalNumber(f.value)
+	numeric := aok && bok
+	switch f.op {
+	case "==":
+		if numeric {
+			return a == b
+		}
+		return v == f.value
+	case "!=":
+		if numeric {
+			return a != b
+		}
+		return v != f.value
+	}
+	return numeric && compareOrdered(f.op, a, b)
+}
+
+// fieldNumber returns a field's numeric value: its typed value if --types
+// converted it (durations in seconds), else s parsed as a number.
+func fieldNumber(e *entry.LogEntry, name, s string) (float64, bool) {
+	switch v := e.Values[name].(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case time.Duration:
+		return v.Seconds(), true
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// literalNumber parses a number, or a duration such as "250ms" as seconds.
+func literalNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d.Seconds(), true
+	}
+	return 0, false
+}
+
+// Name returns the filter description.
+func (f *FieldFilter) Name() string {
+	op := f.op
+	if op == "==" {
+		op = "="
+	}
+	return "field:" + f.field + op + f.value
+}
@@ -2,6 +2,8 @@
 package filter
 
 import (
+	"sync/atomic"
+
 	"github.com/Geun-Oh/lx/internal/entry"
 )
 
@@ -24,9 +26,11 @@ const (
 	MatchAll
 )
 
-// Chain combines multiple filters with a configurable match mode.
+// Chain combines multiple filters with a configurable match mode. It counts
+// how often each filter matched.
 type Chain struct {
 	filters []Filter
+	hits    []uint64 // per filter, updated atomically
 	mode    MatchMode
 }
 
@@ -34,6 +38,7 @@ type Chain struct {
 func NewChain(mode MatchMode, filters ...Filter) *Chain {
 	return &Chain{
 		filters: filters,
+		hits:    make([]uint64, len(filters)),
 		mode:    mode,
 	}
 }
@@ -41,6 +46,7 @@ func NewChain(mode MatchMode, filters ...Filter) *Chain {
 // Add appends a filter to the chain.
 func (c *Chain) Add(f Filter) {
 	c.filters = append(c.filters, f)
+	c.hits = append(c.hits, 0)
 }
 
 // Match evaluates the chain against an entry.
@@ -52,15 +58,17 @@ func (c *Chain) Match(e *entry.LogEntry) bool {
 
 	switch c.mode {
 	case MatchAll:
-		for _, f := range c.filters {
+		for i, f := range c.filters {
 			if !f.Match(e) {
 				return false
 			}
+			atomic.AddUint64(&c.hits[i], 1)
 		}
 		return true
 	default: // MatchAny
-		for _, f := range c.filters {
+		for i, f := range c.filters {
 			if f.Match(e) {
+				atomic.AddUint64(&c.hits[i], 1)
 				return true
 			}
 		}
@@ -68,6 +76,28 @@ func (c *Chain) Match(e *entry.LogEntry) bool {
 	}
 }
 
+// FilterHits is a filter's name and the number of entries it matched.
+type FilterHits struct {
+	Name string
+	Hits uint64
+}
+
+// Hits returns how many entries each filter matched, in chain order, with
+// nested chains expanded. Evaluation stops at the first filter that decides
+// (the first match with OR, the first miss with AND), so later filters are
+// not counted for that entry.
+func (c *Chain) Hits() []FilterHits {
+	var hits []FilterHits
+	for i, f := range c.filters {
+		if nested, ok := f.(*Chain); ok {
+			hits = append(hits, nested.Hits()...)
+			continue
+		}
+		hits = append(hits, FilterHits{Name: f.Name(), Hits: atomic.LoadUint64(&c.hits[i])})
+	}
+	return hits
+}
+
 // Name returns a description of the chain.
 func (c *Chain) Name() string {
 	if c.mode == MatchAll {
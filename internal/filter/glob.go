@@ -0,0 +1,83 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// GlobFilter matches whole messages against a shell-style glob:
+// * matches any run of characters (including '/'), ? matches one character,
+// [abc], [a-z] and [!abc] match character classes, and \ escapes the next
+// character. Use *word* to match a substring.
+type GlobFilter struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// NewGlobFilter compiles pattern.
+func NewGlobFilter(pattern string) (*GlobFilter, error) {
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	return &GlobFilter{pattern: pattern, re: re}, nil
+}
+
+// Match returns true if the whole message matches the glob.
+func (f *GlobFilter) Match(e *entry.LogEntry) bool {
+	return f.re.MatchString(e.Message)
+}
+
+// Name returns the filter description.
+func (f *GlobFilter) Name() string {
+	return "glob:" + f.pattern
+}
+
+// globToRegexp translates a glob into an anchored regular expression.
+func globToRegexp(glob string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("^(?s:")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 == len(glob) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == 0 && i+2 < len(glob) {
+				// "[]...]": a leading ] is part of the class.
+				end = strings.IndexByte(glob[i+2:], ']') + 1
+			}
+			if end <= 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			sb.WriteByte('[')
+			if class[0] == '!' || class[0] == '^' {
+				sb.WriteByte('^')
+				class = class[1:]
+			}
+			sb.WriteString(strings.ReplaceAll(class, `\`, `\\`))
+			sb.WriteByte(']')
+			i += end + 1
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString(")$")
+	return sb.String(), nil
+}
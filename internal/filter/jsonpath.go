@@ -0,0 +1,111 @@
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeJSONMessage parses the JSON object or array a message contains,
+// skipping any text before it. It returns nil if there is none.
+func decodeJSONMessage(msg string) interface{} {
+	i := strings.IndexAny(msg, "{[")
+	if i < 0 {
+		return nil
+	}
+	dec := json.NewDecoder(strings.NewReader(msg[i:]))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil
+	}
+	return doc
+}
+
+// parseJSONPath splits a jq-style path such as ".request.headers[0].name"
+// into string keys and int indexes. "." alone selects the whole document.
+func parseJSONPath(path string) ([]interface{}, error) {
+	var segs []interface{}
+	rest := path
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				if rest == "" && len(segs) == 0 {
+					return segs, nil
+				}
+				if rest == "" || rest[0] != '[' {
+					return nil, fmt.Errorf("invalid JSON path %q: empty key", path)
+				}
+				continue
+			}
+			segs = append(segs, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: missing ]", path)
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: bad index %q", path, rest[1:end])
+			}
+			segs = append(segs, n)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+	}
+	return segs, nil
+}
+
+// lookupJSON walks path through doc and renders the value it finds as a
+// string: strings as-is, numbers in their original form, true/false, and
+// objects or arrays as compact JSON. Missing values and null report false.
+func lookupJSON(doc interface{}, path []interface{}) (string, bool) {
+	v := doc
+	for _, seg := range path {
+		switch seg := seg.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			if v, ok = m[seg]; !ok {
+				return "", false
+			}
+		case int:
+			a, ok := v.([]interface{})
+			if !ok || seg >= len(a) {
+				return "", false
+			}
+			v = a[seg]
+		}
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	default:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return "", false
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), true
+	}
+}
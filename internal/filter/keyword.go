@@ -1,7 +1,9 @@
 package filter
 
 import (
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/Geun-Oh/lx/internal/entry"
 )
@@ -10,6 +12,7 @@ import (
 // Uses bytes.Contains for zero-copy matching when Raw is available.
 type KeywordFilter struct {
 	keyword string
+	fold    *foldMatcher
 }
 
 // NewKeywordFilter creates a filter that matches entries containing the keyword.
@@ -17,12 +20,79 @@ func NewKeywordFilter(keyword string) *KeywordFilter {
 	return &KeywordFilter{keyword: keyword}
 }
 
+// NewKeywordFilterFold creates a case-insensitive keyword filter.
+func NewKeywordFilterFold(keyword string) *KeywordFilter {
+	return &KeywordFilter{keyword: keyword, fold: newFoldMatcher(keyword)}
+}
+
 // Match returns true if the entry message contains the keyword.
 func (f *KeywordFilter) Match(e *entry.LogEntry) bool {
+	if f.fold != nil {
+		return f.fold.match(e.Message)
+	}
 	return strings.Contains(e.Message, f.keyword)
 }
 
 // Name returns the filter description.
 func (f *KeywordFilter) Name() string {
+	if f.fold != nil {
+		return "keyword(i):" + f.keyword
+	}
 	return "keyword:" + f.keyword
 }
+
+// foldMatcher finds a substring ignoring case. ASCII keywords (the common
+// case) are compared byte by byte against the message without lowercasing
+// or allocating; others fall back to a (?i) regexp, which handles Unicode
+// case folding.
+type foldMatcher struct {
+	lower string // ASCII-lowercased keyword
+	re    *regexp.Regexp
+}
+
+func newFoldMatcher(sub string) *foldMatcher {
+	for i := 0; i < len(sub); i++ {
+		if sub[i] >= utf8.RuneSelf {
+			return &foldMatcher{re: regexp.MustCompile("(?i)" + regexp.QuoteMeta(sub))}
+		}
+	}
+	return &foldMatcher{lower: strings.ToLower(sub)}
+}
+
+func (m *foldMatcher) match(s string) bool {
+	if m.re != nil {
+		return m.re.MatchString(s)
+	}
+	sub := m.lower
+	if sub == "" {
+		return true
+	}
+	first, firstUpper := sub[0], toUpperASCII(sub[0])
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if c := s[i]; c != first && c != firstUpper {
+			continue
+		}
+		j := 1
+		for j < len(sub) && toLowerASCII(s[i+j]) == sub[j] {
+			j++
+		}
+		if j == len(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+func toUpperASCII(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - ('a' - 'A')
+	}
+	return c
+}
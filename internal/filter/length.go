@@ -0,0 +1,28 @@
+package filter
+
+import (
+	"strconv"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// LengthFilter rejects entries whose message is longer than max bytes, such
+// as minified JSON blobs or base64 dumps.
+type LengthFilter struct {
+	max int
+}
+
+// NewLengthFilter creates a filter passing messages of at most max bytes.
+func NewLengthFilter(max int) *LengthFilter {
+	return &LengthFilter{max: max}
+}
+
+// Match returns true if the message fits within the limit.
+func (f *LengthFilter) Match(e *entry.LogEntry) bool {
+	return len(e.Message) <= f.max
+}
+
+// Name returns the filter description.
+func (f *LengthFilter) Name() string {
+	return "length<=" + strconv.Itoa(f.max)
+}
@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// MultiKeywordFilter matches entries containing any (or all) of several
+// keywords as a single filter. It stops at the first keyword that decides
+// the result: the first hit in any mode, the first miss in all mode.
+type MultiKeywordFilter struct {
+	keywords []string
+	all      bool
+	fold     []*foldMatcher
+}
+
+// NewMultiKeywordFilter creates a filter over keywords; with all set every
+// keyword must be present, otherwise one is enough.
+func NewMultiKeywordFilter(all bool, keywords ...string) *MultiKeywordFilter {
+	return &MultiKeywordFilter{keywords: keywords, all: all}
+}
+
+// NewMultiKeywordFilterFold is like NewMultiKeywordFilter but ignores case.
+func NewMultiKeywordFilterFold(all bool, keywords ...string) *MultiKeywordFilter {
+	f := &MultiKeywordFilter{keywords: keywords, all: all}
+	for _, kw := range keywords {
+		f.fold = append(f.fold, newFoldMatcher(kw))
+	}
+	return f
+}
+
+// Match returns true if the message contains any (or all) of the keywords.
+func (f *MultiKeywordFilter) Match(e *entry.LogEntry) bool {
+	for i, kw := range f.keywords {
+		var found bool
+		if f.fold != nil {
+			found = f.fold[i].match(e.Message)
+		} else {
+			found = strings.Contains(e.Message, kw)
+		}
+		if found != f.all {
+			return found
+		}
+	}
+	return f.all
+}
+
+// Name returns the filter description.
+func (f *MultiKeywordFilter) Name() string {
+	mode := "any"
+	if f.all {
+		mode = "all"
+	}
+	return "keywords(" + mode + "):" + strings.Join(f.keywords, ",")
+}
@@ -0,0 +1,26 @@
+package filter
+
+import (
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// NegateFilter inverts an inner filter: it passes exactly the entries the
+// inner filter rejects.
+type NegateFilter struct {
+	inner Filter
+}
+
+// NewNegateFilter wraps f.
+func NewNegateFilter(f Filter) *NegateFilter {
+	return &NegateFilter{inner: f}
+}
+
+// Match returns true if the inner filter does NOT match.
+func (f *NegateFilter) Match(e *entry.LogEntry) bool {
+	return !f.inner.Match(e)
+}
+
+// Name returns the filter description.
+func (f *NegateFilter) Name() string {
+	return "not:" + f.inner.Name()
+}
@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// RangeFilter passes the entries between a start marker and an end marker,
+// like sed -n '/start/,/end/p'. Several ranges may follow each other; a start
+// marker inside an open range is ignored. The end pattern may refer to named
+// groups of the start pattern as ${name}, so a range can be tied to one
+// request:
+//
+//	start: request start id=(?P<id>\w+)
+//	end:   request end id=${id}
+//
+// With no start pattern the range is open from the first entry until the
+// end marker; with no end pattern it stays open to the last entry. The
+// marker lines themselves are included unless the filter is exclusive.
+//
+// The filter is stateful and must see every entry, so place it first in an
+// AND chain.
+type RangeFilter struct {
+	start     *regexp.Regexp
+	endText   string
+	end       *regexp.Regexp // end pattern of the open range
+	exclusive bool
+	open      bool
+	done      bool // a range without a start pattern has ended
+}
+
+// NewRangeFilter compiles the start and end patterns; either may be empty.
+func NewRangeFilter(start, end string, exclusive bool) (*RangeFilter, error) {
+	if start == "" && end == "" {
+		return nil, fmt.Errorf("range needs a start or end pattern")
+	}
+	f := &RangeFilter{endText: end, exclusive: exclusive}
+	if start != "" {
+		re, err := regexp.Compile(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start pattern %q: %w", start, err)
+		}
+		f.start = re
+	}
+	if end != "" {
+		var unknown []string
+		expandRefs(end, func(name string) string {
+			if f.start == nil || f.start.SubexpIndex(name) < 0 {
+				unknown = append(unknown, name)
+			}
+			return ""
+		})
+		if len(unknown) > 0 {
+			return nil, fmt.Errorf("end pattern %q refers to ${%s}, which is not a named group of the start pattern", end, strings.Join(unknown, "}, ${"))
+		}
+		// Check that the pattern compiles with its references filled in.
+		probe, err := regexp.Compile(expandRefs(end, func(string) string { return "x" }))
+		if err != nil {
+			return nil, fmt.Errorf("invalid end pattern %q: %w", end, err)
+		}
+		f.end = probe
+	}
+	f.open = f.start == nil
+	return f, nil
+}
+
+// Match returns true for entries inside a range.
+func (f *RangeFilter) Match(e *entry.LogEntry) bool {
+	if f.done {
+		return false
+	}
+
+	if !f.open {
+		m := f.start.FindStringSubmatch(e.Message)
+		if m == nil {
+			return false
+		}
+		f.open = true
+		if f.endText != "" {
+			// A pattern that only compiles with some values (e.g. "${id}{2}"
+			// when the group matched nothing) leaves the range without an end.
+			f.end, _ = regexp.Compile(expandRefs(f.endText, func(name string) string {
+				return regexp.QuoteMeta(m[f.start.SubexpIndex(name)])
+			}))
+		}
+		return !f.exclusive
+	}
+
+	if f.end != nil && f.end.MatchString(e.Message) {
+		f.open = false
+		f.done = f.start == nil
+		return !f.exclusive
+	}
+	return true
+}
+
+// Name returns the filter description.
+func (f *RangeFilter) Name() string {
+	start := ""
+	if f.start != nil {
+		start = f.start.String()
+	}
+	return "range:" + start + ".." + f.endText
+}
+
+// expandRefs replaces ${name} references in s. Unlike os.Expand it leaves a
+// bare $ alone, since that is a regex anchor.
+func expandRefs(s string, mapping func(string) string) string {
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			break
+		}
+		sb.WriteString(s[:i])
+		sb.WriteString(mapping(s[i+2 : i+j]))
+		s = s[i+j+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// SetConfig describes one named filter set in a --filters-file:
+//
+//	sets:
+//	  api-errors:
+//	    match: all                # any (default) or all
+//	    levels: [ERROR, FATAL]
+//	    keywords: [timeout, refused]
+//	    regexes: ['status=5\d\d']
+//	    excludes: [healthcheck]
+//	    where: 'fields.latency_ms > 250'
+//
+// Keywords, regexes, levels and the where expression are combined with match;
+// excludes always apply.
+type SetConfig struct {
+	Match    string   `yaml:"match"`
+	Keywords []string `yaml:"keywords"`
+	Regexes  []string `yaml:"regexes"`
+	Excludes []string `yaml:"excludes"`
+	Levels   []string `yaml:"levels"`
+	Where    string   `yaml:"where"`
+}
+
+// RulesFile is the layout of a --filters-file. Alerts are regex patterns,
+// like --alert.
+type RulesFile struct {
+	Sets   map[string]SetConfig `yaml:"sets"`
+	Alerts []string             `yaml:"alerts"`
+}
+
+// Set is a compiled named filter set.
+type Set struct {
+	Name  string
+	Chain *Chain
+}
+
+// ReadRulesFile parses a filters file.
+func ReadRulesFile(path string) (*RulesFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rf RulesFile
+	if err := yaml.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &rf, nil
+}
+
+// Compile builds the named sets, or every set (sorted by name) when names
+// is empty.
+func (rf *RulesFile) Compile(names []string) ([]Set, error) {
+	if len(rf.Sets) == 0 {
+		return nil, fmt.Errorf("no filter sets defined")
+	}
+
+	if len(names) == 0 {
+		for name := range rf.Sets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	sets := make([]Set, 0, len(names))
+	for _, name := range names {
+		cfg, ok := rf.Sets[name]
+		if !ok {
+			return nil, fmt.Errorf("no filter set %q", name)
+		}
+		chain, err := cfg.compile()
+		if err != nil {
+			return nil, fmt.Errorf("set %q: %w", name, err)
+		}
+		sets = append(sets, Set{Name: name, Chain: chain})
+	}
+	return sets, nil
+}
+
+// compile builds the set's chain.
+func (c SetConfig) compile() (*Chain, error) {
+	mode := MatchAny
+	switch strings.ToLower(c.Match) {
+	case "", "any", "or":
+	case "all", "and":
+		mode = MatchAll
+	default:
+		return nil, fmt.Errorf("invalid match %q (want any or all)", c.Match)
+	}
+
+	chain := NewChain(mode)
+	for _, kw := range c.Keywords {
+		chain.Add(NewKeywordFilter(kw))
+	}
+	for _, p := range c.Regexes {
+		rf, err := NewRegexFilter(p)
+		if err != nil {
+			return nil, err
+		}
+		chain.Add(rf)
+	}
+	if len(c.Levels) > 0 {
+		var lvls []entry.Level
+		for _, l := range c.Levels {
+			parsed := entry.ParseLevel(strings.ToUpper(strings.TrimSpace(l)))
+			if parsed == entry.LevelUnknown {
+				return nil, fmt.Errorf("unknown log level: %q", l)
+			}
+			lvls = append(lvls, parsed)
+		}
+		chain.Add(NewLevelFilter(lvls...))
+	}
+	if c.Where != "" {
+		ef, err := NewExprFilter(c.Where)
+		if err != nil {
+			return nil, err
+		}
+		chain.Add(ef)
+	}
+	if chain.Len() == 0 && len(c.Excludes) == 0 {
+		return nil, fmt.Errorf("no filters defined")
+	}
+
+	if len(c.Excludes) > 0 {
+		exclude := NewExcludeFilter(c.Excludes...)
+		if chain.Len() == 0 {
+			return NewChain(MatchAll, exclude), nil
+		}
+		return NewChain(MatchAll, exclude, chain), nil
+	}
+	return chain, nil
+}
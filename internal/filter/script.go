@@ -0,0 +1,151 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// ScriptFilter runs a Lua function per entry, for logic the flags cannot
+// express. The script defines match(e) and returns true to keep the entry:
+//
+//	local seen = 0
+//	function match(e)
+//	  if not e.fields.user then e.fields.user = e.message:match("user=(%w+)") end
+//	  seen = seen + 1
+//	  return e.level == "ERROR" and seen > 100
+//	end
+//
+// e has message, level, source, stream, time (Unix seconds) and fields.
+// Globals persist between calls, so scripts can keep state. Values assigned
+// to e.fields are copied back to the entry for later filters and sinks.
+//
+// Only the base, string, table and math libraries are loaded. An entry whose
+// call fails does not match; OnError, if set, is told about the first failure
+// only, so a broken script does not flood the terminal.
+type ScriptFilter struct {
+	name string
+
+	// OnError receives the first runtime error.
+	OnError func(error)
+
+	mu       sync.Mutex
+	state    *lua.LState
+	fn       lua.LValue
+	errCount uint64
+}
+
+// NewScriptFilter loads a script that defines match(e). name identifies it
+// in errors (usually the file name).
+func NewScriptFilter(name, src string) (*ScriptFilter, error) {
+	L := newScriptState()
+	if err := L.DoString(src); err != nil {
+		L.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	fn := L.GetGlobal("match")
+	if fn.Type() != lua.LTFunction {
+		L.Close()
+		return nil, fmt.Errorf("%s: script must define function match(e)", name)
+	}
+	return &ScriptFilter{name: name, state: L, fn: fn}, nil
+}
+
+// NewScriptExprFilter builds a script filter from a single expression or a
+// function body using e, like `e.level == "ERROR" and #e.message > 200`.
+func NewScriptExprFilter(src string) (*ScriptFilter, error) {
+	body := src
+	if !strings.HasPrefix(strings.TrimSpace(src), "return") {
+		// Prefer an expression; fall back to statements.
+		L := newScriptState()
+		if _, err := L.LoadString("return " + src); err == nil {
+			body = "return " + src
+		}
+		L.Close()
+	}
+	f, err := NewScriptFilter("script", "function match(e)\n"+body+"\nend")
+	if err != nil {
+		return nil, err
+	}
+	f.name = src
+	return f, nil
+}
+
+// newScriptState returns a Lua state with the safe standard libraries.
+func newScriptState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	return L
+}
+
+// Match calls match(e) and reports whether it returned a true value.
+func (f *ScriptFilter) Match(e *entry.LogEntry) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	L := f.state
+	fields := L.NewTable()
+	for k, v := range e.Fields {
+		fields.RawSetString(k, lua.LString(v))
+	}
+	t := L.NewTable()
+	t.RawSetString("message", lua.LString(e.Message))
+	t.RawSetString("level", lua.LString(entryLevel(e).String()))
+	t.RawSetString("source", lua.LString(e.Source))
+	t.RawSetString("stream", lua.LString(e.Stream))
+	t.RawSetString("fields", fields)
+	if !e.Timestamp.IsZero() {
+		t.RawSetString("time", lua.LNumber(float64(e.Timestamp.UnixNano())/1e9))
+	}
+
+	if err := L.CallByParam(lua.P{Fn: f.fn, NRet: 1, Protect: true}, t); err != nil {
+		f.errCount++
+		if f.errCount == 1 && f.OnError != nil {
+			if apiErr, ok := err.(*lua.ApiError); ok {
+				// Drop the stack traceback.
+				err = fmt.Errorf("%s", apiErr.Object.String())
+			}
+			f.OnError(fmt.Errorf("%s: %w", f.name, err))
+		}
+		return false
+	}
+	ret := L.Get(-1)
+	L.Pop(1)
+
+	fields.ForEach(func(k, v lua.LValue) {
+		key, ok := k.(lua.LString)
+		if !ok || v.Type() == lua.LTTable || v.Type() == lua.LTFunction {
+			return
+		}
+		val := v.String()
+		if old, ok := e.Fields[string(key)]; ok && old == val {
+			return
+		}
+		if e.Fields == nil {
+			e.Fields = make(map[string]string)
+		}
+		e.Fields[string(key)] = val
+	})
+	return lua.LVAsBool(ret)
+}
+
+// Name returns the filter description.
+func (f *ScriptFilter) Name() string {
+	return "script:" + f.name
+}
@@ -0,0 +1,34 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// SourceFilter matches entries by the name of the source they came from,
+// e.g. "docker:api" or "file:/var/log/app/*.log". The pattern may use * and
+// ? wildcards, which (unlike shell globs) also match '/'.
+type SourceFilter struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// NewSourceFilter creates a filter matching source names against pattern.
+func NewSourceFilter(pattern string) *SourceFilter {
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return &SourceFilter{pattern: pattern, re: regexp.MustCompile("^" + expr + "$")}
+}
+
+// Match returns true if the entry's source matches the pattern.
+func (f *SourceFilter) Match(e *entry.LogEntry) bool {
+	return f.re.MatchString(e.Source)
+}
+
+// Name returns the filter description.
+func (f *SourceFilter) Name() string {
+	return "source:" + f.pattern
+}
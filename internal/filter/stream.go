@@ -0,0 +1,33 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// StreamFilter matches entries from any of the given streams, such as
+// "stdout", "stderr" or "file". Names compare case-insensitively.
+type StreamFilter struct {
+	streams []string
+}
+
+// NewStreamFilter creates a filter matching the given stream names.
+func NewStreamFilter(streams ...string) *StreamFilter {
+	return &StreamFilter{streams: streams}
+}
+
+// Match returns true if the entry's stream is one of the names.
+func (f *StreamFilter) Match(e *entry.LogEntry) bool {
+	for _, s := range f.streams {
+		if strings.EqualFold(e.Stream, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns the filter description.
+func (f *StreamFilter) Name() string {
+	return "stream:" + strings.Join(f.streams, ",")
+}
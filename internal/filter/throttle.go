@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// ThrottleFilter passes at most limit entries per window for each message
+// pattern and counts the rest. Messages are grouped by a normalized form with
+// numbers and hex IDs replaced by '#', so "retry 1 of 5" and "retry 2 of 5"
+// share a budget. Windows follow entry timestamps, so replays throttle the
+// same way the live stream did.
+//
+// The filter is stateful: place it last in an AND chain so only entries that
+// passed every other filter use up the budget.
+type ThrottleFilter struct {
+	limit  int
+	window time.Duration
+
+	mu         sync.Mutex
+	buckets    map[string]*throttleBucket
+	suppressed map[string]uint64
+	total      uint64
+}
+
+type throttleBucket struct {
+	start time.Time
+	count int
+}
+
+// maxThrottleBuckets bounds the pattern map; expired windows are pruned
+// when it is exceeded.
+const maxThrottleBuckets = 10000
+
+// throttleNumber matches numbers and hex tokens containing a digit.
+var throttleNumber = regexp.MustCompile(`0[xX][0-9a-fA-F]+|[0-9a-fA-F]*[0-9][0-9a-fA-F]*`)
+
+// NewThrottleFilter creates a filter passing limit entries per pattern per window.
+func NewThrottleFilter(limit int, window time.Duration) *ThrottleFilter {
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &ThrottleFilter{
+		limit:      limit,
+		window:     window,
+		buckets:    make(map[string]*throttleBucket),
+		suppressed: make(map[string]uint64),
+	}
+}
+
+// Match returns true while the entry's pattern is within its budget.
+func (f *ThrottleFilter) Match(e *entry.LogEntry) bool {
+	key := throttleNumber.ReplaceAllString(e.Message, "#")
+	now := e.Timestamp
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	b := f.buckets[key]
+	if b == nil || now.Sub(b.start) >= f.window {
+		if b == nil && len(f.buckets) >= maxThrottleBuckets {
+			f.prune(now)
+		}
+		b = &throttleBucket{start: now}
+		f.buckets[key] = b
+	}
+	if b.count < f.limit {
+		b.count++
+		return true
+	}
+	f.suppressed[key]++
+	f.total++
+	return false
+}
+
+// prune drops buckets whose window has ended.
+func (f *ThrottleFilter) prune(now time.Time) {
+	for k, b := range f.buckets {
+		if now.Sub(b.start) >= f.window {
+			delete(f.buckets, k)
+		}
+	}
+}
+
+// Suppressed returns the number of entries dropped so far.
+func (f *ThrottleFilter) Suppressed() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.total
+}
+
+// Summary lists the most throttled patterns, or "" if nothing was dropped.
+func (f *ThrottleFilter) Summary() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.total == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(f.suppressed))
+	for k := range f.suppressed {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if f.suppressed[keys[i]] != f.suppressed[keys[j]] {
+			return f.suppressed[keys[i]] > f.suppressed[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("── Throttled ──\n")
+	for i, k := range keys {
+		if i == 10 {
+			sb.WriteString(fmt.Sprintf("  … %d more patterns\n", len(keys)-i))
+			break
+		}
+		if len(k) > 60 {
+			k = k[:57] + "..."
+		}
+		sb.WriteString(fmt.Sprintf("  %8d  %s\n", f.suppressed[keys[i]], k))
+	}
+	sb.WriteString(fmt.Sprintf("  %8d  total\n", f.total))
+	sb.WriteString("───────────────")
+	return sb.String()
+}
+
+// Name returns the filter description.
+func (f *ThrottleFilter) Name() string {
+	return fmt.Sprintf("throttle:%d/%s", f.limit, f.window)
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// TimeFilter matches entries whose timestamp falls within [since, until].
+// A zero bound leaves that side open.
+type TimeFilter struct {
+	since time.Time
+	until time.Time
+}
+
+// NewTimeFilter creates a filter on entry.Timestamp.
+func NewTimeFilter(since, until time.Time) *TimeFilter {
+	return &TimeFilter{since: since, until: until}
+}
+
+// Match returns true if the entry's timestamp is within the range.
+func (f *TimeFilter) Match(e *entry.LogEntry) bool {
+	if !f.since.IsZero() && e.Timestamp.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && e.Timestamp.After(f.until) {
+		return false
+	}
+	return true
+}
+
+// Name returns the filter description.
+func (f *TimeFilter) Name() string {
+	name := "time:"
+	if !f.since.IsZero() {
+		name += f.since.Format(time.RFC3339)
+	}
+	name += ".."
+	if !f.until.IsZero() {
+		name += f.until.Format(time.RFC3339)
+	}
+	return name
+}
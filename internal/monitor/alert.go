@@ -3,61 +3,735 @@ package monitor
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/Geun-Oh/lx/internal/buffer"
 	"github.com/Geun-Oh/lx/internal/entry"
 )
 
-// AlertRule defines a pattern that triggers an alert when matched.
+// AlertRule defines a pattern that triggers an alert when matched. With
+// Levels, only entries of those levels count. With a Threshold, it triggers
+// only once Threshold entries match within Window, and then starts counting
+// again; after triggering it stays quiet for Cooldown. Exec is a shell
+// command to run when it triggers; Severity and Notify (notifier names such
+// as slack or email; empty means all) are passed on to notifications.
+//
+// A rule with Silence watches for the opposite: it triggers when fewer than
+// Floor lines (default 1) match within Silence, e.g. when a service stops
+// logging or a heartbeat or cron job line has not been seen for five minutes.
+// Such rules see every line, not only the ones passing the filters, and
+// trigger once until the rate recovers.
+//
+// A rule with Rate triggers when at least Rate lines per second match in
+// every second for For (default 10s): sustained throughput, unlike the
+// one-second spikes of RateDetector. It also sees every line and triggers
+// once until the rate drops.
+//
+// A rule that triggered is firing until it resolves: a silence or rate rule
+// when its condition no longer holds, any other rule when nothing matched it
+// for Resolve (default its Window, or one minute).
 type AlertRule struct {
-	Name    string
-	Pattern *regexp.Regexp
-	Count   int // number of times triggered
+	Name      string
+	Pattern   *regexp.Regexp
+	Levels    []entry.Level
+	Threshold int
+	Window    time.Duration
+	Silence   time.Duration
+	Floor     int
+	Rate      float64
+	For       time.Duration
+	Cooldown  time.Duration
+	Resolve   time.Duration
+	Severity  string
+	Notify    []string
+	Exec      string
+	Count     int // number of times triggered
+
+	hits    []time.Time // recent matches, for threshold and silence rules (at most floor())
+	fired   time.Time   // last trigger, for the cooldown
+	since   time.Time   // start of watching, for silence rules
+	last    time.Time   // last match (wall clock)
+	rate    *RateDetector
+	active  bool      // firing: triggered and not resolved since
+	started time.Time // when it started firing
 }
 
 // AlertEngine evaluates log entries against a set of alert rules.
 type AlertEngine struct {
-	mu    sync.Mutex
-	rules []*AlertRule
+	mu       sync.Mutex
+	rules    []*AlertRule
+	fixed    []*AlertRule // rules from --alerts-file, kept by SetRules
+	firings  []*Firing    // the last maxFirings, oldest first
+	pending  []*Firing    // firings still collecting lines after the trigger
+	resolved []string     // rules resolved by Observe, for CheckTimed
+
+	ring          *buffer.Ring // where lines before a trigger come from
+	before, after int
+}
+
+// maxFirings bounds the firings an AlertEngine remembers.
+const maxFirings = 1000
+
+// Firing records a rule triggering: when, and the line that triggered it
+// (for silence and rate rules, a description of what happened). With
+// SetContext, the lines around the trigger are kept too. The last firing of
+// a rule gets the time the rule resolved.
+type Firing struct {
+	Rule     string
+	Severity string
+	Time     time.Time
+	Line     string
+	Before   []entry.LogEntry // lines leading up to the trigger
+	After    []entry.LogEntry // lines following it, as they arrive
+	Resolved time.Time        // zero while firing
+}
+
+// SetContext keeps up to before lines from ring that precede each trigger,
+// and the next after lines seen by Observe, with the firing. Call it before
+// entries are checked.
+func (e *AlertEngine) SetContext(ring *buffer.Ring, before, after int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ring, e.before, e.after = ring, before, after
+}
+
+// NewAlertEngine creates an alert engine with the given regex patterns (or
+// rule specs, see parseAlertRule) and predefined rules.
+func NewAlertEngine(patterns []string, rules ...*AlertRule) (*AlertEngine, error) {
+	compiled, err := compileAlertRules(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &AlertEngine{rules: append(compiled, rules...), fixed: rules}, nil
+}
+
+// SetRules replaces the pattern rules with patterns, keeping the counts,
+// recent matches and firing state of rules that are still present. Predefined rules stay. On error the current
+// rules are left untouched.
+func (e *AlertEngine) SetRules(patterns []string) error {
+	rules, err := compileAlertRules(patterns)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	old := make(map[string]*AlertRule, len(e.rules))
+	for _, r := range e.rules {
+		old[r.Name] = r
+	}
+	for _, r := range rules {
+		if o := old[r.Name]; o != nil {
+			r.Count, r.fired, r.since, r.last = o.Count, o.fired, o.since, o.last
+			r.active, r.started = o.active, o.started
+			if sameWindows(o, r) {
+				r.hits, r.rate = o.hits, o.rate
+			}
+		}
+	}
+	e.rules = append(rules, e.fixed...)
+	return nil
+}
+
+// sameWindows reports whether a and b count matches the same way, so the
+// matches and rate one recorded hold for the other.
+func sameWindows(a, b *AlertRule) bool {
+	return a.Threshold == b.Threshold && a.Window == b.Window && a.Silence == b.Silence &&
+		a.Floor == b.Floor && a.Rate == b.Rate && a.For == b.For
 }
 
-// NewAlertEngine creates an alert engine with the given regex patterns.
-func NewAlertEngine(patterns []string) (*AlertEngine, error) {
-	engine := &AlertEngine{}
+func compileAlertRules(patterns []string) ([]*AlertRule, error) {
+	var rules []*AlertRule
 	for _, p := range patterns {
-		re, err := regexp.Compile(p)
+		r, err := parseAlertRule(p)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// parseAlertRule compiles a plain regex, or a rule written as key=value
+// pairs:
+//
+//	pattern="OOM" count=5 window=60s exec="systemctl restart api"
+//	silence=2m
+//	pattern="heartbeat" silence=60s min=3
+//	pattern="backup done" every=24h
+//	levels=ERROR,FATAL rate=50 for=30s
+//
+// count defaults to 1 and window to one minute. silence makes it a silence
+// rule over all lines, or those matching pattern, with a floor of min lines;
+// every is the same as silence, for patterns expected at least that often.
+// rate makes it a sustained rate rule, for 10s by default. levels limits
+// any rule to lines of those levels; severity is info, warning or critical.
+// resolve sets how long a count rule must go without a match to resolve.
+func parseAlertRule(spec string) (*AlertRule, error) {
+	if !isRuleSpec(spec) {
+		re, err := regexp.Compile(spec)
 		if err != nil {
-			return nil, fmt.Errorf("invalid alert pattern %q: %w", p, err)
+			return nil, fmt.Errorf("invalid alert pattern %q: %w", spec, err)
+		}
+		return &AlertRule{Name: spec, Pattern: re}, nil
+	}
+
+	pairs, err := splitRuleSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alert rule %q: %w", spec, err)
+	}
+	r := &AlertRule{Name: spec, Threshold: 1, Window: time.Minute}
+	for k, v := range pairs {
+		switch k {
+		case "pattern":
+			if r.Pattern, err = regexp.Compile(v); err != nil {
+				return nil, fmt.Errorf("invalid alert pattern %q: %w", v, err)
+			}
+		case "count":
+			if r.Threshold, err = strconv.Atoi(v); err != nil || r.Threshold < 1 {
+				return nil, fmt.Errorf("invalid alert rule %q: count must be a positive integer", spec)
+			}
+		case "window":
+			if r.Window, err = time.ParseDuration(v); err != nil || r.Window <= 0 {
+				return nil, fmt.Errorf("invalid alert rule %q: window must be a positive duration", spec)
+			}
+		case "silence", "every":
+			if r.Silence != 0 {
+				return nil, fmt.Errorf("invalid alert rule %q: silence and every are the same, give one", spec)
+			}
+			if r.Silence, err = time.ParseDuration(v); err != nil || r.Silence <= 0 {
+				return nil, fmt.Errorf("invalid alert rule %q: %s must be a positive duration", spec, k)
+			}
+		case "rate":
+			if r.Rate, err = strconv.ParseFloat(v, 64); err != nil || r.Rate <= 0 {
+				return nil, fmt.Errorf("invalid alert rule %q: rate must be a positive number of lines/s", spec)
+			}
+		case "for":
+			if r.For, err = time.ParseDuration(v); err != nil || r.For < time.Second {
+				return nil, fmt.Errorf("invalid alert rule %q: for must be a duration of at least 1s", spec)
+			}
+		case "levels":
+			for _, l := range strings.Split(v, ",") {
+				parsed := entry.ParseLevel(strings.ToUpper(strings.TrimSpace(l)))
+				if parsed == entry.LevelUnknown {
+					return nil, fmt.Errorf("invalid alert rule %q: unknown log level %q", spec, l)
+				}
+				r.Levels = append(r.Levels, parsed)
+			}
+		case "min":
+			if r.Floor, err = strconv.Atoi(v); err != nil || r.Floor < 1 {
+				return nil, fmt.Errorf("invalid alert rule %q: min must be a positive integer", spec)
+			}
+		case "severity":
+			if !validSeverity(v) {
+				return nil, fmt.Errorf("invalid alert rule %q: severity must be one of %s", spec, strings.Join(Severities, ", "))
+			}
+			r.Severity = strings.ToLower(v)
+		case "resolve":
+			if r.Resolve, err = time.ParseDuration(v); err != nil || r.Resolve <= 0 {
+				return nil, fmt.Errorf("invalid alert rule %q: resolve must be a positive duration", spec)
+			}
+		case "exec":
+			r.Exec = v
+		default:
+			return nil, fmt.Errorf("invalid alert rule %q: unknown key %q (want %s)", spec, k, strings.Join(ruleKeys, ", "))
+		}
+	}
+	if r.Silence > 0 && r.Rate > 0 {
+		return nil, fmt.Errorf("invalid alert rule %q: silence and rate do not combine", spec)
+	}
+	if r.For > 0 && r.Rate == 0 {
+		return nil, fmt.Errorf("invalid alert rule %q: for needs rate", spec)
+	}
+	if r.Rate > 0 && r.For == 0 {
+		r.For = 10 * time.Second
+	}
+	if r.Floor > 0 && r.Silence == 0 {
+		return nil, fmt.Errorf("invalid alert rule %q: min needs silence", spec)
+	}
+	if r.Pattern == nil {
+		r.Pattern = matchAll
+	}
+	return r, nil
+}
+
+// ruleKeys are the keys of rules written as key=value pairs.
+var ruleKeys = []string{"pattern", "levels", "count", "window", "silence", "every", "min", "rate", "for", "resolve", "severity", "exec"}
+
+// isRuleSpec reports whether spec is written as key=value pairs rather than
+// a plain regex.
+func isRuleSpec(spec string) bool {
+	for _, k := range ruleKeys {
+		if strings.HasPrefix(spec, k+"=") {
+			return true
 		}
-		engine.rules = append(engine.rules, &AlertRule{
-			Name:    p,
-			Pattern: re,
-		})
 	}
-	return engine, nil
+	return false
+}
+
+// matchAll is the pattern of silence and rate rules watching every line.
+var matchAll = regexp.MustCompile("")
+
+// splitRuleSpec splits space-separated key=value pairs; values may be
+// double-quoted, with \" and \\ escapes.
+func splitRuleSpec(s string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("want key=value, got %q", s)
+		}
+		if !strings.HasPrefix(rest, `"`) {
+			value, tail, _ := strings.Cut(rest, " ")
+			pairs[key], s = value, tail
+			continue
+		}
+		var sb strings.Builder
+		i := 1
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+			}
+			sb.WriteByte(rest[i])
+		}
+		if i >= len(rest) {
+			return nil, fmt.Errorf("unterminated quote in %s", key)
+		}
+		pairs[key], s = sb.String(), rest[i+1:]
+	}
+	return pairs, nil
 }
 
 // Check evaluates an entry against all rules. Returns matched rule names.
 func (e *AlertEngine) Check(entry *entry.LogEntry) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if len(e.rules) == 0 {
 		return nil
 	}
 
+	t := entry.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	now := time.Now()
+	var triggered []string
+	for _, r := range e.rules {
+		if r.timed() || !r.matches(entry) {
+			continue
+		}
+		r.last = now
+		if !r.hit(t) {
+			continue
+		}
+		r.fired = t
+		r.Count++
+		r.fire(now)
+		e.record(r, t, entry, entry.Message)
+		triggered = append(triggered, r.Name)
+	}
+	return triggered
+}
+
+// fire marks r as firing, from now unless it already was.
+func (r *AlertRule) fire(now time.Time) {
+	if !r.active {
+		r.active, r.started = true, now
+	}
+}
+
+// resolve marks r as no longer firing, on its last firing too. Must be
+// called with lock held.
+func (e *AlertEngine) resolve(r *AlertRule, now time.Time) {
+	r.active = false
+	for i := len(e.firings) - 1; i >= 0; i-- {
+		if f := e.firings[i]; f.Rule == r.Name {
+			if f.Resolved.IsZero() {
+				f.Resolved = now
+			}
+			break
+		}
+	}
+	e.resolved = append(e.resolved, r.Name)
+}
+
+// resolveAfter returns how long nothing must match r, a rule neither
+// silence nor rate, before it resolves.
+func (r *AlertRule) resolveAfter() time.Duration {
+	switch {
+	case r.Resolve > 0:
+		return r.Resolve
+	case r.Window > 0:
+		return r.Window
+	}
+	return time.Minute
+}
+
+// record remembers that r triggered at t on trigger (nil for silence and
+// rate rules), described by line. Must be called with lock held.
+func (e *AlertEngine) record(r *AlertRule, t time.Time, trigger *entry.LogEntry, line string) {
+	f := &Firing{Rule: r.Name, Severity: r.Severity, Time: t, Line: line}
+	if e.ring != nil && e.before > 0 {
+		// The trigger is normally the newest line in the ring; lines
+		// released late by --before/--after context are not.
+		recent := e.ring.Last(e.before + 1)
+		if n := len(recent); n > 0 && trigger != nil &&
+			recent[n-1].Seq == trigger.Seq && recent[n-1].Timestamp.Equal(trigger.Timestamp) {
+			recent = recent[:n-1]
+		} else if n > e.before {
+			recent = recent[1:]
+		}
+		f.Before = recent
+	}
+	if e.after > 0 {
+		e.pending = append(e.pending, f)
+	}
+	if len(e.firings) >= maxFirings {
+		e.firings = append(e.firings[:0], e.firings[1:]...)
+	}
+	e.firings = append(e.firings, f)
+}
+
+// Firings returns the rules that triggered, oldest first (the last 1000).
+func (e *AlertEngine) Firings() []Firing {
+	return e.LastFirings(maxFirings)
+}
+
+// LastFirings returns the n most recent firings, oldest first.
+func (e *AlertEngine) LastFirings(n int) []Firing {
 	e.mu.Lock()
 	defer e.mu.Unlock()
+	recent := e.firings
+	if len(recent) > n {
+		recent = recent[len(recent)-n:]
+	}
+	firings := make([]Firing, len(recent))
+	for i, f := range recent {
+		firings[i] = *f
+		firings[i].After = append([]entry.LogEntry(nil), f.After...)
+	}
+	return firings
+}
 
-	var triggered []string
+// timed reports whether r is a silence or rate rule, evaluated by
+// CheckTimed over every line rather than by Check.
+func (r *AlertRule) timed() bool {
+	return r.Silence > 0 || r.Rate > 0
+}
+
+// Observe records a line for the silence and rate rules, and as context
+// after recent firings. Call it for every line, in addition to Check for the
+// matching ones.
+func (e *AlertEngine) Observe(entry *entry.LogEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	keep := e.pending[:0]
+	for _, f := range e.pending {
+		f.After = append(f.After, *entry)
+		if len(f.After) < e.after {
+			keep = append(keep, f)
+		}
+	}
+	clear(e.pending[len(keep):])
+	e.pending = keep
+
+	now := time.Now()
 	for _, r := range e.rules {
-		if r.Pattern.MatchString(entry.Message) {
-			r.Count++
-			triggered = append(triggered, r.Name)
+		if !r.timed() || !r.matches(entry) {
+			continue
+		}
+		if r.Rate > 0 {
+			if r.rate == nil {
+				r.rate = NewRateDetector(r.For+2*time.Second, 3.0)
+			}
+			r.rate.Record()
+			continue
+		}
+		r.prune(now)
+		if n := len(r.hits) - r.floor() + 1; n > 0 {
+			// Only the newest floor() matches decide a silence.
+			r.hits = append(r.hits[:0], r.hits[n:]...)
+		}
+		r.hits = append(r.hits, now)
+		r.last = now
+		if r.active && len(r.hits) >= r.floor() {
+			e.resolve(r, now)
 		}
 	}
-	return triggered
 }
 
-// Summary returns a formatted summary of alert counts.
+// CheckTimed evaluates the silence and rate rules at now, and resolves the
+// rules that stopped firing; it should be called every second. Returns the
+// names of the rules that triggered and of those that resolved since the
+// last call. A silence rule does not trigger before it has watched for its
+// whole Silence duration.
+func (e *AlertEngine) CheckTimed(now time.Time) (triggered, resolved []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		var holds bool
+		switch {
+		case r.Silence > 0:
+			if r.since.IsZero() {
+				r.since = now
+			}
+			r.prune(now)
+			holds = now.Sub(r.since) >= r.Silence && len(r.hits) < r.floor()
+			if r.active {
+				continue // Observe resets it
+			}
+		case r.Rate > 0:
+			holds = r.rate != nil && r.rate.SustainedAbove(r.Rate, r.For, now)
+			if !holds && r.active {
+				e.resolve(r, now)
+			}
+		default:
+			if r.active && now.Sub(r.last) >= r.resolveAfter() {
+				e.resolve(r, now)
+			}
+			continue
+		}
+		if !holds || r.active {
+			continue
+		}
+		if r.Cooldown > 0 && !r.fired.IsZero() && now.Sub(r.fired) < r.Cooldown {
+			continue
+		}
+		r.fired = now
+		r.Count++
+		r.fire(now)
+		e.record(r, now, nil, r.timedText())
+		triggered = append(triggered, r.Name)
+	}
+	resolved, e.resolved = e.resolved, nil
+	return triggered, resolved
+}
+
+// ResolvedEntry is the entry that resolved rules from CheckTimed are
+// reported with, e.g. "api-errors: resolved after 4m10s".
+func (e *AlertEngine) ResolvedEntry(rules []string, now time.Time) entry.LogEntry {
+	e.mu.Lock()
+	var parts []string
+	for _, r := range e.rules {
+		for _, name := range rules {
+			if r.Name == name {
+				parts = append(parts, fmt.Sprintf("%s: resolved after %s", r.Name, now.Sub(r.started).Round(time.Second)))
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	msg := strings.Join(parts, "; ")
+	return entry.LogEntry{
+		Timestamp: now,
+		Level:     entry.LevelInfo,
+		Source:    "lx",
+		Message:   msg,
+		Raw:       []byte(msg),
+	}
+}
+
+// Active returns the names of the rules currently firing.
+func (e *AlertEngine) Active() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var names []string
+	for _, r := range e.rules {
+		if r.active {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// TimedEntry is the entry that alerts from CheckTimed are reported with:
+// what went missing for how long and when it was last seen, or how fast
+// lines arrive.
+func (e *AlertEngine) TimedEntry(rules []string, now time.Time) entry.LogEntry {
+	e.mu.Lock()
+	var parts []string
+	for _, r := range e.rules {
+		for _, name := range rules {
+			if r.Name == name {
+				parts = append(parts, r.timedText())
+			}
+		}
+	}
+	e.mu.Unlock()
+
+	msg := strings.Join(parts, "; ")
+	return entry.LogEntry{
+		Timestamp: now,
+		Level:     entry.LevelWarn,
+		Source:    "lx",
+		Message:   msg,
+		Raw:       []byte(msg),
+	}
+}
+
+// timedText describes a triggered silence or rate rule, e.g. `no line
+// matching "heartbeat" for 5m0s (last seen 14:03:12)` or `lines at ERROR
+// above 50/s for 30s (61/s on average)`.
+func (r *AlertRule) timedText() string {
+	what := "no line"
+	if r.Rate > 0 {
+		what = "lines"
+	} else if r.Floor > 1 {
+		what = fmt.Sprintf("fewer than %d lines", r.Floor)
+	}
+	if r.Pattern != matchAll && r.Pattern.String() != "" {
+		what += fmt.Sprintf(" matching %q", r.Pattern.String())
+	}
+	if len(r.Levels) > 0 {
+		names := make([]string, len(r.Levels))
+		for i, l := range r.Levels {
+			names[i] = l.String()
+		}
+		what += " at " + strings.Join(names, "/")
+	}
+	if r.Rate > 0 {
+		return fmt.Sprintf("%s: %s above %g/s for %s (%.0f/s on average)", r.Name, what, r.Rate, r.For, r.rate.CurrentRate())
+	}
+	seen := "never seen"
+	if !r.last.IsZero() {
+		seen = "last seen " + r.last.Local().Format("15:04:05")
+	}
+	return fmt.Sprintf("%s: %s for %s (%s)", r.Name, what, r.Silence, seen)
+}
+
+// prune drops the matches of a silence rule older than Silence.
+func (r *AlertRule) prune(now time.Time) {
+	keep := r.hits[:0]
+	for _, h := range r.hits {
+		if now.Sub(h) < r.Silence {
+			keep = append(keep, h)
+		}
+	}
+	r.hits = keep
+}
+
+// floor returns the fewest matches within Silence that are not a silence.
+func (r *AlertRule) floor() int {
+	if r.Floor < 1 {
+		return 1
+	}
+	return r.Floor
+}
+
+// matches reports whether e has one of the rule's levels and matches its
+// pattern.
+func (r *AlertRule) matches(e *entry.LogEntry) bool {
+	if len(r.Levels) > 0 {
+		ok := false
+		for _, l := range r.Levels {
+			ok = ok || e.Level == l
+		}
+		if !ok {
+			return false
+		}
+	}
+	return r.Pattern.MatchString(e.Message)
+}
+
+// hit records a match at t and reports whether the rule triggers. Matches
+// during the cooldown are ignored.
+func (r *AlertRule) hit(t time.Time) bool {
+	if r.Cooldown > 0 && !r.fired.IsZero() && t.Sub(r.fired) < r.Cooldown {
+		return false
+	}
+	if r.Threshold <= 1 {
+		return true
+	}
+	keep := r.hits[:0]
+	for _, h := range r.hits {
+		if t.Sub(h) < r.Window {
+			keep = append(keep, h)
+		}
+	}
+	r.hits = append(keep, t)
+	if len(r.hits) < r.Threshold {
+		return false
+	}
+	r.hits = r.hits[:0]
+	return true
+}
+
+// Severities are the alert severities, lowest first. Rules without one count
+// as warning.
+var Severities = []string{"info", "warning", "critical"}
+
+// SeverityRank orders severities: 1 for info, 2 for warning (and none), 3
+// for critical, 0 for unknown names.
+func SeverityRank(s string) int {
+	if s == "" {
+		s = "warning"
+	}
+	for i, name := range Severities {
+		if strings.EqualFold(s, name) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// validSeverity reports whether s is empty or one of Severities.
+func validSeverity(s string) bool {
+	return s == "" || SeverityRank(s) > 0
+}
+
+// Severity returns the highest severity of the named rules, or "" when none
+// has one.
+func (e *AlertEngine) Severity(rules []string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	best := ""
+	for _, r := range e.rules {
+		for _, name := range rules {
+			if r.Name == name && r.Severity != "" && (best == "" || SeverityRank(r.Severity) > SeverityRank(best)) {
+				best = r.Severity
+			}
+		}
+	}
+	return best
+}
+
+// Command returns the exec command of the named rule, or "".
+func (e *AlertEngine) Command(rule string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		if r.Name == rule {
+			return r.Exec
+		}
+	}
+	return ""
+}
+
+// Routing returns the severity and notifier names of the named rule.
+func (e *AlertEngine) Routing(rule string) (severity string, notify []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range e.rules {
+		if r.Name == rule {
+			return r.Severity, r.Notify
+		}
+	}
+	return "", nil
+}
+
+// Summary returns a formatted summary of alert counts, most severe rules
+// first.
 func (e *AlertEngine) Summary() string {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -66,10 +740,22 @@ func (e *AlertEngine) Summary() string {
 		return ""
 	}
 
+	rules := append([]*AlertRule(nil), e.rules...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		return SeverityRank(rules[i].Severity) > SeverityRank(rules[j].Severity)
+	})
 	var sb strings.Builder
 	sb.WriteString("── Alerts ──\n")
-	for _, r := range e.rules {
-		sb.WriteString(fmt.Sprintf("  %-30s %d hits\n", r.Name, r.Count))
+	for _, r := range rules {
+		severity := r.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		state := ""
+		if r.active {
+			state = " (firing)"
+		}
+		sb.WriteString(fmt.Sprintf("  %-8s  %-30s %d hits%s\n", severity, r.Name, r.Count, state))
 	}
 	sb.WriteString("────────────")
 	return sb.String()
@@ -86,3 +772,27 @@ func (e *AlertEngine) TotalAlerts() int {
 	}
 	return total
 }
+
+// Rules returns the rule names in the order they were given.
+func (e *AlertEngine) Rules() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	names := make([]string, len(e.rules))
+	for i, r := range e.rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// Counts returns the number of times each rule has triggered, by rule name.
+func (e *AlertEngine) Counts() map[string]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	counts := make(map[string]int, len(e.rules))
+	for _, r := range e.rules {
+		counts[r.Name] = r.Count
+	}
+	return counts
+}
@@ -0,0 +1,62 @@
+package monitor
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+func TestSetRulesKeepsTimedState(t *testing.T) {
+	const rate, silence = "rate=5 for=1s", "silence=1m"
+	e, err := NewAlertEngine([]string{rate, silence})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	e.CheckTimed(now.Add(-50 * time.Second)) // the silence rule starts watching
+	line := entry.LogEntry{Message: "request served"}
+	for i := 0; i < 100; i++ {
+		e.Observe(&line)
+	}
+	// The lines fill the bucket before now+1s: the rate rule fires.
+	if triggered, _ := e.CheckTimed(now.Add(time.Second)); !slices.Equal(triggered, []string{rate}) {
+		t.Fatalf("triggered = %q, want the rate rule", triggered)
+	}
+
+	// Reload with the same rules, as on SIGHUP.
+	if err := e.SetRules([]string{rate, silence}); err != nil {
+		t.Fatal(err)
+	}
+
+	if triggered, resolved := e.CheckTimed(now.Add(time.Second)); len(triggered) > 0 || len(resolved) > 0 {
+		t.Errorf("after reload: triggered %q, resolved %q; want none", triggered, resolved)
+	}
+	if active := e.Active(); !slices.Equal(active, []string{rate}) {
+		t.Errorf("Active() = %q, want the rate rule", active)
+	}
+	// A minute after the silence rule started, but 15s after the last line.
+	if triggered, _ := e.CheckTimed(now.Add(15 * time.Second)); slices.Contains(triggered, silence) {
+		t.Error("silence rule fired after reload although a line arrived 15s ago")
+	}
+}
+
+func TestSetRulesKeepsThresholdMatches(t *testing.T) {
+	const rule = "pattern=timeout count=2"
+	e, err := NewAlertEngine([]string{rule})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := entry.LogEntry{Message: "upstream timeout"}
+	if got := e.Check(&line); len(got) > 0 {
+		t.Fatalf("first match triggered %q", got)
+	}
+	if err := e.SetRules([]string{rule}); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Check(&line); !slices.Equal(got, []string{rule}) {
+		t.Errorf("second match after reload triggered %q, want the rule", got)
+	}
+}
@@ -0,0 +1,175 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// AlertConfig describes one named rule in an --alerts-file:
+//
+//	rules:
+//	  - name: oom
+//	    pattern: 'OOM|out of memory'
+//	    levels: [ERROR, FATAL]
+//	    count: 5            # matches needed within window (default 1)
+//	    window: 1m          # default 1m
+//	    cooldown: 10m       # stay quiet this long after firing
+//	    resolve: 5m         # resolved after this long without a match (default window)
+//	    severity: critical  # info, warning (default) or critical
+//	    notify: [slack, exec]
+//	    exec: 'systemctl restart api'
+//	  - name: api-silent
+//	    silence: 2m         # fewer than min lines within 2m
+//	    min: 1              # default 1
+//	  - name: backup
+//	    pattern: 'backup done'
+//	    every: 24h          # same as silence: expected at least this often
+//	  - name: error-storm
+//	    levels: [ERROR, FATAL]
+//	    rate: 50            # lines/s, in every second
+//	    for: 30s            # default 10s
+//
+// A rule needs a pattern, levels, silence, rate or a combination. A silence rule
+// with a pattern or levels watches only the lines they match.
+type AlertConfig struct {
+	Name     string        `yaml:"name"`
+	Pattern  string        `yaml:"pattern"`
+	Levels   []string      `yaml:"levels"`
+	Count    int           `yaml:"count"`
+	Window   time.Duration `yaml:"window"`
+	Silence  time.Duration `yaml:"silence"`
+	Every    time.Duration `yaml:"every"`
+	Min      int           `yaml:"min"`
+	Rate     float64       `yaml:"rate"`
+	For      time.Duration `yaml:"for"`
+	Cooldown time.Duration `yaml:"cooldown"`
+	Resolve  time.Duration `yaml:"resolve"`
+	Severity string        `yaml:"severity"`
+	Notify   []string      `yaml:"notify"`
+	Exec     string        `yaml:"exec"`
+}
+
+// AlertsFile is the layout of an --alerts-file.
+type AlertsFile struct {
+	Rules []AlertConfig `yaml:"rules"`
+}
+
+// notifyTargets are the notifier names a rule may route to.
+var notifyTargets = []string{"slack", "discord", "teams", "email", "exec"}
+
+// ReadAlertsFile parses an alerts file and compiles its rules.
+func ReadAlertsFile(path string) ([]*AlertRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var af AlertsFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&af); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(af.Rules))
+	rules := make([]*AlertRule, 0, len(af.Rules))
+	for i, c := range af.Rules {
+		if c.Name == "" {
+			return nil, fmt.Errorf("%s: rule %d: missing name", path, i+1)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("%s: duplicate rule %q", path, c.Name)
+		}
+		seen[c.Name] = true
+		r, err := c.compile()
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %q: %w", path, c.Name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// compile builds the rule.
+func (c AlertConfig) compile() (*AlertRule, error) {
+	if c.Every != 0 {
+		if c.Silence != 0 {
+			return nil, fmt.Errorf("silence and every are the same, give one")
+		}
+		c.Silence = c.Every
+	}
+	if c.Pattern == "" && len(c.Levels) == 0 && c.Silence == 0 && c.Rate == 0 {
+		return nil, fmt.Errorf("needs a pattern, levels, silence or rate")
+	}
+	re, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	r := &AlertRule{
+		Name:      c.Name,
+		Pattern:   re,
+		Threshold: c.Count,
+		Window:    c.Window,
+		Silence:   c.Silence,
+		Floor:     c.Min,
+		Rate:      c.Rate,
+		For:       c.For,
+		Cooldown:  c.Cooldown,
+		Resolve:   c.Resolve,
+		Severity:  c.Severity,
+		Notify:    c.Notify,
+		Exec:      c.Exec,
+	}
+	if r.Threshold < 0 {
+		return nil, fmt.Errorf("count must not be negative")
+	}
+	if !validSeverity(r.Severity) {
+		return nil, fmt.Errorf("severity must be one of %s", strings.Join(Severities, ", "))
+	}
+	r.Severity = strings.ToLower(r.Severity)
+	if r.Silence < 0 {
+		return nil, fmt.Errorf("silence must not be negative")
+	}
+	if r.Floor < 0 || r.Floor > 0 && r.Silence == 0 {
+		return nil, fmt.Errorf("min must be positive and needs silence")
+	}
+	if r.Rate < 0 || r.Rate > 0 && r.Silence > 0 {
+		return nil, fmt.Errorf("rate must be positive and does not combine with silence")
+	}
+	if r.For != 0 && (r.Rate == 0 || r.For < time.Second) {
+		return nil, fmt.Errorf("for needs rate and must be at least 1s")
+	}
+	if r.Rate > 0 && r.For == 0 {
+		r.For = 10 * time.Second
+	}
+	if r.Window <= 0 {
+		r.Window = time.Minute
+	}
+	if r.Resolve < 0 {
+		return nil, fmt.Errorf("resolve must not be negative")
+	}
+	for _, l := range c.Levels {
+		parsed := entry.ParseLevel(strings.ToUpper(strings.TrimSpace(l)))
+		if parsed == entry.LevelUnknown {
+			return nil, fmt.Errorf("unknown log level: %q", l)
+		}
+		r.Levels = append(r.Levels, parsed)
+	}
+	for _, n := range c.Notify {
+		known := false
+		for _, t := range notifyTargets {
+			known = known || n == t
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown notify target %q (want %s)", n, strings.Join(notifyTargets, ", "))
+		}
+	}
+	return r, nil
+}
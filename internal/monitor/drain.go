@@ -0,0 +1,195 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewTemplateRule is the rule name under which new templates are alerted.
+const NewTemplateRule = "new template"
+
+// drainWildcard stands for the variable tokens of a template.
+const drainWildcard = "<*>"
+
+// Drain mines log templates online with the Drain algorithm (He et al.,
+// 2017): messages are normalized (see NormalizeMessage) and split into
+// tokens, routed through a tree by token count and their first tokens, and
+// joined to the most similar template in that leaf. Tokens where a template
+// and a joining message differ become <*>, so "user 42 logged in from web"
+// and "user 7 logged in from app" end up as "user <num> logged in from <*>".
+//
+// Templates first seen after the learning period are novel: in a deploy,
+// they are the messages the new version writes and the old one never did.
+type Drain struct {
+	mu       sync.Mutex
+	depth    int     // tokens used to route to a leaf
+	sim      float64 // fraction of equal tokens needed to join a template
+	children int     // max children per node, the rest share <*>
+
+	root     map[int]*drainNode // by token count
+	clusters []*drainCluster
+	start    time.Time
+	learn    time.Duration
+}
+
+type drainNode struct {
+	children map[string]*drainNode
+	clusters []*drainCluster
+}
+
+type drainCluster struct {
+	tokens []string
+	count  uint64
+	novel  bool
+}
+
+// NewDrain creates a template miner. Templates first seen within learn of
+// its creation are the baseline; later ones are reported as novel by Add.
+func NewDrain(learn time.Duration) *Drain {
+	return &Drain{
+		depth:    2,
+		sim:      0.5,
+		children: 100,
+		root:     make(map[int]*drainNode),
+		start:    time.Now(),
+		learn:    learn,
+	}
+}
+
+// Add assigns msg to a template, creating one if none is similar enough.
+// Returns the template and whether it is new and past the learning period.
+func (d *Drain) Add(msg string) (string, bool) {
+	tokens := strings.Fields(NormalizeMessage(msg))
+	if len(tokens) == 0 {
+		return "", false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	leaf := d.leaf(tokens)
+	var best *drainCluster
+	bestSim, bestWild := -1.0, -1
+	for _, c := range leaf.clusters {
+		sim, wild := similarity(c.tokens, tokens)
+		if sim > bestSim || sim == bestSim && wild > bestWild {
+			best, bestSim, bestWild = c, sim, wild
+		}
+	}
+	if best != nil && bestSim >= d.sim {
+		for i, t := range tokens {
+			if best.tokens[i] != t {
+				best.tokens[i] = drainWildcard
+			}
+		}
+		best.count++
+		return strings.Join(best.tokens, " "), false
+	}
+
+	c := &drainCluster{
+		tokens: append([]string(nil), tokens...),
+		count:  1,
+		novel:  time.Since(d.start) >= d.learn,
+	}
+	leaf.clusters = append(leaf.clusters, c)
+	d.clusters = append(d.clusters, c)
+	return strings.Join(c.tokens, " "), c.novel
+}
+
+// leaf walks the tree to the leaf for tokens, creating nodes on the way.
+// Must be called with lock held.
+func (d *Drain) leaf(tokens []string) *drainNode {
+	node := d.root[len(tokens)]
+	if node == nil {
+		node = &drainNode{children: make(map[string]*drainNode)}
+		d.root[len(tokens)] = node
+	}
+	for i := 0; i < d.depth && i < len(tokens); i++ {
+		key := tokens[i]
+		if strings.HasPrefix(key, "<") && strings.HasSuffix(key, ">") {
+			key = drainWildcard
+		}
+		child := node.children[key]
+		if child == nil {
+			if len(node.children) >= d.children {
+				key = drainWildcard
+				child = node.children[key]
+			}
+			if child == nil {
+				child = &drainNode{children: make(map[string]*drainNode)}
+				node.children[key] = child
+			}
+		}
+		node = child
+	}
+	return node
+}
+
+// similarity returns the fraction of tokens equal in template and tokens,
+// and the number of wildcards in template.
+func similarity(template, tokens []string) (float64, int) {
+	equal, wild := 0, 0
+	for i, t := range template {
+		switch {
+		case t == drainWildcard:
+			wild++
+		case t == tokens[i]:
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens)), wild
+}
+
+// Templates returns all templates, most frequent first.
+func (d *Drain) Templates() []TemplateCount {
+	d.mu.Lock()
+	templates := make([]TemplateCount, 0, len(d.clusters))
+	for _, c := range d.clusters {
+		templates = append(templates, TemplateCount{Template: strings.Join(c.tokens, " "), Count: c.count})
+	}
+	d.mu.Unlock()
+
+	sort.SliceStable(templates, func(i, j int) bool {
+		return templates[i].Count > templates[j].Count
+	})
+	return templates
+}
+
+// Summary returns the number of templates, the most frequent ones and those
+// first seen after the learning period.
+func (d *Drain) Summary() string {
+	templates := d.Templates()
+	if len(templates) == 0 {
+		return ""
+	}
+
+	d.mu.Lock()
+	var novel []string
+	for _, c := range d.clusters {
+		if c.novel {
+			novel = append(novel, fmt.Sprintf("  %8d  %s", c.count, truncateTemplate(strings.Join(c.tokens, " "), 100)))
+		}
+	}
+	d.mu.Unlock()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "── Templates (%d) ──\n", len(templates))
+	for i, t := range templates {
+		if i == 20 {
+			fmt.Fprintf(&sb, "  … %d more\n", len(templates)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "  %8d  %s\n", t.Count, truncateTemplate(t.Template, 100))
+	}
+	if len(novel) > 0 && d.learn > 0 {
+		fmt.Fprintf(&sb, "  New after the first %s:\n", d.learn)
+		for _, l := range novel {
+			sb.WriteString("  " + l + "\n")
+		}
+	}
+	sb.WriteString("──────────────")
+	return sb.String()
+}
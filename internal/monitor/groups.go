@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// maxFieldValues bounds the distinct values a FieldCounter keeps; further
+// values are counted as OtherValue.
+const maxFieldValues = 1000
+
+// OtherValue collects the values past the limit of a FieldCounter.
+const OtherValue = "(other)"
+
+// ValueCount is a field value and the number of entries that had it.
+type ValueCount struct {
+	Value string
+	Count uint64
+}
+
+// FieldCounter counts entries by the value of one field, e.g. status codes,
+// routes or containers. Entries without the field are not counted.
+type FieldCounter struct {
+	field string
+
+	mu     sync.Mutex
+	counts map[string]uint64
+	total  uint64
+}
+
+// NewFieldCounter creates a counter for field.
+func NewFieldCounter(field string) *FieldCounter {
+	return &FieldCounter{field: field, counts: make(map[string]uint64)}
+}
+
+// Field returns the counted field.
+func (c *FieldCounter) Field() string {
+	return c.field
+}
+
+// Record counts e under its value of the field.
+func (c *FieldCounter) Record(e *entry.LogEntry) {
+	v, ok := e.Fields[c.field]
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, seen := c.counts[v]; !seen && len(c.counts) >= maxFieldValues {
+		v = OtherValue
+	}
+	c.counts[v]++
+	c.total++
+}
+
+// Total returns the number of entries counted.
+func (c *FieldCounter) Total() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+// Counts returns the counts per value, most frequent first.
+func (c *FieldCounter) Counts() []ValueCount {
+	c.mu.Lock()
+	counts := make([]ValueCount, 0, len(c.counts))
+	for v, n := range c.counts {
+		counts = append(counts, ValueCount{Value: v, Count: n})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	return counts
+}
+
+// Line formats the n most frequent values on one line, e.g.
+// "status: 200 1201 · 404 33 · 500 4".
+func (c *FieldCounter) Line(n int) string {
+	counts := c.Counts()
+	parts := make([]string, 0, n+1)
+	for i, vc := range counts {
+		if i == n {
+			parts = append(parts, fmt.Sprintf("+%d more", len(counts)-n))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", vc.Value, vc.Count))
+	}
+	return c.field + ": " + strings.Join(parts, " · ")
+}
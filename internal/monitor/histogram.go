@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// maxHistogramBuckets bounds the buckets a LevelHistogram keeps (a day of
+// minutes); the oldest are dropped first.
+const maxHistogramBuckets = 1440
+
+// levelCounts holds one bucket's line counts, by level.
+type levelCounts [entry.LevelFatal + 1]uint64
+
+// LevelHistogram counts lines per level in fixed time buckets (one minute by
+// default) over the whole session, by the entries' timestamps, so replaying
+// a file shows its own timeline.
+type LevelHistogram struct {
+	mu      sync.Mutex
+	bucket  time.Duration
+	buckets map[int64]*levelCounts // by bucket start, in Unix nanoseconds
+}
+
+// NewLevelHistogram creates a histogram with buckets of the given width.
+func NewLevelHistogram(bucket time.Duration) *LevelHistogram {
+	if bucket <= 0 {
+		bucket = time.Minute
+	}
+	return &LevelHistogram{bucket: bucket, buckets: make(map[int64]*levelCounts)}
+}
+
+// Bucket returns the bucket width.
+func (h *LevelHistogram) Bucket() time.Duration {
+	return h.bucket
+}
+
+// Record counts e in the bucket of its timestamp (now if it has none).
+func (h *LevelHistogram) Record(e *entry.LogEntry) {
+	t := e.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	key := t.Truncate(h.bucket).UnixNano()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := h.buckets[key]
+	if c == nil {
+		if len(h.buckets) >= maxHistogramBuckets {
+			oldest := key
+			for k := range h.buckets {
+				if k < oldest {
+					oldest = k
+				}
+			}
+			if oldest == key {
+				return // older than everything kept
+			}
+			delete(h.buckets, oldest)
+		}
+		c = &levelCounts{}
+		h.buckets[key] = c
+	}
+	if e.Level >= 0 && int(e.Level) < len(c) {
+		c[e.Level]++
+	}
+}
+
+// Series returns the combined counts of levels per bucket, from the first
+// to the last bucket seen (empty buckets in between are 0), and the start
+// of the first bucket.
+func (h *LevelHistogram) Series(levels ...entry.Level) ([]uint64, time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.buckets) == 0 {
+		return nil, time.Time{}
+	}
+	keys := make([]int64, 0, len(h.buckets))
+	for k := range h.buckets {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	// A stray timestamp years off must not blow up the series.
+	last := keys[len(keys)-1]
+	first := keys[0]
+	if span := (last - first) / int64(h.bucket); span >= maxHistogramBuckets {
+		first = last - (maxHistogramBuckets-1)*int64(h.bucket)
+	}
+	series := make([]uint64, (last-first)/int64(h.bucket)+1)
+	for _, k := range keys {
+		if k < first {
+			continue
+		}
+		c := h.buckets[k]
+		for _, l := range levels {
+			series[(k-first)/int64(h.bucket)] += c[l]
+		}
+	}
+	return series, time.Unix(0, first)
+}
+
+// Summary formats the error and fatal counts per bucket: a sparkline of the
+// whole session, then the buckets with errors (the last 20). Empty when
+// there were no errors.
+func (h *LevelHistogram) Summary() string {
+	series, start := h.Series(entry.LevelError, entry.LevelFatal)
+	var peak uint64
+	peakAt := 0
+	for i, n := range series {
+		if n > peak {
+			peak, peakAt = n, i
+		}
+	}
+	if peak == 0 {
+		return ""
+	}
+
+	layout := "15:04"
+	if h.bucket < time.Minute {
+		layout = "15:04:05"
+	}
+	at := func(i int) string {
+		return start.Add(time.Duration(i) * h.bucket).Local().Format(layout)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  Errors by %s: %s (peak %d at %s)\n", bucketName(h.bucket), Sparkline(series, 60), peak, at(peakAt))
+	var rows []string
+	for i, n := range series {
+		if n > 0 {
+			bar := strings.Repeat("█", int((n*30+peak-1)/peak))
+			rows = append(rows, fmt.Sprintf("    %s  %-30s %d\n", at(i), bar, n))
+		}
+	}
+	if len(rows) > 20 {
+		fmt.Fprintf(&sb, "    … %d earlier\n", len(rows)-20)
+		rows = rows[len(rows)-20:]
+	}
+	for _, r := range rows {
+		sb.WriteString(r)
+	}
+	return sb.String()
+}
+
+// bucketName names a bucket width: "minute" for 1m, else the duration.
+func bucketName(d time.Duration) string {
+	switch d {
+	case time.Second:
+		return "second"
+	case time.Minute:
+		return "minute"
+	case time.Hour:
+		return "hour"
+	}
+	return d.String()
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as block characters, at most width of them: longer
+// series are shrunk by summing neighbouring values.
+func Sparkline(values []uint64, width int) string {
+	if width > 0 && len(values) > width {
+		per := (len(values) + width - 1) / width
+		shrunk := make([]uint64, 0, width)
+		for i := 0; i < len(values); i += per {
+			var sum uint64
+			for j := i; j < i+per && j < len(values); j++ {
+				sum += values[j]
+			}
+			shrunk = append(shrunk, sum)
+		}
+		values = shrunk
+	}
+
+	var max uint64
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		switch {
+		case v == 0:
+			sb.WriteRune(' ')
+		default:
+			sb.WriteRune(sparkBlocks[int(v*uint64(len(sparkBlocks)-1)/max)])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// latencyKeys are the fields holding a request duration, as named by
+// access-log Grok patterns and common JSON loggers, in order of preference.
+var latencyKeys = []string{
+	"latency_ms", "duration_ms", "response_time_ms", "elapsed_ms", "took_ms",
+	"latency", "duration", "response_time", "elapsed", "took",
+	"request_time", "upstream_response_time",
+}
+
+// Latency tracks streaming percentiles of a duration field.
+type Latency struct {
+	mu     sync.Mutex
+	keys   []string
+	digest *TDigest
+	field  string // last field a value was read from
+}
+
+// NewLatency creates a tracker reading the first of keys an entry has; no
+// keys means the usual duration fields (latency_ms, duration, request_time,
+// ...).
+func NewLatency(keys ...string) *Latency {
+	if len(keys) == 0 {
+		keys = latencyKeys
+	}
+	return &Latency{keys: keys, digest: NewTDigest(100)}
+}
+
+// Record adds the duration of e, if it has one.
+func (l *Latency) Record(e *entry.LogEntry) {
+	for _, k := range l.keys {
+		d, ok := durationValue(e, k)
+		if !ok {
+			continue
+		}
+		l.mu.Lock()
+		l.digest.Add(float64(d))
+		l.field = k
+		l.mu.Unlock()
+		return
+	}
+}
+
+// Count returns the number of durations recorded.
+func (l *Latency) Count() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.digest.Count()
+}
+
+// Field returns the field durations were last read from.
+func (l *Latency) Field() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.field
+}
+
+// Quantile returns the estimated duration at quantile q (e.g. 0.99).
+func (l *Latency) Quantile(q float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Duration(l.digest.Quantile(q))
+}
+
+// String formats p50, p90 and p99, e.g. "p50 12ms p90 48ms p99 210ms".
+func (l *Latency) String() string {
+	return "p50 " + formatLatency(l.Quantile(0.5)) +
+		" p90 " + formatLatency(l.Quantile(0.9)) +
+		" p99 " + formatLatency(l.Quantile(0.99))
+}
+
+// durationValue reads field k of e as a duration: a --types duration or
+// number, or text like "31ms" or "0.031". Plain numbers are in the unit the
+// field name ends with (_ms, _us, _ns, _s), seconds for nginx's
+// request_time and upstream_response_time, and milliseconds otherwise.
+func durationValue(e *entry.LogEntry, k string) (time.Duration, bool) {
+	switch v := e.Values[k].(type) {
+	case time.Duration:
+		return v, true
+	case int64:
+		return time.Duration(float64(v) * float64(durationUnit(k))), true
+	case float64:
+		return time.Duration(v * float64(durationUnit(k))), true
+	}
+	s, ok := e.Fields[k]
+	if !ok || s == "" || s == "-" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return time.Duration(f * float64(durationUnit(k))), true
+}
+
+// durationUnit guesses the unit of a numeric duration field from its name.
+func durationUnit(k string) time.Duration {
+	k = strings.ToLower(k)
+	switch {
+	case strings.HasSuffix(k, "ms"):
+		return time.Millisecond
+	case strings.HasSuffix(k, "us"):
+		return time.Microsecond
+	case strings.HasSuffix(k, "ns"):
+		return time.Nanosecond
+	case strings.HasSuffix(k, "_s"), strings.HasSuffix(k, "_sec"), strings.HasSuffix(k, "_secs"),
+		strings.HasSuffix(k, "_seconds"), k == "request_time", k == "upstream_response_time":
+		return time.Second
+	}
+	return time.Millisecond
+}
+
+// formatLatency rounds d to three significant digits or so.
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -1,17 +1,177 @@
 package monitor
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// SpikeMethod selects how a RateDetector decides that the rate spikes.
+type SpikeMethod int
+
+const (
+	// SpikeRatio flags a second with more than threshold times the average
+	// of the window.
+	SpikeRatio SpikeMethod = iota
+
+	// SpikeEWMA flags a second more than threshold standard deviations above
+	// an exponentially weighted moving average of the per-second counts. The
+	// average follows slow ramps, and a one-second blip moves it only by
+	// alpha, so it does not skew the baseline for the rest of the window.
+	SpikeEWMA
 )
 
-// RateDetector tracks event rates and detects spikes using a sliding window.
+// ParseSpikeMethod parses "ratio" or "ewma".
+func ParseSpikeMethod(s string) (SpikeMethod, error) {
+	switch s {
+	case "ratio":
+		return SpikeRatio, nil
+	case "ewma":
+		return SpikeEWMA, nil
+	}
+	return 0, fmt.Errorf("unknown spike method %q (want ratio or ewma)", s)
+}
+
+// ewmaWarmup is the number of buckets the EWMA must have seen before it
+// reports spikes.
+const ewmaWarmup = 5
+
+// SpikeConfig configures spike detection (see NewSpikeDetector).
+type SpikeConfig struct {
+	Method    SpikeMethod
+	Threshold float64       // multiplier for SpikeRatio, z-score for SpikeEWMA
+	Alpha     float64       // weight of each new bucket in the SpikeEWMA average
+	Window    time.Duration // history the baseline is taken from
+	Bucket    time.Duration // granularity of the counts compared
+	MinRate   float64       // lines/s the baseline counts as at least
+	Levels    []entry.Level // levels counted by AddSpikeDetector; none means all
+}
+
+// DefaultSpikeConfig returns the default spike detection: a second over 3
+// times the average of the last 30 seconds.
+func DefaultSpikeConfig() SpikeConfig {
+	return SpikeConfig{
+		Method:    SpikeRatio,
+		Threshold: 3,
+		Alpha:     0.3,
+		Window:    30 * time.Second,
+		Bucket:    time.Second,
+	}
+}
+
+// spikeKeys are the keys of detectors written as key=value pairs.
+var spikeKeys = []string{"levels", "method", "threshold", "alpha", "window", "bucket", "min"}
+
+// ParseSpikeConfig parses a detector written as key=value pairs, taking
+// unset keys from base:
+//
+//	levels=ERROR,FATAL threshold=5 window=2m bucket=5s min=0.5
+//	levels=WARN method=ewma threshold=4 alpha=0.1
+//
+// min is the baseline rate in lines/s below which the baseline is not
+// taken to fall, so a burst in a quiet log is not a spike unless it clears
+// threshold times min.
+func ParseSpikeConfig(spec string, base SpikeConfig) (SpikeConfig, error) {
+	pairs, err := splitRuleSpec(spec)
+	if err != nil {
+		return base, fmt.Errorf("invalid spike detector %q: %w", spec, err)
+	}
+	c := base
+	c.Levels = nil
+	for k, v := range pairs {
+		switch k {
+		case "levels":
+			for _, l := range strings.Split(v, ",") {
+				parsed := entry.ParseLevel(strings.ToUpper(strings.TrimSpace(l)))
+				if parsed == entry.LevelUnknown {
+					return base, fmt.Errorf("invalid spike detector %q: unknown log level %q", spec, l)
+				}
+				c.Levels = append(c.Levels, parsed)
+			}
+		case "method":
+			if c.Method, err = ParseSpikeMethod(v); err != nil {
+				return base, fmt.Errorf("invalid spike detector %q: %w", spec, err)
+			}
+		case "threshold":
+			if c.Threshold, err = strconv.ParseFloat(v, 64); err != nil || c.Threshold <= 0 {
+				return base, fmt.Errorf("invalid spike detector %q: threshold must be positive", spec)
+			}
+		case "alpha":
+			if c.Alpha, err = strconv.ParseFloat(v, 64); err != nil || c.Alpha <= 0 || c.Alpha > 1 {
+				return base, fmt.Errorf("invalid spike detector %q: alpha must be in (0, 1]", spec)
+			}
+		case "window":
+			if c.Window, err = time.ParseDuration(v); err != nil || c.Window <= 0 {
+				return base, fmt.Errorf("invalid spike detector %q: window must be a positive duration", spec)
+			}
+		case "bucket":
+			if c.Bucket, err = time.ParseDuration(v); err != nil || c.Bucket <= 0 {
+				return base, fmt.Errorf("invalid spike detector %q: bucket must be a positive duration", spec)
+			}
+		case "min":
+			if c.MinRate, err = strconv.ParseFloat(v, 64); err != nil || c.MinRate < 0 {
+				return base, fmt.Errorf("invalid spike detector %q: min must be a rate of at least 0", spec)
+			}
+		default:
+			return base, fmt.Errorf("invalid spike detector %q: unknown key %q (want %s)", spec, k, strings.Join(spikeKeys, ", "))
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return base, fmt.Errorf("invalid spike detector %q: %w", spec, err)
+	}
+	return c, nil
+}
+
+// Validate checks that the window holds at least three buckets.
+func (c SpikeConfig) Validate() error {
+	if c.Bucket < time.Millisecond {
+		return fmt.Errorf("bucket must be at least 1ms")
+	}
+	if c.Window < 3*c.Bucket {
+		return fmt.Errorf("window %s must hold at least 3 buckets of %s", c.Window, c.Bucket)
+	}
+	return nil
+}
+
+// RateDetector tracks event rates and detects spikes using a sliding window
+// of fixed-width buckets (a second by default). Entries recorded with
+// RecordEntry are also tracked per level, and spikes can be detected by
+// detectors of their own per level (e.g. the error rate) instead.
 type RateDetector struct {
 	mu         sync.Mutex
 	window     time.Duration
-	buckets    []int64     // per-second counters
-	timestamps []time.Time // timestamp for each bucket
-	threshold  float64     // spike threshold multiplier (e.g., 3.0 = 3x average)
+	bucket     time.Duration
+	buckets    []int64     // per-bucket counters
+	timestamps []time.Time // start of each bucket
+	threshold  float64     // spike threshold multiplier (e.g., 3.0 = 3x average), or z-score for SpikeEWMA
+	minRate    float64     // lines/s the baseline counts as at least
+
+	method   SpikeMethod
+	alpha    float64   // EWMA smoothing factor
+	mean     float64   // EWMA of per-bucket counts
+	variance float64   // exponentially weighted variance
+	seen     int       // buckets folded into mean
+	folded   time.Time // start of the last bucket folded, or being counted
+
+	levels    map[entry.Level]*RateDetector // per-level rates
+	detectors []*levelDetector              // spike detectors, when added
+}
+
+// levelDetector detects spikes in the rate of some levels (all if none).
+type levelDetector struct {
+	levels []entry.Level
+	rate   *RateDetector
+}
+
+// Spike describes a detected spike.
+type Spike struct {
+	Levels []entry.Level // the detector's levels, nil for all lines
+	Rate   float64       // their lines/s over the detector's window
 }
 
 // NewRateDetector creates a rate detector with the given window duration and spike threshold.
@@ -26,10 +186,44 @@ func NewRateDetector(window time.Duration, threshold float64) *RateDetector {
 	}
 	return &RateDetector{
 		window:    window,
+		bucket:    time.Second,
 		threshold: threshold,
+		alpha:     0.3,
 	}
 }
 
+// NewSpikeDetector creates a rate detector configured by c (but for
+// c.Levels, which only AddSpikeDetector uses).
+func NewSpikeDetector(c SpikeConfig) *RateDetector {
+	r := NewRateDetector(c.Window, c.Threshold)
+	r.method = c.Method
+	if c.Alpha > 0 && c.Alpha <= 1 {
+		r.alpha = c.Alpha
+	}
+	if c.Bucket > 0 && c.Bucket <= r.window/3 {
+		r.bucket = c.Bucket
+	}
+	r.minRate = c.MinRate
+	return r
+}
+
+// AddSpikeDetector adds a spike detector for the entries of c.Levels (all
+// entries if none). Once one is added, RecordEntry reports the spikes of the
+// added detectors instead of those in the overall rate.
+func (r *RateDetector) AddSpikeDetector(c SpikeConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detectors = append(r.detectors, &levelDetector{levels: c.Levels, rate: NewSpikeDetector(c)})
+}
+
+// derive returns an empty detector with r's settings. Must be called with
+// lock held.
+func (r *RateDetector) derive() *RateDetector {
+	d := NewRateDetector(r.window, r.threshold)
+	d.method, d.alpha, d.bucket, d.minRate = r.method, r.alpha, r.bucket, r.minRate
+	return d
+}
+
 // Record adds an event at the current time.
 // Returns true if a spike is detected.
 func (r *RateDetector) Record() bool {
@@ -39,18 +233,119 @@ func (r *RateDetector) Record() bool {
 	now := time.Now()
 	r.prune(now)
 
-	// Find or create bucket for current second.
-	truncated := now.Truncate(time.Second)
+	// Find or create bucket for now.
+	truncated := now.Truncate(r.bucket)
 	if len(r.timestamps) > 0 && r.timestamps[len(r.timestamps)-1].Equal(truncated) {
 		r.buckets[len(r.buckets)-1]++
 	} else {
+		if r.method == SpikeEWMA {
+			r.fold(truncated)
+		}
 		r.buckets = append(r.buckets, 1)
 		r.timestamps = append(r.timestamps, truncated)
 	}
 
+	if r.method == SpikeEWMA {
+		return r.isOutlier()
+	}
 	return r.isSpiking()
 }
 
+// RecordEntry adds e at the current time, to the overall rate and its
+// level's rate, and reports whether a spike is detected: in the overall
+// rate, or by the detectors for e's level when any were added.
+func (r *RateDetector) RecordEntry(e *entry.LogEntry) (Spike, bool) {
+	r.mu.Lock()
+	if r.levels == nil {
+		r.levels = make(map[entry.Level]*RateDetector)
+	}
+	lr := r.levels[e.Level]
+	if lr == nil {
+		lr = r.derive()
+		r.levels[e.Level] = lr
+	}
+	detectors := r.detectors
+	r.mu.Unlock()
+
+	lr.Record()
+	spiking := r.Record()
+	if len(detectors) == 0 {
+		if !spiking {
+			return Spike{}, false
+		}
+		return Spike{Rate: r.CurrentRate()}, true
+	}
+
+	var spike Spike
+	found := false
+	for _, d := range detectors {
+		if !d.counts(e.Level) || !d.rate.Record() || found {
+			continue
+		}
+		spike, found = Spike{Levels: d.levels, Rate: d.rate.CurrentRate()}, true
+	}
+	return spike, found
+}
+
+// counts reports whether the detector counts entries of level l.
+func (d *levelDetector) counts(l entry.Level) bool {
+	if len(d.levels) == 0 {
+		return true
+	}
+	for _, dl := range d.levels {
+		if dl == l {
+			return true
+		}
+	}
+	return false
+}
+
+// LevelRate returns the combined events per second of the given levels over
+// the last window, counting entries added with RecordEntry.
+func (r *RateDetector) LevelRate(levels ...entry.Level) float64 {
+	r.mu.Lock()
+	detectors := make([]*RateDetector, 0, len(levels))
+	for _, l := range levels {
+		if lr := r.levels[l]; lr != nil {
+			detectors = append(detectors, lr)
+		}
+	}
+	r.mu.Unlock()
+
+	var rate float64
+	for _, lr := range detectors {
+		rate += lr.CurrentRate()
+	}
+	return rate
+}
+
+// SustainedAbove reports whether each of the whole buckets within d before
+// now's bucket had at least rate events per second. The window must be
+// longer than d.
+func (r *RateDetector) SustainedAbove(rate float64, d time.Duration, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune(now)
+	n := int(d / r.bucket)
+	if n < 1 {
+		n = 1
+	}
+	least := rate * r.bucket.Seconds()
+	cur := now.Truncate(r.bucket)
+	i := len(r.timestamps) - 1
+	for s := 1; s <= n; s++ {
+		want := cur.Add(-time.Duration(s) * r.bucket)
+		for i >= 0 && r.timestamps[i].After(want) {
+			i--
+		}
+		if i < 0 || !r.timestamps[i].Equal(want) || float64(r.buckets[i]) < least {
+			return false
+		}
+	}
+	return true
+}
+
 // CurrentRate returns events per second over the last window.
 func (r *RateDetector) CurrentRate() float64 {
 	r.mu.Lock()
@@ -73,7 +368,8 @@ func (r *RateDetector) CurrentRate() float64 {
 	return float64(total) / seconds
 }
 
-// LatestSecondRate returns the event count in the most recent second.
+// LatestSecondRate returns the event count in the most recent bucket (a
+// second by default).
 func (r *RateDetector) LatestSecondRate() int64 {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -82,7 +378,7 @@ func (r *RateDetector) LatestSecondRate() int64 {
 		return 0
 	}
 
-	now := time.Now().Truncate(time.Second)
+	now := time.Now().Truncate(r.bucket)
 	last := r.timestamps[len(r.timestamps)-1]
 	if now.Equal(last) {
 		return r.buckets[len(r.buckets)-1]
@@ -103,7 +399,8 @@ func (r *RateDetector) prune(now time.Time) {
 	}
 }
 
-// isSpiking checks if the latest bucket exceeds threshold * average. Must be called with lock held.
+// isSpiking checks if the latest bucket exceeds threshold * average, the
+// average being at least the minimum rate. Must be called with lock held.
 func (r *RateDetector) isSpiking() bool {
 	if len(r.buckets) < 3 {
 		return false // not enough data
@@ -114,7 +411,7 @@ func (r *RateDetector) isSpiking() bool {
 	for i := 0; i < len(r.buckets)-1; i++ {
 		sum += r.buckets[i]
 	}
-	avg := float64(sum) / float64(len(r.buckets)-1)
+	avg := math.Max(float64(sum)/float64(len(r.buckets)-1), r.floor())
 	if avg == 0 {
 		return false
 	}
@@ -122,3 +419,61 @@ func (r *RateDetector) isSpiking() bool {
 	latest := float64(r.buckets[len(r.buckets)-1])
 	return latest > avg*r.threshold
 }
+
+// floor returns the minimum rate as a count per bucket.
+func (r *RateDetector) floor() float64 {
+	return r.minRate * r.bucket.Seconds()
+}
+
+// fold adds the bucket that just ended to the EWMA, and a zero for each
+// bucket without events since, up to one window of them. Must be called
+// with lock held, before the bucket for now is added.
+func (r *RateDetector) fold(now time.Time) {
+	from := r.folded
+	if len(r.buckets) > 0 {
+		r.add(float64(r.buckets[len(r.buckets)-1]))
+		from = r.timestamps[len(r.timestamps)-1]
+	} else if r.seen == 0 {
+		r.folded = now
+		return
+	}
+	idle := int(now.Sub(from)/r.bucket) - 1
+	if limit := int(r.window / r.bucket); idle > limit {
+		idle = limit
+	}
+	for i := 0; i < idle; i++ {
+		r.add(0)
+	}
+	r.folded = now
+}
+
+// add updates the EWMA and variance with one bucket's count x.
+func (r *RateDetector) add(x float64) {
+	r.seen++
+	if r.seen == 1 {
+		r.mean = x
+		return
+	}
+	diff := x - r.mean
+	incr := r.alpha * diff
+	r.mean += incr
+	r.variance = (1 - r.alpha) * (r.variance + diff*incr)
+}
+
+// isOutlier checks if the current bucket is more than threshold standard
+// deviations above the EWMA, the EWMA being at least the minimum rate. The
+// deviation is at least the square root of the mean, the spread of a steady
+// random rate, so a flat baseline does not turn a few extra lines into a
+// spike. Must be called with lock held.
+func (r *RateDetector) isOutlier() bool {
+	if r.seen < ewmaWarmup {
+		return false // not enough data
+	}
+	mean := math.Max(r.mean, r.floor())
+	std := math.Max(math.Sqrt(r.variance), math.Sqrt(mean))
+	if std == 0 {
+		std = 1
+	}
+	latest := float64(r.buckets[len(r.buckets)-1])
+	return latest > mean+r.threshold*std
+}
@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+)
+
+// sourceRateWindow is the number of seconds the current rate of a source
+// is averaged over.
+const sourceRateWindow = 10
+
+// SourceStat holds the line counts of one source of a merged stream.
+type SourceStat struct {
+	Source   string
+	Lines    uint64
+	Matched  uint64
+	Filtered uint64  // lines the filters rejected (some may be shown as context)
+	Rate     float64 // lines/s over the last 10 seconds
+}
+
+// sourceCounter counts the lines of one source, with per-second buckets
+// for its current rate.
+type sourceCounter struct {
+	lines    uint64
+	matched  uint64
+	filtered uint64
+	secs     [sourceRateWindow]uint64 // by Unix second modulo the window
+	first    int64                    // Unix second of the first line
+	last     int64                    // Unix second of the newest bucket
+}
+
+// record counts a line arriving at Unix second now.
+func (c *sourceCounter) record(now int64) {
+	if c.lines == 0 {
+		c.first, c.last = now, now
+	}
+	c.advance(now)
+	c.secs[now%sourceRateWindow]++
+	c.lines++
+}
+
+// advance clears the buckets of the seconds between the newest bucket and
+// now.
+func (c *sourceCounter) advance(now int64) {
+	if now <= c.last {
+		return
+	}
+	if now-c.last >= sourceRateWindow {
+		c.secs = [sourceRateWindow]uint64{}
+	} else {
+		for s := c.last + 1; s <= now; s++ {
+			c.secs[s%sourceRateWindow] = 0
+		}
+	}
+	c.last = now
+}
+
+// rate returns the lines per second over the complete seconds of the
+// window before now.
+func (c *sourceCounter) rate(now int64) float64 {
+	c.advance(now)
+	span := now - c.first
+	if span > sourceRateWindow-1 {
+		span = sourceRateWindow - 1
+	}
+	if span < 1 {
+		return 0
+	}
+	var sum uint64
+	for s := now - span; s < now; s++ {
+		sum += c.secs[s%sourceRateWindow]
+	}
+	return float64(sum) / float64(span)
+}
+
+// Sources returns the line counts and current rate of each source, most
+// lines first.
+func (s *Stats) Sources() []SourceStat {
+	now := time.Now().Unix()
+
+	s.mu.Lock()
+	stats := make([]SourceStat, 0, len(s.sources))
+	for name, c := range s.sources {
+		stats = append(stats, SourceStat{
+			Source:   name,
+			Lines:    c.lines,
+			Matched:  c.matched,
+			Filtered: c.filtered,
+			Rate:     c.rate(now),
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Lines != stats[j].Lines {
+			return stats[i].Lines > stats[j].Lines
+		}
+		return stats[i].Source < stats[j].Source
+	})
+	return stats
+}
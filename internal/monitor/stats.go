@@ -3,24 +3,54 @@ package monitor
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
 )
 
-// Stats collects pipeline processing metrics in a lock-free manner.
+// Stats collects pipeline processing metrics. Line counters are lock-free;
+// the per-source breakdown (lines, matches and current rate of each source)
+// takes a lock. With TrackTopMessages it also counts
+// the most frequent message templates. The fields of matched lines feed
+// latency percentiles and, with CountBy, counts per field value.
 type Stats struct {
 	totalLines   atomic.Uint64
 	matchedLines atomic.Uint64
+	levelLines   [entry.LevelFatal + 1]atomic.Uint64
 	startTime    time.Time
+
+	mu      sync.Mutex
+	sources map[string]*sourceCounter
+
+	top     *TopK // optional
+	latency *Latency
+	groups  []*FieldCounter
+	hist    *LevelHistogram
 }
 
 // NewStats creates a new statistics collector.
 func NewStats() *Stats {
 	return &Stats{
 		startTime: time.Now(),
+		latency:   NewLatency(),
+		hist:      NewLevelHistogram(time.Minute),
 	}
 }
 
+// SetHistogramBucket sets the bucket width of the per-level histogram
+// (default one minute). Call it before lines are recorded.
+func (s *Stats) SetHistogramBucket(d time.Duration) {
+	s.hist = NewLevelHistogram(d)
+}
+
+// Histogram returns the line counts per level and time bucket.
+func (s *Stats) Histogram() *LevelHistogram {
+	return s.hist
+}
+
 // RecordLine increments the total line counter.
 func (s *Stats) RecordLine() {
 	s.totalLines.Add(1)
@@ -31,6 +61,107 @@ func (s *Stats) RecordMatch() {
 	s.matchedLines.Add(1)
 }
 
+// SetLatencyFields sets the duration fields read by RecordMatchFields, instead
+// of the usual ones (latency_ms, duration, request_time, ...). Call it before
+// lines are recorded.
+func (s *Stats) SetLatencyFields(keys ...string) {
+	s.latency = NewLatency(keys...)
+}
+
+// CountBy enables counting matched lines by the values of fields (e.g.
+// status, route). Call it before lines are recorded.
+func (s *Stats) CountBy(fields ...string) {
+	s.groups = nil
+	for _, f := range fields {
+		s.groups = append(s.groups, NewFieldCounter(f))
+	}
+}
+
+// Groups returns the counters set up by CountBy.
+func (s *Stats) Groups() []*FieldCounter {
+	return s.groups
+}
+
+// RecordMatchFields counts a matched entry for its source, adds its
+// duration to the latency percentiles and counts it by its CountBy field
+// values.
+func (s *Stats) RecordMatchFields(e *entry.LogEntry) {
+	s.mu.Lock()
+	if c := s.sources[e.Source]; c != nil {
+		c.matched++
+	}
+	s.mu.Unlock()
+	s.latency.Record(e)
+	for _, g := range s.groups {
+		g.Record(e)
+	}
+}
+
+// RecordFiltered counts a line the filters rejected for its source.
+func (s *Stats) RecordFiltered(e *entry.LogEntry) {
+	s.mu.Lock()
+	if c := s.sources[e.Source]; c != nil {
+		c.filtered++
+	}
+	s.mu.Unlock()
+}
+
+// Latency returns the latency percentiles of matched lines.
+func (s *Stats) Latency() *Latency {
+	return s.latency
+}
+
+// TrackTopMessages enables counting the k most frequent message templates
+// (see NormalizeMessage). Call it before lines are recorded.
+func (s *Stats) TrackTopMessages(k int) {
+	s.top = NewTopK(k)
+}
+
+// TracksTopMessages reports whether TrackTopMessages was called.
+func (s *Stats) TracksTopMessages() bool {
+	return s.top != nil
+}
+
+// TopMessages returns the most frequent message templates, or nil when not
+// tracked.
+func (s *Stats) TopMessages() []TemplateCount {
+	if s.top == nil {
+		return nil
+	}
+	return s.top.Top()
+}
+
+// RecordEntry counts a parsed line by level, time bucket, source and, if
+// tracked, message template.
+func (s *Stats) RecordEntry(e *entry.LogEntry) {
+	if e.Level >= 0 && int(e.Level) < len(s.levelLines) {
+		s.levelLines[e.Level].Add(1)
+	}
+	s.hist.Record(e)
+	s.mu.Lock()
+	if s.sources == nil {
+		s.sources = make(map[string]*sourceCounter)
+	}
+	c := s.sources[e.Source]
+	if c == nil {
+		c = &sourceCounter{}
+		s.sources[e.Source] = c
+	}
+	c.record(time.Now().Unix())
+	s.mu.Unlock()
+	if s.top != nil {
+		s.top.Record(e.Message)
+	}
+}
+
+// LevelCount returns the number of lines recorded with level l.
+func (s *Stats) LevelCount(l entry.Level) uint64 {
+	if l < 0 || int(l) >= len(s.levelLines) {
+		return 0
+	}
+	return s.levelLines[l].Load()
+}
+
 // Total returns the total number of processed lines.
 func (s *Stats) Total() uint64 {
 	return s.totalLines.Load()
@@ -66,15 +197,79 @@ func (s *Stats) Summary() string {
 		matchRate = float64(matched) / float64(total) * 100
 	}
 
-	return fmt.Sprintf(
+	var sb strings.Builder
+	fmt.Fprintf(&sb,
 		"── Summary ──\n"+
 			"  Total lines:   %d\n"+
 			"  Matched lines: %d (%.1f%%)\n"+
 			"  Duration:      %s\n"+
-			"  Throughput:    %.0f lines/s\n"+
-			"─────────────",
+			"  Throughput:    %.0f lines/s\n",
 		total, matched, matchRate,
 		elapsed.Round(time.Millisecond),
 		s.Rate(),
 	)
+
+	seconds := elapsed.Seconds()
+	perSecond := func(n uint64) float64 {
+		if seconds == 0 {
+			return 0
+		}
+		return float64(n) / seconds
+	}
+	if n := s.LevelCount(entry.LevelError) + s.LevelCount(entry.LevelFatal); n > 0 {
+		fmt.Fprintf(&sb, "  Error rate:    %.2f lines/s\n", perSecond(n))
+	}
+	var levels []string
+	for l := entry.LevelFatal; l >= entry.LevelUnknown; l-- {
+		if n := s.LevelCount(l); n > 0 {
+			levels = append(levels, fmt.Sprintf("%s %d", l, n))
+		}
+	}
+	if len(levels) > 0 {
+		fmt.Fprintf(&sb, "  By level:      %s\n", strings.Join(levels, ", "))
+	}
+	sb.WriteString(s.hist.Summary())
+	if sources := s.Sources(); len(sources) > 1 {
+		fmt.Fprintf(&sb, "  By source:    %-20s %10s %10s %10s %10s\n", "", "lines", "matched", "filtered", "lines/s")
+		for _, src := range sources {
+			fmt.Fprintf(&sb, "    %-32s %10d %10d %10d %10.1f\n",
+				truncateTemplate(src.Source, 32), src.Lines, src.Matched, src.Filtered, perSecond(src.Lines))
+		}
+	}
+	if n := s.latency.Count(); n > 0 {
+		fmt.Fprintf(&sb, "  Latency:       %s (%d lines, %s)\n", s.latency, n, s.latency.Field())
+	}
+	for _, g := range s.groups {
+		total := g.Total()
+		if total == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "  By %s:\n", g.Field())
+		for i, vc := range g.Counts() {
+			if i == 10 {
+				break
+			}
+			fmt.Fprintf(&sb, "    %-28s %d (%.1f%%)\n", truncateTemplate(vc.Value, 28), vc.Count, float64(vc.Count)/float64(total)*100)
+		}
+	}
+	if top := s.TopMessages(); len(top) > 0 {
+		sb.WriteString("  Top messages:\n")
+		for _, t := range top {
+			fmt.Fprintf(&sb, "    %8d  %s\n", t.Count, truncateTemplate(t.Template, 100))
+		}
+	}
+	sb.WriteString("─────────────")
+	return sb.String()
+}
+
+// truncateTemplate shortens a template to its first line and max runes.
+func truncateTemplate(s string, max int) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " …"
+	}
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
 }
@@ -0,0 +1,126 @@
+package monitor
+
+import (
+	"math"
+	"sort"
+)
+
+// TDigest estimates quantiles of a stream in bounded memory (Dunning's
+// merging t-digest). Values are kept as weighted centroids; centroids near
+// the tails stay small, so p99 is accurate while the median is coarse.
+// Not safe for concurrent use.
+type TDigest struct {
+	compression float64
+	centroids   []centroid // merged, sorted by mean
+	pending     []centroid // added since the last merge
+	count       float64
+	min, max    float64
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// NewTDigest creates a digest. Higher compression keeps more centroids and
+// gives more accurate quantiles; 100 is a common choice.
+func NewTDigest(compression float64) *TDigest {
+	if compression < 20 {
+		compression = 100
+	}
+	return &TDigest{compression: compression}
+}
+
+// Add records x.
+func (t *TDigest) Add(x float64) {
+	if math.IsNaN(x) {
+		return
+	}
+	if t.count == 0 || x < t.min {
+		t.min = x
+	}
+	if t.count == 0 || x > t.max {
+		t.max = x
+	}
+	t.count++
+	t.pending = append(t.pending, centroid{mean: x, weight: 1})
+	if len(t.pending) >= int(5*t.compression) {
+		t.merge()
+	}
+}
+
+// Count returns the number of values added.
+func (t *TDigest) Count() uint64 {
+	return uint64(t.count)
+}
+
+// merge folds the pending values into the centroids, joining neighbours
+// while the k1 scale function allows.
+func (t *TDigest) merge() {
+	if len(t.pending) == 0 {
+		return
+	}
+	all := append(t.centroids, t.pending...)
+	t.pending = t.pending[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, int(t.compression))
+	cur := all[0]
+	done := 0.0 // weight of the centroids already closed
+	limit := t.qLimit(0)
+	for _, c := range all[1:] {
+		if (done+cur.weight+c.weight)/t.count <= limit {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		done += cur.weight
+		merged = append(merged, cur)
+		limit = t.qLimit(done / t.count)
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+}
+
+// qLimit returns the largest quantile a centroid starting at q may reach.
+func (t *TDigest) qLimit(q float64) float64 {
+	k := t.compression / (2 * math.Pi) * math.Asin(2*q-1)
+	return (math.Sin(2*math.Pi*(k+1)/t.compression) + 1) / 2
+}
+
+// Quantile returns the estimated value at quantile q (0 to 1), or 0 if
+// nothing was added.
+func (t *TDigest) Quantile(q float64) float64 {
+	t.merge()
+	if len(t.centroids) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+
+	// Interpolate between centroid centers, and towards min and max beyond
+	// the first and last center.
+	target := q * t.count
+	cum := 0.0
+	prevMean, prevPos := t.min, 0.0
+	for _, c := range t.centroids {
+		pos := cum + c.weight/2
+		if target < pos {
+			return interpolate(prevMean, c.mean, prevPos, pos, target)
+		}
+		prevMean, prevPos = c.mean, pos
+		cum += c.weight
+	}
+	return interpolate(prevMean, t.max, prevPos, t.count, target)
+}
+
+func interpolate(a, b, posA, posB, target float64) float64 {
+	if posB <= posA {
+		return b
+	}
+	return a + (b-a)*(target-posA)/(posB-posA)
+}
@@ -0,0 +1,108 @@
+package monitor
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Message normalization: variable parts are replaced by placeholders, so
+// "timeout after 31ms from 10.0.0.7" and "timeout after 5ms from 10.0.0.9"
+// count as the same template.
+var (
+	normUUID = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	normIP   = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b|\b(?:[0-9a-fA-F]{1,4}:){2,7}[0-9a-fA-F]{1,4}\b`)
+	normHex  = regexp.MustCompile(`\b0x[0-9a-fA-F]+\b|\b[0-9a-fA-F]{6,}\b`)
+	normNum  = regexp.MustCompile(`\d+(?:\.\d+)?`)
+)
+
+// NormalizeMessage replaces UUIDs, IP addresses, hex strings and numbers in
+// msg with <uuid>, <ip>, <hex> and <num>.
+func NormalizeMessage(msg string) string {
+	msg = normUUID.ReplaceAllString(msg, "<uuid>")
+	msg = normIP.ReplaceAllString(msg, "<ip>")
+	msg = normHex.ReplaceAllStringFunc(msg, func(s string) string {
+		// Words like "deadbeef" or "facade" and plain numbers are no hex IDs.
+		if strings.HasPrefix(s, "0x") || strings.IndexFunc(s, isDigit) >= 0 && strings.IndexFunc(s, isHexLetter) >= 0 {
+			return "<hex>"
+		}
+		return s
+	})
+	return normNum.ReplaceAllString(msg, "<num>")
+}
+
+func isDigit(r rune) bool     { return r >= '0' && r <= '9' }
+func isHexLetter(r rune) bool { return r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F' }
+
+// TemplateCount is a normalized message and how often it was seen.
+type TemplateCount struct {
+	Template string
+	Count    uint64
+}
+
+// TopK tracks the most frequent message templates in bounded memory with the
+// Space-Saving algorithm: it keeps a fixed number of counters, and a new
+// template takes over the smallest one, inheriting its count. Counts of the
+// top templates are exact unless the log has far more distinct templates than
+// counters, and then overestimate by at most the smallest count.
+type TopK struct {
+	mu       sync.Mutex
+	k        int
+	capacity int
+	counts   map[string]uint64
+}
+
+// NewTopK creates a tracker reporting the k most frequent templates.
+func NewTopK(k int) *TopK {
+	if k < 1 {
+		k = 10
+	}
+	return &TopK{
+		k:        k,
+		capacity: k * 20,
+		counts:   make(map[string]uint64),
+	}
+}
+
+// Record counts the template of msg.
+func (t *TopK) Record(msg string) {
+	tmpl := NormalizeMessage(msg)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.counts[tmpl]; ok || len(t.counts) < t.capacity {
+		t.counts[tmpl]++
+		return
+	}
+	minKey, minCount := "", uint64(0)
+	for k, c := range t.counts {
+		if minKey == "" || c < minCount {
+			minKey, minCount = k, c
+		}
+	}
+	delete(t.counts, minKey)
+	t.counts[tmpl] = minCount + 1
+}
+
+// Top returns the k most frequent templates, most frequent first.
+func (t *TopK) Top() []TemplateCount {
+	t.mu.Lock()
+	top := make([]TemplateCount, 0, len(t.counts))
+	for tmpl, c := range t.counts {
+		top = append(top, TemplateCount{Template: tmpl, Count: c})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Template < top[j].Template
+	})
+	if len(top) > t.k {
+		top = top[:t.k]
+	}
+	return top
+}
@@ -0,0 +1,297 @@
+// Package msgpack implements the subset of MessagePack needed by lx's
+// fluent forward protocol support and binary payload decoding.
+package msgpack
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// DefaultMaxSize is the largest encoded value a Decoder reads by default.
+// Lengths in MessagePack headers are checked against it before anything is
+// allocated, so a header declaring gigabytes cannot exhaust memory.
+const DefaultMaxSize = 64 << 20
+
+// MaxDepth is how deeply arrays and maps may nest.
+const MaxDepth = 100
+
+// readChunk is the size up to which str, bin and ext data is allocated at
+// once; longer data grows as it arrives, so a declared length the input does
+// not back up costs no more memory than the bytes actually received.
+const readChunk = 64 << 10
+
+var (
+	// ErrTooLarge is returned for a value larger than the decoder's limit.
+	ErrTooLarge = errors.New("msgpack: value exceeds size limit")
+	// ErrTooDeep is returned for arrays and maps nested over MaxDepth.
+	ErrTooDeep = errors.New("msgpack: nesting exceeds depth limit")
+)
+
+// Ext is an extension value whose type lx does not interpret natively.
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// Decoder reads MessagePack values from a stream.
+//
+// Values decode to Go types as follows: nil, bool, int64, uint64, float64,
+// string, []byte (bin), []interface{} (array), map[string]interface{} (map;
+// non-string keys are formatted with fmt.Sprint), and Ext.
+//
+// A value may be at most DefaultMaxSize bytes (see SetMaxSize) and nest at
+// most MaxDepth deep; input is untrusted, e.g. from a network listener.
+type Decoder struct {
+	r     *bufio.Reader
+	max   int
+	left  int // bytes the current value may still use
+	depth int
+}
+
+// NewDecoder creates a decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{r: br, max: DefaultMaxSize}
+}
+
+// SetMaxSize sets the largest encoded value Decode reads, in bytes.
+func (d *Decoder) SetMaxSize(n int) {
+	d.max = n
+}
+
+// Unmarshal decodes a single value from data. No length in data may exceed
+// len(data), which bounds what decoding allocates.
+func Unmarshal(data []byte) (interface{}, error) {
+	d := NewDecoder(bytes.NewReader(data))
+	d.SetMaxSize(len(data))
+	return d.Decode()
+}
+
+// Decode reads the next value. Returns io.EOF at a clean end of stream, and
+// ErrTooLarge or ErrTooDeep for a value over the limits; the stream cannot
+// be decoded further after an error.
+func (d *Decoder) Decode() (interface{}, error) {
+	d.left, d.depth = d.max, 0
+	return d.value()
+}
+
+// value reads one value, counting its bytes against d.left.
+func (d *Decoder) value() (interface{}, error) {
+	c, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.readMap(int(c & 0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.readArray(int(c & 0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		return d.readString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLen(c - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return d.readBytes(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLen(c - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.readExt(n)
+	case 0xca:
+		b, err := d.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := d.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		b, err := d.readBytes(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return readUint(b), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		b, err := d.readBytes(size)
+		if err != nil {
+			return nil, err
+		}
+		u := readUint(b)
+		shift := uint(64 - 8*size)
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.readExt(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLen(c - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.readString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLen(c - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.readArray(n)
+	case 0xde, 0xdf:
+		n, err := d.readLen(c - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.readMap(n)
+	}
+
+	return nil, fmt.Errorf("msgpack: invalid type byte 0x%02x", c)
+}
+
+// readLen reads a big-endian length of 1, 2 or 4 bytes (sel = 0, 1, 2).
+func (d *Decoder) readLen(sel byte) (int, error) {
+	b, err := d.readBytes(1 << sel)
+	if err != nil {
+		return 0, err
+	}
+	return int(readUint(b)), nil
+}
+
+func (d *Decoder) readByte() (byte, error) {
+	if d.left < 1 {
+		return 0, ErrTooLarge
+	}
+	d.left--
+	return d.r.ReadByte()
+}
+
+func (d *Decoder) readBytes(n int) ([]byte, error) {
+	if n < 0 || n > d.left {
+		return nil, ErrTooLarge
+	}
+	d.left -= n
+	if n <= readChunk {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(d.r, b); err != nil {
+			return nil, unexpected(err)
+		}
+		return b, nil
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, d.r, int64(n)); err != nil {
+		return nil, unexpected(err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *Decoder) readString(n int) (string, error) {
+	b, err := d.readBytes(n)
+	return string(b), err
+}
+
+func (d *Decoder) readExt(n int) (Ext, error) {
+	t, err := d.readByte()
+	if err != nil {
+		return Ext{}, unexpected(err)
+	}
+	b, err := d.readBytes(n)
+	return Ext{Type: int8(t), Data: b}, err
+}
+
+// enter checks that a container of n elements, each at least one byte, fits
+// in what is left of the value and the depth limit.
+func (d *Decoder) enter(n int) error {
+	if n < 0 || n > d.left {
+		return ErrTooLarge
+	}
+	if d.depth >= MaxDepth {
+		return ErrTooDeep
+	}
+	d.depth++
+	return nil
+}
+
+func (d *Decoder) readArray(n int) ([]interface{}, error) {
+	if err := d.enter(n); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	arr := make([]interface{}, 0, min(n, 64))
+	for i := 0; i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func (d *Decoder) readMap(n int) (map[string]interface{}, error) {
+	if err := d.enter(2 * n); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+	m := make(map[string]interface{}, min(n, 64))
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		v, err := d.value()
+		if err != nil {
+			return nil, unexpected(err)
+		}
+		switch key := k.(type) {
+		case string:
+			m[key] = v
+		case []byte:
+			m[string(key)] = v
+		default:
+			m[fmt.Sprint(key)] = v
+		}
+	}
+	return m, nil
+}
+
+// unexpected converts a clean EOF inside a container into ErrUnexpectedEOF.
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func readUint(b []byte) uint64 {
+	var u uint64
+	for _, x := range b {
+		u = u<<8 | uint64(x)
+	}
+	return u
+}
@@ -0,0 +1,140 @@
+package msgpack
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	in := map[string]interface{}{
+		"msg":   "hello",
+		"n":     int64(-42),
+		"big":   uint64(1 << 63),
+		"f":     1.5,
+		"ok":    true,
+		"nil":   nil,
+		"list":  []interface{}{int64(1), "two", []interface{}{int64(3)}},
+		"bin":   []byte{0, 1, 2},
+		"long":  strings.Repeat("x", readChunk+1),
+		"inner": map[string]interface{}{"k": "v"},
+	}
+	b, err := Append(nil, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, in) {
+		t.Errorf("Unmarshal = %#v, want %#v", got, in)
+	}
+}
+
+func TestDecodeStream(t *testing.T) {
+	var b []byte
+	b = AppendString(b, "a")
+	b = AppendInt(b, 7)
+	d := NewDecoder(bytes.NewReader(b))
+	for _, want := range []interface{}{"a", int64(7)} {
+		v, err := d.Decode()
+		if err != nil || v != want {
+			t.Fatalf("Decode = %v, %v; want %v", v, err, want)
+		}
+	}
+	if _, err := d.Decode(); err != io.EOF {
+		t.Errorf("Decode at end = %v, want io.EOF", err)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	full, err := Append(nil, map[string]interface{}{
+		"msg":  strings.Repeat("x", 300),
+		"list": []interface{}{int64(1), 2.5, []byte("bin")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n < len(full); n++ {
+		d := NewDecoder(bytes.NewReader(full[:n]))
+		if _, err := d.Decode(); err != io.ErrUnexpectedEOF {
+			t.Fatalf("Decode of %d/%d bytes = %v, want io.ErrUnexpectedEOF", n, len(full), err)
+		}
+	}
+}
+
+func TestDecodeOversized(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"array32", []byte{0xdd, 0x7f, 0xff, 0xff, 0xff}},
+		{"map32", []byte{0xdf, 0xff, 0xff, 0xff, 0xff}},
+		{"str32", []byte{0xdb, 0xff, 0xff, 0xff, 0xff, 'a'}},
+		{"bin32", []byte{0xc6, 0x7f, 0xff, 0xff, 0xff}},
+		{"ext32", []byte{0xc9, 0x7f, 0xff, 0xff, 0xff, 0x01}},
+		{"nested", []byte{0x91, 0xdd, 0x7f, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Through a stream the length is checked against the size limit.
+			d := NewDecoder(bytes.NewReader(tt.data))
+			d.SetMaxSize(1 << 20)
+			if _, err := d.Decode(); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("Decode = %v, want ErrTooLarge", err)
+			}
+			// Unmarshal bounds it by the data itself.
+			if _, err := Unmarshal(tt.data); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("Unmarshal = %v, want ErrTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestDecodeDeclaredLengthUnbacked(t *testing.T) {
+	// Under the limit but far longer than the input: no huge allocation,
+	// just a truncated value.
+	data := []byte{0xc6, 0x01, 0x00, 0x00, 0x00, 'a', 'b'}
+	if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode = %v, want io.ErrUnexpectedEOF", err)
+	}
+	data = []byte{0xdd, 0x00, 0x10, 0x00, 0x00, 0x01}
+	if _, err := NewDecoder(bytes.NewReader(data)).Decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Decode = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestDecodeLimitPerValue(t *testing.T) {
+	var b []byte
+	for i := 0; i < 3; i++ {
+		b = AppendString(b, "abcdefgh")
+	}
+	d := NewDecoder(bytes.NewReader(b))
+	d.SetMaxSize(9)
+	for i := 0; i < 3; i++ {
+		if _, err := d.Decode(); err != nil {
+			t.Fatalf("Decode %d = %v", i, err)
+		}
+	}
+}
+
+func TestDecodeTooDeep(t *testing.T) {
+	data := append(bytes.Repeat([]byte{0x91}, MaxDepth+1), 0xc0)
+	if _, err := Unmarshal(data); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("Unmarshal = %v, want ErrTooDeep", err)
+	}
+	data = append(bytes.Repeat([]byte{0x91}, MaxDepth), 0xc0)
+	if _, err := Unmarshal(data); err != nil {
+		t.Errorf("Unmarshal at MaxDepth = %v", err)
+	}
+}
+
+func TestDecodeInvalidType(t *testing.T) {
+	if _, err := Unmarshal([]byte{0xc1}); err == nil {
+		t.Error("Unmarshal(0xc1) succeeded")
+	}
+}
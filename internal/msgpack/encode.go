@@ -0,0 +1,199 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// AppendMapHeader appends a map header for n key/value pairs.
+func AppendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// AppendArrayHeader appends an array header for n elements.
+func AppendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+// AppendString appends a str value.
+func AppendString(b []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+// AppendBytes appends a bin value.
+func AppendBytes(b []byte, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, data...)
+}
+
+// AppendInt appends a signed integer using the smallest encoding.
+func AppendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0 && v <= 0x7f:
+		return append(b, byte(v))
+	case v < 0 && v >= -32:
+		return append(b, byte(int8(v)))
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return append(b, 0xd0, byte(int8(v)))
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(int16(v)))
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(int32(v)))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+// AppendFloat appends a float64 value.
+func AppendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+// AppendBool appends a bool value.
+func AppendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+// AppendNil appends nil.
+func AppendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+// AppendExt appends an extension value.
+func AppendExt(b []byte, typ int8, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n == 1:
+		b = append(b, 0xd4, byte(typ))
+	case n == 2:
+		b = append(b, 0xd5, byte(typ))
+	case n == 4:
+		b = append(b, 0xd6, byte(typ))
+	case n == 8:
+		b = append(b, 0xd7, byte(typ))
+	case n == 16:
+		b = append(b, 0xd8, byte(typ))
+	case n <= math.MaxUint8:
+		b = append(b, 0xc7, byte(n), byte(typ))
+	case n <= math.MaxUint16:
+		b = append(binary.BigEndian.AppendUint16(append(b, 0xc8), uint16(n)), byte(typ))
+	default:
+		b = append(binary.BigEndian.AppendUint32(append(b, 0xc9), uint32(n)), byte(typ))
+	}
+	return append(b, data...)
+}
+
+// Append encodes v, which must be one of the types produced by Decoder
+// (plus int, map[string]string and []string for convenience).
+// Map keys are written in sorted order so output is deterministic.
+func Append(b []byte, v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		return AppendNil(b), nil
+	case bool:
+		return AppendBool(b, val), nil
+	case int:
+		return AppendInt(b, int64(val)), nil
+	case int64:
+		return AppendInt(b, val), nil
+	case uint64:
+		if val > math.MaxInt64 {
+			return binary.BigEndian.AppendUint64(append(b, 0xcf), val), nil
+		}
+		return AppendInt(b, int64(val)), nil
+	case float64:
+		return AppendFloat(b, val), nil
+	case string:
+		return AppendString(b, val), nil
+	case []byte:
+		return AppendBytes(b, val), nil
+	case Ext:
+		return AppendExt(b, val.Type, val.Data), nil
+	case []string:
+		b = AppendArrayHeader(b, len(val))
+		for _, s := range val {
+			b = AppendString(b, s)
+		}
+		return b, nil
+	case []interface{}:
+		b = AppendArrayHeader(b, len(val))
+		var err error
+		for _, x := range val {
+			if b, err = Append(b, x); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]string:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = AppendMapHeader(b, len(val))
+		for _, k := range keys {
+			b = AppendString(AppendString(b, k), val[k])
+		}
+		return b, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = AppendMapHeader(b, len(val))
+		var err error
+		for _, k := range keys {
+			b = AppendString(b, k)
+			if b, err = Append(b, val[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+}
+
+// Marshal encodes v into a new byte slice. See Append for supported types.
+func Marshal(v interface{}) ([]byte, error) {
+	return Append(nil, v)
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// DigestReport summarizes the alerts triggered during one digest window.
+type DigestReport struct {
+	Start, End time.Time
+	Counts     map[string]int // triggers per rule
+	Total      int            // triggers (an entry matching two rules counts once)
+	Samples    []DigestSample // the first few matching entries
+	Dropped    int            // triggers beyond the sample limit
+}
+
+// DigestSample is one alerting entry kept as an example.
+type DigestSample struct {
+	Rules []string
+	Entry entry.LogEntry
+}
+
+// Rules returns the rule names in the report, most frequent first.
+func (r *DigestReport) Rules() []string {
+	rules := make([]string, 0, len(r.Counts))
+	for rule := range r.Counts {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if r.Counts[rules[i]] != r.Counts[rules[j]] {
+			return r.Counts[rules[i]] > r.Counts[rules[j]]
+		}
+		return rules[i] < rules[j]
+	})
+	return rules
+}
+
+// DigestSender delivers a digest report (e.g. as an email).
+type DigestSender interface {
+	SendDigest(ctx context.Context, r *DigestReport) error
+	Name() string
+}
+
+// digest accumulates every alert trigger for a sender over a window. Unlike
+// notifiers it sees all triggers, not only those passing the rate limit.
+type digest struct {
+	sender  DigestSender
+	window  time.Duration
+	samples int
+
+	mu     sync.Mutex
+	report *DigestReport
+}
+
+// collect adds one trigger to the current window.
+func (g *digest) collect(rules []string, e *entry.LogEntry, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.report == nil {
+		g.report = &DigestReport{Start: now, Counts: make(map[string]int)}
+	}
+	for _, r := range rules {
+		g.report.Counts[r]++
+	}
+	g.report.Total++
+	if len(g.report.Samples) < g.samples {
+		g.report.Samples = append(g.report.Samples, DigestSample{Rules: rules, Entry: *e})
+	} else {
+		g.report.Dropped++
+	}
+}
+
+// take returns the pending report if its window has ended (or force is set)
+// and starts a new window.
+func (g *digest) take(now time.Time, force bool) *DigestReport {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.report == nil || (!force && now.Sub(g.report.Start) < g.window) {
+		return nil
+	}
+	r := g.report
+	r.End = now
+	g.report = nil
+	return r
+}
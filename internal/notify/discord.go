@@ -0,0 +1,62 @@
+package notify
+
+import "net/http"
+
+// Discord message limits.
+const (
+	discordMaxContent     = 2000
+	discordMaxDescription = 4000
+)
+
+// DiscordNotifier posts alerts to a Discord channel webhook.
+type DiscordNotifier struct {
+	webhookNotifier
+}
+
+// NewDiscordNotifier creates a notifier for the given webhook URL.
+func NewDiscordNotifier(webhookURL string) *DiscordNotifier {
+	n := &DiscordNotifier{}
+	n.webhookNotifier = webhookNotifier{
+		name:    "discord",
+		url:     webhookURL,
+		client:  &http.Client{},
+		payload: n.payload,
+	}
+	return n
+}
+
+// payload builds a message with an embed holding the matched line and
+// context (or what resolved), or plain content when a template is set.
+func (n *DiscordNotifier) payload(a *Alert, text string) map[string]interface{} {
+	if text != "" {
+		return map[string]interface{}{"username": "lx", "content": truncate(text, discordMaxContent)}
+	}
+
+	if a.Resolved {
+		return map[string]interface{}{
+			"username": "lx",
+			"content":  truncate("✅ "+alertTitle(a), discordMaxContent),
+			"embeds": []map[string]interface{}{{
+				"title":       truncate(alertTitle(a), 250),
+				"description": truncate(a.Entry.Message, discordMaxDescription),
+				"color":       0x2EB67D,
+				"timestamp":   a.Time.UTC().Format("2006-01-02T15:04:05.000Z"),
+			}},
+		}
+	}
+
+	desc := "**Source:** " + a.Entry.Source + "\n**Matched line:**\n```" + truncate(formatLine(&a.Entry), 1500) + "```"
+	if ctx := contextText(a, discordMaxDescription-len(desc)-40); ctx != "" {
+		desc += "\n**Recent context:**\n```" + ctx + "```"
+	}
+	return map[string]interface{}{
+		"username": "lx",
+		"content":  truncate("🚨 "+alertTitle(a), discordMaxContent),
+		"embeds": []map[string]interface{}{{
+			"title":       truncate(alertTitle(a), 250),
+			"description": desc,
+			"color":       0xE01E5A,
+			"timestamp":   a.Time.UTC().Format("2006-01-02T15:04:05.000Z"),
+		}},
+	}
+}
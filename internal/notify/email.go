@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailOptions configures an EmailSender.
+type EmailOptions struct {
+	Addr     string   // SMTP server host:port
+	From     string   // envelope and header sender
+	To       []string // recipients
+	Username string   // optional PLAIN auth user
+	Password string
+}
+
+// EmailSender mails alert digests over SMTP, upgrading to TLS with STARTTLS
+// when the server offers it.
+type EmailSender struct {
+	opts EmailOptions
+}
+
+// NewEmailSender validates opts.
+func NewEmailSender(opts EmailOptions) (*EmailSender, error) {
+	if opts.Addr == "" || opts.From == "" || len(opts.To) == 0 {
+		return nil, fmt.Errorf("email: SMTP server, sender and at least one recipient are required")
+	}
+	if _, _, err := net.SplitHostPort(opts.Addr); err != nil {
+		return nil, fmt.Errorf("email: invalid SMTP address %q: %w", opts.Addr, err)
+	}
+	return &EmailSender{opts: opts}, nil
+}
+
+// Name returns the sender identifier.
+func (s *EmailSender) Name() string { return "email" }
+
+// SendDigest mails r as a plain-text message.
+func (s *EmailSender) SendDigest(ctx context.Context, r *DigestReport) error {
+	host, _, _ := net.SplitHostPort(s.opts.Addr)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.opts.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.opts.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.opts.Username, s.opts.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.opts.From); err != nil {
+		return err
+	}
+	for _, to := range s.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.message(r)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message renders the digest as an RFC 5322 message.
+func (s *EmailSender) message(r *DigestReport) []byte {
+	rules := r.Rules()
+	subject := fmt.Sprintf("[lx] %d alert", r.Total)
+	if r.Total != 1 {
+		subject += "s"
+	}
+	subject += ": " + strings.Join(rules, ", ")
+	// Encode non-ASCII rule names, folding the header between encoded words.
+	subject = strings.ReplaceAll(mime.QEncoding.Encode("utf-8", truncate(subject, 150)), "?= =?", "?=\r\n =?")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.opts.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.opts.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", r.End.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+
+	fmt.Fprintf(&b, "lx alert digest %s – %s\r\n\r\n",
+		r.Start.Format("2006-01-02 15:04:05"), r.End.Format("15:04:05 MST"))
+	b.WriteString("Triggers per rule:\r\n")
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "  %6d  %s\r\n", r.Counts[rule], rule)
+	}
+
+	b.WriteString("\r\nSample lines:\r\n")
+	for i := range r.Samples {
+		smp := &r.Samples[i]
+		fmt.Fprintf(&b, "  [%s] %s", strings.Join(smp.Rules, ", "), formatLine(&smp.Entry))
+		if smp.Entry.Source != "" {
+			fmt.Fprintf(&b, "  (%s)", smp.Entry.Source)
+		}
+		b.WriteString("\r\n")
+	}
+	if r.Dropped > 0 {
+		fmt.Fprintf(&b, "  … and %d more\r\n", r.Dropped)
+	}
+
+	// Dot-stuffing is handled by the SMTP data writer; only normalize bare LFs.
+	return []byte(strings.ReplaceAll(strings.ReplaceAll(b.String(), "\r\n", "\n"), "\n", "\r\n"))
+}
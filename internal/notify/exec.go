@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// execMaxOutput bounds the command output quoted in an error.
+const execMaxOutput = 500
+
+// ExecNotifier runs a shell command when an alert triggers, e.g. to capture
+// a goroutine dump or restart a service. The matching entry is passed in
+// environment variables (LX_ALERT_RULE, LX_ALERT_SEVERITY, LX_MESSAGE,
+// LX_LEVEL, LX_SOURCE, LX_STREAM, LX_TIMESTAMP, LX_SUPPRESSED and
+// LX_FIELD_<NAME> per field) and the recent context lines, ending with the
+// match, on stdin. A non-zero exit is reported with the command's output.
+type ExecNotifier struct {
+	command string
+	lookup  func(rule string) string
+}
+
+// NewExecNotifier creates a notifier running command for every alert.
+// lookup (may be nil) returns a rule's own command, which replaces command
+// for that rule; a rule with neither runs nothing.
+func NewExecNotifier(command string, lookup func(rule string) string) *ExecNotifier {
+	return &ExecNotifier{command: command, lookup: lookup}
+}
+
+// Name returns the notifier identifier.
+func (n *ExecNotifier) Name() string { return "exec" }
+
+// Notify runs the command of each triggered rule, once per distinct command.
+// Resolved rules run nothing.
+func (n *ExecNotifier) Notify(ctx context.Context, a *Alert) error {
+	if a.Resolved {
+		return nil
+	}
+	ran := make(map[string]bool)
+	for _, rule := range a.Rules {
+		command := n.command
+		if n.lookup != nil {
+			if c := n.lookup(rule); c != "" {
+				command = c
+			}
+		}
+		if command == "" || ran[command] {
+			continue
+		}
+		ran[command] = true
+		if err := n.run(ctx, command, rule, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ExecNotifier) run(ctx context.Context, command, rule string, a *Alert) error {
+	var stdin bytes.Buffer
+	for i := range a.Context {
+		stdin.WriteString(formatLine(&a.Context[i]))
+		stdin.WriteByte('\n')
+	}
+	if len(a.Context) == 0 {
+		stdin.WriteString(formatLine(&a.Entry))
+		stdin.WriteByte('\n')
+	}
+	for i := range a.After {
+		stdin.WriteString(formatLine(&a.After[i]))
+		stdin.WriteByte('\n')
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = &stdin
+	cmd.Env = append(os.Environ(), alertEnv(rule, a)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) > execMaxOutput {
+			msg = "…" + msg[len(msg)-execMaxOutput:]
+		}
+		if msg != "" {
+			return fmt.Errorf("%q: %w: %s", command, err, msg)
+		}
+		return fmt.Errorf("%q: %w", command, err)
+	}
+	return nil
+}
+
+// alertEnv describes the alert as environment variables.
+func alertEnv(rule string, a *Alert) []string {
+	e := &a.Entry
+	env := []string{
+		"LX_ALERT_RULE=" + rule,
+		"LX_ALERT_RULES=" + strings.Join(a.Rules, "\n"),
+		"LX_MESSAGE=" + e.Message,
+		"LX_LEVEL=" + e.Level.String(),
+		"LX_SOURCE=" + e.Source,
+		"LX_STREAM=" + e.Stream,
+		"LX_TIMESTAMP=" + e.Timestamp.Format(time.RFC3339Nano),
+		"LX_SUPPRESSED=" + strconv.Itoa(a.Suppressed),
+		"LX_ALERT_SEVERITY=" + a.Severity,
+	}
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, "LX_FIELD_"+envName(k)+"="+e.Fields[k])
+	}
+	return env
+}
+
+// envName upper-cases a field name and replaces characters that are not
+// allowed in environment variable names with underscores.
+func envName(s string) string {
+	b := []byte(strings.ToUpper(s))
+	for i, c := range b {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,388 @@
+// Package notify delivers alert notifications (Slack, ...) when the alert
+// engine triggers, with per-rule rate limiting, and periodic alert digests
+// (email).
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/buffer"
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/monitor"
+)
+
+// Alert describes one triggered alert, or with Resolved, rules that stopped
+// firing.
+type Alert struct {
+	Rules      []string         // names of the rules that matched
+	Entry      entry.LogEntry   // the matching entry
+	Context    []entry.LogEntry // recent entries leading up to (and including) Entry
+	After      []entry.LogEntry // entries following Entry, with SetAfter
+	Suppressed int              // alerts for these rules dropped by rate limiting since the last notification
+	Severity   string           // the highest severity set by the rules, if any
+	Resolved   bool             // the rules resolved; Entry describes how
+	Time       time.Time
+}
+
+// Routing returns a rule's severity and the names of the notifiers and
+// digests it goes to; no names means all of them.
+type Routing func(rule string) (severity string, targets []string)
+
+// Notifier sends an alert to an external service.
+type Notifier interface {
+	Notify(ctx context.Context, a *Alert) error
+	Name() string
+}
+
+// Dispatcher rate-limits alerts per rule and sends them to every notifier
+// from a background goroutine, so slow endpoints never block the pipeline.
+type Dispatcher struct {
+	notifiers    []Notifier
+	interval     time.Duration
+	contextLines int
+	onError      func(error)
+	routing      Routing
+	minSeverity  string
+	afterLines   int
+	afterWait    time.Duration
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time
+	suppressed map[string]int
+	digests    []*digest
+	waiting    []*waitingAlert
+
+	queue chan *Alert
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewDispatcher creates a dispatcher that sends at most one alert per rule
+// every interval, attaching up to contextLines recent entries. onError (may
+// be nil) receives delivery failures.
+func NewDispatcher(interval time.Duration, contextLines int, onError func(error), notifiers ...Notifier) *Dispatcher {
+	d := &Dispatcher{
+		notifiers:    notifiers,
+		interval:     interval,
+		contextLines: contextLines,
+		onError:      onError,
+		lastSent:     make(map[string]time.Time),
+		suppressed:   make(map[string]int),
+		queue:        make(chan *Alert, 64),
+		done:         make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// SetRouting sets how rules are routed to notifiers and digests. Call it
+// before alerts are recorded.
+func (d *Dispatcher) SetRouting(r Routing) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.routing = r
+}
+
+// SetMinSeverity makes notifiers skip rules below severity s (see
+// monitor.Severities), so that e.g. only critical alerts page someone.
+// Digests still report every rule. Call it before alerts are recorded.
+func (d *Dispatcher) SetMinSeverity(s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.minSeverity = s
+}
+
+// pages reports whether rule is severe enough for the notifiers. Must be
+// called with d.mu held.
+func (d *Dispatcher) pages(rule string) bool {
+	if d.minSeverity == "" {
+		return true
+	}
+	var s string
+	if d.routing != nil {
+		s, _ = d.routing(rule)
+	}
+	return monitor.SeverityRank(s) >= monitor.SeverityRank(d.minSeverity)
+}
+
+// waitingAlert is an alert collecting the lines after its entry.
+type waitingAlert struct {
+	alert    *Alert
+	deadline time.Time
+}
+
+// SetAfter makes alerts wait for the next lines lines passed to Observe,
+// or for wait at most, and include them. Call it before alerts are recorded.
+func (d *Dispatcher) SetAfter(lines int, wait time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.afterLines, d.afterWait = lines, wait
+}
+
+// Observe adds a line to the alerts waiting for lines after their entry,
+// and sends those that have enough. Call it for every line.
+func (d *Dispatcher) Observe(e *entry.LogEntry) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	if len(d.waiting) == 0 {
+		d.mu.Unlock()
+		return
+	}
+	var ready []*Alert
+	keep := d.waiting[:0]
+	for _, w := range d.waiting {
+		w.alert.After = append(w.alert.After, *e)
+		if len(w.alert.After) >= d.afterLines {
+			ready = append(ready, w.alert)
+		} else {
+			keep = append(keep, w)
+		}
+	}
+	clear(d.waiting[len(keep):])
+	d.waiting = keep
+	d.mu.Unlock()
+
+	for _, a := range ready {
+		d.enqueue(a)
+	}
+}
+
+// expired removes and returns the waiting alerts whose deadline passed
+// (all of them if force).
+func (d *Dispatcher) expired(now time.Time, force bool) []*Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var ready []*Alert
+	keep := d.waiting[:0]
+	for _, w := range d.waiting {
+		if force || !now.Before(w.deadline) {
+			ready = append(ready, w.alert)
+		} else {
+			keep = append(keep, w)
+		}
+	}
+	clear(d.waiting[len(keep):])
+	d.waiting = keep
+	return ready
+}
+
+// enqueue hands an alert to the delivery goroutine, dropping it if the
+// queue is full.
+func (d *Dispatcher) enqueue(a *Alert) {
+	select {
+	case d.queue <- a:
+	default:
+	}
+}
+
+// routed returns the rules that go to the notifier or digest called name.
+func (d *Dispatcher) routed(rules []string, name string) []string {
+	if d.routing == nil {
+		return rules
+	}
+	var out []string
+	for _, r := range rules {
+		_, targets := d.routing(r)
+		ok := len(targets) == 0
+		for _, t := range targets {
+			ok = ok || t == name
+		}
+		if ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// severity returns the highest severity set by rules.
+func (d *Dispatcher) severity(rules []string) string {
+	if d.routing == nil {
+		return ""
+	}
+	best := ""
+	for _, r := range rules {
+		s, _ := d.routing(r)
+		if s != "" && (best == "" || monitor.SeverityRank(s) > monitor.SeverityRank(best)) {
+			best = s
+		}
+	}
+	return best
+}
+
+// AddDigest sends sender a report of every alert triggered during each
+// window, with counts per rule and up to samples example lines. Digests are
+// not rate limited. Call it before alerts are recorded.
+func (d *Dispatcher) AddDigest(sender DigestSender, window time.Duration, samples int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.digests = append(d.digests, &digest{sender: sender, window: window, samples: samples})
+}
+
+// Alert records a triggered alert. It returns immediately; the alert is
+// dropped if every rule is within its rate-limit interval or the queue is full.
+func (d *Dispatcher) Alert(rules []string, e *entry.LogEntry, ring *buffer.Ring) {
+	if d == nil || len(rules) == 0 {
+		return
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	for _, g := range d.digests {
+		if rs := d.routed(rules, g.sender.Name()); len(rs) > 0 {
+			g.collect(rs, e, now)
+		}
+	}
+	var send []string
+	suppressed := 0
+	for _, r := range rules {
+		if !d.pages(r) {
+			continue
+		}
+		if last, ok := d.lastSent[r]; ok && now.Sub(last) < d.interval {
+			d.suppressed[r]++
+			continue
+		}
+		d.lastSent[r] = now
+		suppressed += d.suppressed[r]
+		d.suppressed[r] = 0
+		send = append(send, r)
+	}
+	d.mu.Unlock()
+	if len(send) == 0 || len(d.notifiers) == 0 {
+		return
+	}
+
+	a := &Alert{Rules: send, Entry: *e, Suppressed: suppressed, Severity: d.severity(send), Time: now}
+	if ring != nil && d.contextLines > 0 {
+		a.Context = ring.Last(d.contextLines)
+	}
+
+	if d.afterLines > 0 {
+		d.mu.Lock()
+		d.waiting = append(d.waiting, &waitingAlert{alert: a, deadline: now.Add(d.afterWait)})
+		d.mu.Unlock()
+		return
+	}
+	d.enqueue(a)
+}
+
+// Resolve records that rules stopped firing, described by e, and tells the
+// notifiers about the ones they were sent, so that a page can be closed.
+// Resolves are not rate limited, and the next alert for these rules is
+// sent right away.
+func (d *Dispatcher) Resolve(rules []string, e *entry.LogEntry) {
+	if d == nil || len(rules) == 0 {
+		return
+	}
+
+	d.mu.Lock()
+	var send []string
+	for _, r := range rules {
+		if _, ok := d.lastSent[r]; ok {
+			delete(d.lastSent, r)
+			send = append(send, r)
+		}
+	}
+	d.mu.Unlock()
+	if len(send) == 0 || len(d.notifiers) == 0 {
+		return
+	}
+	d.enqueue(&Alert{Rules: send, Entry: *e, Severity: d.severity(send), Resolved: true, Time: time.Now()})
+}
+
+// Close sends the alerts still waiting for lines after their entry, then
+// waits for queued alerts to be delivered.
+func (d *Dispatcher) Close() {
+	if d == nil {
+		return
+	}
+	d.once.Do(func() {
+		for _, a := range d.expired(time.Now(), true) {
+			d.queue <- a
+		}
+		close(d.queue)
+		<-d.done
+	})
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case a, ok := <-d.queue:
+			if !ok {
+				d.sendDigests(time.Now(), true)
+				return
+			}
+			for _, n := range d.notifiers {
+				rs := d.routed(a.Rules, n.Name())
+				if len(rs) == 0 {
+					continue
+				}
+				na := a
+				if len(rs) < len(a.Rules) {
+					c := *a
+					c.Rules, c.Severity = rs, d.severity(rs)
+					na = &c
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+				err := n.Notify(ctx, na)
+				cancel()
+				d.report(n.Name(), err)
+			}
+		case now := <-ticker.C:
+			for _, a := range d.expired(now, false) {
+				d.enqueue(a)
+			}
+			d.sendDigests(now, false)
+		}
+	}
+}
+
+// sendDigests delivers digests whose window has ended (all pending ones if force).
+func (d *Dispatcher) sendDigests(now time.Time, force bool) {
+	d.mu.Lock()
+	digests := d.digests
+	d.mu.Unlock()
+
+	for _, g := range digests {
+		r := g.take(now, force)
+		if r == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := g.sender.SendDigest(ctx, r)
+		cancel()
+		d.report(g.sender.Name(), err)
+	}
+}
+
+// report passes a delivery failure to onError.
+func (d *Dispatcher) report(name string, err error) {
+	if err != nil && d.onError != nil {
+		d.onError(fmt.Errorf("notify %s: %w", name, err))
+	}
+}
+
+// formatLine renders an entry as a single plain-text log line.
+func formatLine(e *entry.LogEntry) string {
+	var sb strings.Builder
+	sb.WriteString(e.Timestamp.Format("15:04:05"))
+	if e.Level != entry.LevelUnknown {
+		sb.WriteString(" ")
+		sb.WriteString(e.Level.String())
+	}
+	sb.WriteString(" ")
+	sb.WriteString(e.Message)
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// slackMaxText keeps code blocks under Slack's 3000-character section limit.
+const slackMaxText = 2800
+
+// SlackNotifier posts alerts to a Slack incoming webhook.
+type SlackNotifier struct {
+	webhookNotifier
+	channel string // optional override of the webhook's default channel
+}
+
+// NewSlackNotifier creates a notifier for the given incoming webhook URL.
+func NewSlackNotifier(webhookURL, channel string) *SlackNotifier {
+	n := &SlackNotifier{channel: channel}
+	n.webhookNotifier = webhookNotifier{
+		name:    "slack",
+		url:     webhookURL,
+		client:  &http.Client{},
+		payload: n.payload,
+	}
+	return n
+}
+
+// payload builds a Block Kit message: rule names, source, the matched line
+// and the recent context lines, or a single line for resolved rules. A
+// custom template replaces the blocks with its text.
+func (n *SlackNotifier) payload(a *Alert, text string) map[string]interface{} {
+	payload := map[string]interface{}{}
+	switch {
+	case text != "":
+		payload["text"] = text
+	case a.Resolved:
+		payload["text"] = "lx " + a.Entry.Message
+		payload["blocks"] = []map[string]interface{}{
+			section(fmt.Sprintf(":white_check_mark: lx resolved `%s`\n%s", strings.Join(a.Rules, "`, `"), a.Entry.Message)),
+		}
+	default:
+		title := fmt.Sprintf(":rotating_light: lx alert `%s`", strings.Join(a.Rules, "`, `"))
+		if a.Severity != "" {
+			title = fmt.Sprintf(":rotating_light: lx %s alert `%s`", a.Severity, strings.Join(a.Rules, "`, `"))
+		}
+		if a.Suppressed > 0 {
+			title += fmt.Sprintf(" (+%d suppressed)", a.Suppressed)
+		}
+
+		blocks := []map[string]interface{}{
+			section(title + "\n*Source:* " + a.Entry.Source),
+			section("*Matched line:*\n```" + truncate(formatLine(&a.Entry), slackMaxText) + "```"),
+		}
+		if ctx := contextText(a, slackMaxText); ctx != "" {
+			blocks = append(blocks, section("*Recent context:*\n```"+ctx+"```"))
+		}
+
+		payload["text"] = fmt.Sprintf("lx alert %s: %s", strings.Join(a.Rules, ", "), a.Entry.Message)
+		payload["blocks"] = blocks
+	}
+	if n.channel != "" {
+		payload["channel"] = n.channel
+	}
+	return payload
+}
+
+// section builds a Block Kit mrkdwn section.
+func section(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]string{"type": "mrkdwn", "text": text},
+	}
+}
+
+// truncate shortens s to at most n bytes, without splitting a UTF-8
+// sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
@@ -0,0 +1,76 @@
+package notify
+
+import "net/http"
+
+// teamsMaxText keeps the card well under Teams' 28 KB payload limit.
+const teamsMaxText = 8000
+
+// TeamsNotifier posts alerts to a Microsoft Teams channel through an incoming
+// webhook or a Workflows "post to a channel when a webhook request is
+// received" URL, as an Adaptive Card.
+type TeamsNotifier struct {
+	webhookNotifier
+}
+
+// NewTeamsNotifier creates a notifier for the given webhook URL.
+func NewTeamsNotifier(webhookURL string) *TeamsNotifier {
+	n := &TeamsNotifier{}
+	n.webhookNotifier = webhookNotifier{
+		name:    "teams",
+		url:     webhookURL,
+		client:  &http.Client{},
+		payload: n.payload,
+	}
+	return n
+}
+
+// payload wraps the alert in an Adaptive Card message.
+func (n *TeamsNotifier) payload(a *Alert, text string) map[string]interface{} {
+	var body []map[string]interface{}
+	switch {
+	case text != "":
+		body = append(body, textBlock(truncate(text, teamsMaxText), false))
+	case a.Resolved:
+		body = append(body,
+			map[string]interface{}{
+				"type": "TextBlock", "text": alertTitle(a), "weight": "Bolder",
+				"size": "Medium", "color": "Good", "wrap": true,
+			},
+			textBlock(a.Entry.Message, false),
+		)
+	default:
+		body = append(body,
+			map[string]interface{}{
+				"type": "TextBlock", "text": alertTitle(a), "weight": "Bolder",
+				"size": "Medium", "color": "Attention", "wrap": true,
+			},
+			textBlock("Source: "+a.Entry.Source, false),
+			textBlock(truncate(formatLine(&a.Entry), teamsMaxText), true),
+		)
+		if ctx := contextText(a, teamsMaxText); ctx != "" {
+			body = append(body, textBlock("Recent context:", false), textBlock(ctx, true))
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// textBlock builds an Adaptive Card TextBlock, monospaced for log lines.
+func textBlock(text string, monospace bool) map[string]interface{} {
+	b := map[string]interface{}{"type": "TextBlock", "text": text, "wrap": true}
+	if monospace {
+		b["fontType"] = "Monospace"
+	}
+	return b
+}
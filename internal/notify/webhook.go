@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// webhookNotifier is the common core of chat notifiers (Slack, Discord,
+// Teams): it renders the alert text, lets the service-specific payload
+// builder wrap it, and POSTs the JSON to an incoming webhook URL.
+type webhookNotifier struct {
+	name   string
+	url    string
+	client *http.Client
+	tmpl   *template.Template // optional custom message text
+
+	// payload builds the request body. text is the rendered template, or ""
+	// when no template is set and the builder should use its default layout.
+	payload func(a *Alert, text string) map[string]interface{}
+}
+
+// SetTemplate replaces the default message layout with a text/template
+// executed against the Alert (see ParseTemplate).
+func (n *webhookNotifier) SetTemplate(t *template.Template) {
+	n.tmpl = t
+}
+
+// Name returns the notifier identifier.
+func (n *webhookNotifier) Name() string { return n.name }
+
+// Notify renders and posts the alert.
+func (n *webhookNotifier) Notify(ctx context.Context, a *Alert) error {
+	var text string
+	if n.tmpl != nil {
+		var buf bytes.Buffer
+		if err := n.tmpl.Execute(&buf, a); err != nil {
+			return fmt.Errorf("template: %w", err)
+		}
+		text = buf.String()
+	}
+
+	body, err := json.Marshal(n.payload(a, text))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// templateFuncs are available to notification templates.
+var templateFuncs = template.FuncMap{
+	"line": func(e entry.LogEntry) string { return formatLine(&e) },
+	"join": strings.Join,
+}
+
+// ParseTemplate parses a notification message template. It is executed
+// against the Alert: {{.Rules}}, {{.Severity}}, {{.Resolved}},
+// {{.Entry.Message}}, {{.Entry.Source}}, {{.Suppressed}},
+// {{range .Context}}...{{end}} (lines up to
+// the entry), {{range .After}}...{{end}} (lines after it), plus the helpers
+// {{join .Rules ", "}} and {{line .Entry}} (an entry as a text line).
+func ParseTemplate(text string) (*template.Template, error) {
+	t, err := template.New("notify").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("notify template: %w", err)
+	}
+	return t, nil
+}
+
+// contextText joins the alert's context lines and the lines after its
+// entry, keeping the last max bytes.
+func contextText(a *Alert, max int) string {
+	var lines []string
+	for i := range a.Context {
+		lines = append(lines, formatLine(&a.Context[i]))
+	}
+	for i := range a.After {
+		lines = append(lines, formatLine(&a.After[i]))
+	}
+	text := strings.Join(lines, "\n")
+	if len(text) > max {
+		text = "…" + text[len(text)-max:]
+	}
+	return text
+}
+
+// alertTitle is the one-line summary shared by the default layouts.
+func alertTitle(a *Alert) string {
+	if a.Resolved {
+		return "lx resolved: " + strings.Join(a.Rules, ", ")
+	}
+	title := "lx alert: " + strings.Join(a.Rules, ", ")
+	if a.Severity != "" {
+		title = "lx " + a.Severity + " alert: " + strings.Join(a.Rules, ", ")
+	}
+	if a.Suppressed > 0 {
+		title += fmt.Sprintf(" (+%d suppressed)", a.Suppressed)
+	}
+	return title
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// Parser extracts fields from an entry's message. Parse reports whether the
+// message was in the parser's format.
+type Parser interface {
+	Parse(e *entry.LogEntry) bool
+}
+
+// FormatNames lists the formats NewFormatParser knows, in the order
+// AutoParser prefers them on a tie.
+var FormatNames = []string{"json", "syslog", "combined", "common", "logfmt"}
+
+// NewFormatParser returns the parser for a named format: json, syslog,
+// combined (Apache/nginx combined access log), common (Common Log Format)
+// or logfmt.
+func NewFormatParser(name string) (Parser, error) {
+	switch name {
+	case "json":
+		return NewJSONParser(), nil
+	case "syslog":
+		return NewSyslogParser(), nil
+	case "combined":
+		return NewGrokParser(`^%{COMBINEDAPACHELOG}`)
+	case "common":
+		return NewGrokParser(`^%{COMMONAPACHELOG}$`)
+	case "logfmt":
+		return NewLogfmtParser(), nil
+	}
+	return nil, fmt.Errorf("unknown log format %q (want %s)", name, strings.Join(FormatNames, ", "))
+}
+
+// AutoParser detects the log format. Each of the first sample lines is tried
+// against every known format and parsed with the first that fits; after
+// that, the format that fit the most sampled lines (at least half of them)
+// is used for the rest of the stream. If none does, lines are left as is.
+type AutoParser struct {
+	names   []string
+	formats []Parser
+	scores  []int
+	sample  int
+	seen    int
+	chosen  Parser
+
+	// OnDetect, if set, is called once the format is chosen ("" for none).
+	OnDetect func(format string, matched, sampled int)
+}
+
+// NewAutoParser creates a parser that samples the first sample lines.
+func NewAutoParser(sample int) (*AutoParser, error) {
+	if sample <= 0 {
+		sample = 50
+	}
+	a := &AutoParser{sample: sample, scores: make([]int, len(FormatNames))}
+	for _, name := range FormatNames {
+		p, err := NewFormatParser(name)
+		if err != nil {
+			return nil, err
+		}
+		a.names = append(a.names, name)
+		a.formats = append(a.formats, p)
+	}
+	return a, nil
+}
+
+// Parse parses e with the detected format, or while sampling with the first
+// format that fits it.
+func (a *AutoParser) Parse(e *entry.LogEntry) bool {
+	if a.seen >= a.sample {
+		return a.chosen != nil && a.chosen.Parse(e)
+	}
+
+	a.seen++
+	first := -1
+	for i, p := range a.formats {
+		trial := *e
+		trial.Fields = nil
+		if p.Parse(&trial) {
+			a.scores[i]++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	if a.seen == a.sample {
+		a.decide()
+	}
+	return first >= 0 && a.formats[first].Parse(e)
+}
+
+// decide picks the format that fit the most sampled lines.
+func (a *AutoParser) decide() {
+	best := -1
+	for i, score := range a.scores {
+		if score*2 >= a.seen && (best < 0 || score > a.scores[best]) {
+			best = i
+		}
+	}
+	name, matched := "", 0
+	if best >= 0 {
+		a.chosen = a.formats[best]
+		name, matched = a.names[best], a.scores[best]
+	}
+	if a.OnDetect != nil {
+		a.OnDetect(name, matched, a.seen)
+	}
+}
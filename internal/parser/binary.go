@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/msgpack"
+)
+
+// PayloadDecoder decodes a binary message payload into a record whose values
+// are JSON-like: maps, slices, strings, json.Number, bool and nil.
+type PayloadDecoder interface {
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+// BinaryParser decodes binary payloads, such as protobuf or MessagePack
+// messages read from NATS or Redis, into fields, so they show up as records
+// instead of mojibake. Fields are flattened and mapped onto the entry like
+// JSON lines; a record without a message field is shown as compact JSON. The
+// original bytes are kept in Raw.
+type BinaryParser struct {
+	dec PayloadDecoder
+}
+
+// NewBinaryParser creates a parser decoding payloads with dec.
+func NewBinaryParser(dec PayloadDecoder) *BinaryParser {
+	return &BinaryParser{dec: dec}
+}
+
+// Parse decodes e.Raw (or e.Message when Raw is empty). Returns true if it
+// held a record.
+func (p *BinaryParser) Parse(e *entry.LogEntry) bool {
+	data := e.Raw
+	if len(data) == 0 {
+		data = []byte(e.Message)
+	}
+	record, err := p.dec.Decode(data)
+	if err != nil || len(record) == 0 {
+		return false
+	}
+
+	if len(e.Raw) == 0 {
+		e.Raw = data
+	}
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(record))
+	}
+	flattenJSON(e.Fields, "", record)
+	e.Message = ""
+	promoteKeys(e)
+	if e.Message == "" {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(record)
+		e.Message = strings.TrimSuffix(buf.String(), "\n")
+	}
+	return true
+}
+
+// MsgpackDecoder decodes MessagePack maps.
+type MsgpackDecoder struct{}
+
+// NewMsgpackDecoder creates a MessagePack payload decoder.
+func NewMsgpackDecoder() *MsgpackDecoder {
+	return &MsgpackDecoder{}
+}
+
+// Decode decodes data, which must hold a map. Lengths declared in data are
+// bounded by len(data), so a crafted line cannot make it allocate more.
+func (d *MsgpackDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	v, err := msgpack.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("msgpack payload is not a map")
+	}
+	return jsonValue(m).(map[string]interface{}), nil
+}
+
+// jsonValue converts a decoded MessagePack value to its JSON-like form:
+// numbers become json.Number, bin becomes a string (base64 unless it is
+// UTF-8 text) and extension values a "type:base64" string.
+func jsonValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, val := range x {
+			x[k] = jsonValue(val)
+		}
+		return x
+	case []interface{}:
+		for i, val := range x {
+			x[i] = jsonValue(val)
+		}
+		return x
+	case int64:
+		return json.Number(strconv.FormatInt(x, 10))
+	case uint64:
+		return json.Number(strconv.FormatUint(x, 10))
+	case float64:
+		return json.Number(strconv.FormatFloat(x, 'g', -1, 64))
+	case []byte:
+		if utf8.Valid(x) {
+			return string(x)
+		}
+		return base64.StdEncoding.EncodeToString(x)
+	case msgpack.Ext:
+		return fmt.Sprintf("%d:%s", x.Type, base64.StdEncoding.EncodeToString(x.Data))
+	default:
+		return x
+	}
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/msgpack"
+)
+
+func TestMsgpackDecoder(t *testing.T) {
+	data, err := msgpack.Append(nil, map[string]interface{}{"msg": "hi", "level": "warn", "n": int64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := entry.LogEntry{Raw: data}
+	if !NewBinaryParser(NewMsgpackDecoder()).Parse(&e) {
+		t.Fatal("Parse = false")
+	}
+	if e.Message != "hi" || e.Fields["n"] != "3" {
+		t.Errorf("Parse gave message %q, fields %v", e.Message, e.Fields)
+	}
+}
+
+func TestMsgpackDecoderHugeLength(t *testing.T) {
+	tests := [][]byte{
+		{0xdd, 0x7f, 0xff, 0xff, 0xff},                  // array of 2^31-1 elements
+		{0xdf, 0x7f, 0xff, 0xff, 0xff},                  // map of 2^31-1 pairs
+		{0x81, 0xa1, 'k', 0xc6, 0xff, 0xff, 0xff, 0xff}, // bin of 4 GiB
+	}
+	for _, data := range tests {
+		_, err := NewMsgpackDecoder().Decode(data)
+		if !errors.Is(err, msgpack.ErrTooLarge) {
+			t.Errorf("Decode(% x) = %v, want ErrTooLarge", data, err)
+		}
+		e := entry.LogEntry{Raw: data, Message: "raw"}
+		if NewBinaryParser(NewMsgpackDecoder()).Parse(&e) {
+			t.Errorf("Parse(% x) = true", data)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// GELFParser decodes Graylog Extended Log Format messages:
+//
+//	{"version":"1.1","host":"web1","short_message":"boom","full_message":"trace…",
+//	 "timestamp":1700000000.123,"level":3,"_user_id":42}
+//
+// short_message becomes the message, level (a syslog severity) the level and
+// timestamp the timestamp. host and full_message go into Fields, as do the
+// additional fields without their leading underscore.
+type GELFParser struct{}
+
+// NewGELFParser creates a GELF parser.
+func NewGELFParser() *GELFParser {
+	return &GELFParser{}
+}
+
+// Parse decodes e.Message if it is a GELF object. Returns true if it was.
+func (p *GELFParser) Parse(e *entry.LogEntry) bool {
+	msg := strings.TrimSpace(e.Message)
+	if len(msg) < 2 || msg[0] != '{' {
+		return false
+	}
+	dec := json.NewDecoder(strings.NewReader(msg))
+	dec.UseNumber()
+	var record map[string]interface{}
+	if err := dec.Decode(&record); err != nil {
+		return false
+	}
+	short, ok := record["short_message"].(string)
+	if !ok {
+		return false
+	}
+
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(record))
+	}
+	for k, v := range record {
+		switch k {
+		case "short_message", "version", "timestamp", "level":
+			continue
+		}
+		var val string
+		switch x := v.(type) {
+		case string:
+			val = x
+		case json.Number:
+			val = x.String()
+		case bool:
+			val = strconv.FormatBool(x)
+		case nil:
+		default:
+			raw, _ := json.Marshal(x)
+			val = string(raw)
+		}
+		e.Fields[strings.TrimPrefix(k, "_")] = val
+	}
+
+	if len(e.Raw) == 0 {
+		e.Raw = []byte(e.Message)
+	}
+	e.Message = short
+
+	// GELF's default level is 1 (alert).
+	e.Level = SyslogLevel(1)
+	if n, ok := record["level"].(json.Number); ok {
+		if sev, err := n.Int64(); err == nil {
+			e.Level = SyslogLevel(int(sev))
+		}
+	}
+	if n, ok := record["timestamp"].(json.Number); ok {
+		if f, err := n.Float64(); err == nil && f > 0 {
+			sec, frac := math.Modf(f)
+			e.Timestamp = time.Unix(int64(sec), int64(frac*1e9))
+		}
+	}
+	return true
+}
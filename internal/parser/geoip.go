@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// geoIPKeys are the fields looked up by default: the client address as named
+// by the access-log Grok patterns and common JSON loggers.
+var geoIPKeys = []string{"clientip", "client_ip", "client", "remote_addr", "remote_ip", "ip", "src_ip", "source_ip"}
+
+// geoRecord holds the parts of a GeoLite2/GeoIP2 City, Country or ASN record
+// that GeoIP uses; each database fills in what it has.
+type geoRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	ASN   uint   `maxminddb:"autonomous_system_number"`
+	ASOrg string `maxminddb:"autonomous_system_organization"`
+}
+
+// GeoIP looks IP fields up in local MaxMind databases (.mmdb) and adds
+// <field>.country (ISO code), <field>.city, <field>.asn and <field>.as_org,
+// e.g. clientip.asn. City and ASN data come in separate files, so several
+// databases can be given; each adds what it knows. Fields that are not IP
+// addresses or not in any database are left alone.
+type GeoIP struct {
+	readers []*maxminddb.Reader
+	keys    []string
+}
+
+// OpenGeoIP opens the databases at paths. keys names the IP fields to look
+// up; empty means the usual client address fields (clientip, remote_addr,
+// ip, ...).
+func OpenGeoIP(paths, keys []string) (*GeoIP, error) {
+	g := &GeoIP{keys: keys}
+	if len(g.keys) == 0 {
+		g.keys = geoIPKeys
+	}
+	for _, path := range paths {
+		r, err := maxminddb.Open(path)
+		if err != nil {
+			g.Close()
+			return nil, fmt.Errorf("open geoip database %s: %w", path, err)
+		}
+		g.readers = append(g.readers, r)
+	}
+	if len(g.readers) == 0 {
+		return nil, errors.New("no geoip database given")
+	}
+	return g, nil
+}
+
+// Parse adds location fields for each IP field of e. Returns true if any
+// address was found in a database.
+func (g *GeoIP) Parse(e *entry.LogEntry) bool {
+	found := false
+	for _, k := range g.keys {
+		v, ok := e.Fields[k]
+		if !ok {
+			continue
+		}
+		ip := net.ParseIP(strings.Trim(v, "[]"))
+		if ip == nil {
+			continue
+		}
+		for _, r := range g.readers {
+			var rec geoRecord
+			if err := r.Lookup(ip, &rec); err != nil {
+				continue
+			}
+			if rec.Country.ISOCode != "" {
+				e.Fields[k+".country"] = rec.Country.ISOCode
+				found = true
+			}
+			if city := rec.City.Names["en"]; city != "" {
+				e.Fields[k+".city"] = city
+				found = true
+			}
+			if rec.ASN != 0 {
+				e.Fields[k+".asn"] = strconv.FormatUint(uint64(rec.ASN), 10)
+				e.Fields[k+".as_org"] = rec.ASOrg
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// Close releases the databases.
+func (g *GeoIP) Close() error {
+	var errs []error
+	for _, r := range g.readers {
+		errs = append(errs, r.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -3,45 +3,110 @@ package parser
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
-
-	"github.com/Geun-Oh/lx/internal/entry"
 )
 
-// builtinPatterns provides commonly used Grok-style named patterns.
+// builtinPatterns provides commonly used Grok-style named patterns. Patterns
+// may refer to other patterns with %{NAME} or %{NAME:field}; composites such
+// as COMBINEDAPACHELOG capture their own fields.
 var builtinPatterns = map[string]string{
-	"IP":         `(?:\d{1,3}\.){3}\d{1,3}`,
-	"IPV6":       `[0-9A-Fa-f:]+`,
-	"WORD":       `\w+`,
-	"INT":        `[+-]?\d+`,
-	"NUMBER":     `[+-]?(?:\d+\.?\d*|\.\d+)`,
-	"NOTSPACE":   `\S+`,
-	"DATA":       `.*?`,
-	"GREEDYDATA": `.*`,
-	"TIMESTAMP":  `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`,
-	"LOGLEVEL":   `(?:DEBUG|INFO|WARN(?:ING)?|ERROR|ERR|FATAL|PANIC|CRITICAL|TRACE)`,
-	"PATH":       `(?:/[\w.]+)+`,
-	"URI":        `\S+://\S+`,
-	"UUID":       `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
-	"MAC":        `(?:[0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}`,
-	"HTTPMETHOD": `(?:GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|CONNECT|TRACE)`,
-	"STATUSCODE": `\d{3}`,
-	"QS":         `"[^"]*"`,
+	"IP":           `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV4":         `(?:\d{1,3}\.){3}\d{1,3}`,
+	"IPV6":         `[0-9A-Fa-f:]+`,
+	"WORD":         `\w+`,
+	"INT":          `[+-]?\d+`,
+	"POSINT":       `\b[1-9][0-9]*\b`,
+	"NONNEGINT":    `\b[0-9]+\b`,
+	"NUMBER":       `[+-]?(?:\d+\.?\d*|\.\d+)`,
+	"BASE16NUM":    `(?:0[xX])?[0-9A-Fa-f]+`,
+	"SPACE":        `\s*`,
+	"NOTSPACE":     `\S+`,
+	"DATA":         `.*?`,
+	"GREEDYDATA":   `.*`,
+	"TIMESTAMP":    `\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`,
+	"LOGLEVEL":     `(?:DEBUG|INFO|WARN(?:ING)?|ERROR|ERR|FATAL|PANIC|CRITICAL|TRACE)`,
+	"PATH":         `(?:/[\w.]+)+`,
+	"URI":          `\S+://\S+`,
+	"URIPATH":      `(?:/[A-Za-z0-9$.+!*'(){},~:;=@#%&_\-]*)+`,
+	"URIPARAM":     `\?[A-Za-z0-9$.+!*'|(){},~@#%&/=:;_?\-\[\]<>]*`,
+	"URIPATHPARAM": `%{URIPATH}(?:%{URIPARAM})?`,
+	"UUID":         `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+	"MAC":          `(?:[0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}`,
+	"HTTPMETHOD":   `(?:GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|CONNECT|TRACE)`,
+	"STATUSCODE":   `\d{3}`,
+	"QS":           `"[^"]*"`,
+	"QUOTEDSTRING": `%{QS}`,
+
+	// Hosts and users.
+	"HOSTNAME":       `\b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*\.?`,
+	"HOST":           `%{HOSTNAME}`,
+	"IPORHOST":       `(?:%{IP}|%{HOSTNAME}|[0-9A-Fa-f]*:[0-9A-Fa-f:.]+)`,
+	"HOSTPORT":       `%{IPORHOST}:%{POSINT}`,
+	"USERNAME":       `[a-zA-Z0-9._-]+`,
+	"USER":           `%{USERNAME}`,
+	"EMAILLOCALPART": `[a-zA-Z0-9!#$%&'*+/=?^_{|}~-]+(?:\.[a-zA-Z0-9!#$%&'*+/=?^_{|}~-]+)*`,
+	"EMAILADDRESS":   `%{EMAILLOCALPART}@%{HOSTNAME}`,
+	"EMAIL":          `%{EMAILADDRESS}`,
+
+	// Dates and times.
+	"MONTH":             `\b(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)[a-z]*\b`,
+	"MONTHNUM":          `(?:0?[1-9]|1[0-2])`,
+	"MONTHDAY":          `(?:0[1-9]|[12][0-9]|3[01]|[1-9])`,
+	"DAY":               `(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun)[a-z]*`,
+	"YEAR":              `(?:\d\d){1,2}`,
+	"HOUR":              `(?:2[0123]|[01]?[0-9])`,
+	"MINUTE":            `[0-5][0-9]`,
+	"SECOND":            `(?:[0-5]?[0-9]|60)(?:[:.,][0-9]+)?`,
+	"TIME":              `%{HOUR}:%{MINUTE}(?::%{SECOND})?`,
+	"ISO8601_TIMEZONE":  `(?:Z|[+-]%{HOUR}(?::?%{MINUTE}))`,
+	"TIMESTAMP_ISO8601": `%{YEAR}-%{MONTHNUM}-%{MONTHDAY}[T ]%{HOUR}:?%{MINUTE}(?::?%{SECOND})?%{ISO8601_TIMEZONE}?`,
+	"HTTPDATE":          `%{MONTHDAY}/%{MONTH}/%{YEAR}:%{TIME} %{INT}`,
+	"SYSLOGTIMESTAMP":   `%{MONTH} +%{MONTHDAY} %{TIME}`,
+
+	// Syslog.
+	"PROG":       `[\x21-\x5a\x5c\x5e-\x7e]+`,
+	"SYSLOGPROG": `%{PROG:program}(?:\[%{POSINT:pid}\])?`,
+	"SYSLOGHOST": `%{IPORHOST}`,
+	"SYSLOGLINE": `%{SYSLOGTIMESTAMP:timestamp} %{SYSLOGHOST:logsource} %{SYSLOGPROG}: %{GREEDYDATA:message}`,
+
+	// Web servers.
+	"COMMONAPACHELOG":   `%{IPORHOST:clientip} %{USER:ident} %{USER:auth} \[%{HTTPDATE:timestamp}\] "(?:%{WORD:verb} %{NOTSPACE:request}(?: HTTP/%{NUMBER:httpversion})?|%{DATA:rawrequest})" %{NUMBER:response} (?:%{NUMBER:bytes}|-)`,
+	"COMBINEDAPACHELOG": `%{COMMONAPACHELOG} %{QS:referrer} %{QS:agent}`,
+	"NGINXACCESS":       `%{IPORHOST:remote_addr} - %{USER:remote_user} \[%{HTTPDATE:time_local}\] "(?:%{WORD:method} %{NOTSPACE:request}(?: HTTP/%{NUMBER:httpversion})?|%{DATA:rawrequest})" %{INT:status} %{INT:body_bytes_sent} %{QS:http_referer} %{QS:http_user_agent}`,
 }
 
 // GrokParser parses unstructured log lines using Grok-style patterns.
 // Pattern format: %{PATTERN_NAME:capture_name}
 // Example: "%{IP:client} %{WORD:method} %{NOTSPACE:path} %{STATUSCODE:status}"
 type GrokParser struct {
-	pattern    string
-	regex      *regexp.Regexp
-	fieldNames []string
+	*RegexParser
+	pattern string
 }
 
 // NewGrokParser compiles a Grok pattern string into a regex-based parser.
 func NewGrokParser(pattern string) (*GrokParser, error) {
-	regexStr, fieldNames, err := compileGrokPattern(pattern)
+	return NewGrokParserWithPatterns(pattern, nil)
+}
+
+// NewGrokParserWithPatterns is like NewGrokParser with extra pattern
+// definitions (see LoadGrokPatterns), which override built-ins of the same
+// name.
+func NewGrokParserWithPatterns(pattern string, custom map[string]string) (*GrokParser, error) {
+	defs := builtinPatterns
+	if len(custom) > 0 {
+		defs = make(map[string]string, len(builtinPatterns)+len(custom))
+		for name, def := range builtinPatterns {
+			defs[name] = def
+		}
+		for name, def := range custom {
+			defs[name] = def
+		}
+	}
+
+	regexStr, err := compileGrokPattern(pattern, defs)
 	if err != nil {
 		return nil, err
 	}
@@ -52,73 +117,105 @@ func NewGrokParser(pattern string) (*GrokParser, error) {
 	}
 
 	return &GrokParser{
-		pattern:    pattern,
-		regex:      re,
-		fieldNames: fieldNames,
+		RegexParser: newRegexParser(re),
+		pattern:     pattern,
 	}, nil
 }
 
-// Parse extracts structured fields from a log entry's message.
-// Returns true if the pattern matched and fields were extracted.
-func (g *GrokParser) Parse(e *entry.LogEntry) bool {
-	matches := g.regex.FindStringSubmatch(e.Message)
-	if matches == nil {
-		return false
-	}
+// Pattern returns the original Grok pattern string.
+func (g *GrokParser) Pattern() string {
+	return g.pattern
+}
 
-	if e.Fields == nil {
-		e.Fields = make(map[string]string, len(g.fieldNames))
+// LoadGrokPatterns reads logstash-style pattern files from dir: one
+// "NAME regex" definition per line, with blank lines and # comments ignored.
+// Files are read in name order, so a later file overrides an earlier one;
+// hidden files and subdirectories are skipped.
+func LoadGrokPatterns(dir string) (map[string]string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
 	}
 
-	for i, name := range g.fieldNames {
-		if i+1 < len(matches) && name != "" {
-			e.Fields[name] = matches[i+1]
+	defs := make(map[string]string)
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			sp := strings.IndexAny(line, " \t")
+			if sp < 0 {
+				return nil, fmt.Errorf("%s:%d: want \"NAME regex\"", path, i+1)
+			}
+			name, def := line[:sp], strings.TrimSpace(line[sp+1:])
+			if def == "" || !grokName.MatchString(name) {
+				return nil, fmt.Errorf("%s:%d: want \"NAME regex\"", path, i+1)
+			}
+			defs[name] = def
 		}
 	}
-
-	return true
+	return defs, nil
 }
 
-// Pattern returns the original Grok pattern string.
-func (g *GrokParser) Pattern() string {
-	return g.pattern
-}
+// grokName matches a valid pattern name.
+var grokName = regexp.MustCompile(`^\w+$`)
+
+// grokRef matches %{PATTERN}, %{PATTERN:field} and %{PATTERN:field:type};
+// the logstash type suffix is accepted and ignored, as fields are strings.
+var grokRef = regexp.MustCompile(`%\{(\w+)(?::(\w+))?(?::\w+)?\}`)
 
-// compileGrokPattern converts a Grok pattern to a Go regex with named groups.
+// compileGrokPattern converts a Grok pattern to a Go regex with named groups,
+// expanding references inside pattern definitions recursively.
 // %{PATTERN_NAME:field_name} → (?P<field_name>regex_for_PATTERN_NAME)
 // %{PATTERN_NAME} → (?:regex_for_PATTERN_NAME)
-func compileGrokPattern(pattern string) (string, []string, error) {
-	var fieldNames []string
-	result := pattern
-
-	// Find all %{...} tokens.
-	grokRe := regexp.MustCompile(`%\{(\w+)(?::(\w+))?\}`)
-	matches := grokRe.FindAllStringSubmatch(pattern, -1)
-
-	for _, m := range matches {
-		fullMatch := m[0]
-		patternName := m[1]
-		fieldName := ""
-		if len(m) > 2 {
-			fieldName = m[2]
+func compileGrokPattern(pattern string, defs map[string]string) (string, error) {
+	return expandGrok(pattern, defs, nil)
+}
+
+// expandGrok replaces the references in pattern. stack holds the patterns
+// being expanded, to report cycles.
+func expandGrok(pattern string, defs map[string]string, stack []string) (string, error) {
+	var err error
+	result := grokRef.ReplaceAllStringFunc(pattern, func(ref string) string {
+		if err != nil {
+			return ""
 		}
+		m := grokRef.FindStringSubmatch(ref)
+		patternName, fieldName := m[1], m[2]
 
-		builtinRegex, ok := builtinPatterns[patternName]
+		def, ok := defs[patternName]
 		if !ok {
-			return "", nil, fmt.Errorf("unknown grok pattern: %s", patternName)
+			err = fmt.Errorf("unknown grok pattern: %s", patternName)
+			return ""
+		}
+		for i, name := range stack {
+			if name == patternName {
+				err = fmt.Errorf("grok pattern cycle: %s -> %s", strings.Join(stack[i:], " -> "), patternName)
+				return ""
+			}
 		}
 
-		var replacement string
+		sub, subErr := expandGrok(def, defs, append(stack, patternName))
+		if subErr != nil {
+			err = subErr
+			return ""
+		}
 		if fieldName != "" {
-			replacement = fmt.Sprintf("(%s)", builtinRegex)
-			fieldNames = append(fieldNames, fieldName)
-		} else {
-			replacement = fmt.Sprintf("(?:%s)", builtinRegex)
-			fieldNames = append(fieldNames, "")
+			return fmt.Sprintf("(?P<%s>%s)", fieldName, sub)
 		}
-
-		result = strings.Replace(result, fullMatch, replacement, 1)
+		return fmt.Sprintf("(?:%s)", sub)
+	})
+	if err != nil {
+		return "", err
 	}
-
-	return result, fieldNames, nil
+	return result, nil
 }
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// Keys of structured (JSON, logfmt) lines mapped onto the LogEntry itself,
+// in order of preference.
+var (
+	messageKeys = []string{"msg", "message", "log"}
+	levelKeys   = []string{"level", "severity", "lvl", "log.level", "loglevel"}
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp"}
+)
+
+// JSONParser populates entry fields from messages that are JSON objects.
+// Nested objects are flattened to dotted keys ({"http":{"status":500}}
+// becomes http.status); arrays are kept as compact JSON. Common keys are
+// mapped onto the entry: msg/message becomes the message, level/severity the
+// level and time/ts/timestamp the timestamp. The original line is kept in Raw.
+type JSONParser struct{}
+
+// NewJSONParser creates a JSON parser.
+func NewJSONParser() *JSONParser {
+	return &JSONParser{}
+}
+
+// Parse decodes e.Message if it is a JSON object. Returns true if it was.
+func (p *JSONParser) Parse(e *entry.LogEntry) bool {
+	msg := strings.TrimSpace(e.Message)
+	if len(msg) < 2 || msg[0] != '{' {
+		return false
+	}
+	dec := json.NewDecoder(strings.NewReader(msg))
+	dec.UseNumber()
+	var record map[string]interface{}
+	if err := dec.Decode(&record); err != nil {
+		return false
+	}
+
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(record))
+	}
+	flattenJSON(e.Fields, "", record)
+
+	promoteKeys(e)
+	return true
+}
+
+// promoteKeys sets the entry's level, timestamp and message from the
+// well-known fields, moving the message field out of Fields. The original
+// line is kept in Raw.
+func promoteKeys(e *entry.LogEntry) {
+	for _, k := range levelKeys {
+		if v, ok := e.Fields[k]; ok {
+			if l := entry.ParseLevel(strings.ToUpper(v)); l != entry.LevelUnknown {
+				e.Level = l
+				break
+			}
+		}
+	}
+	for _, k := range timeKeys {
+		if v, ok := e.Fields[k]; ok {
+			if t, ok := parseFieldTime(v); ok {
+				e.Timestamp = t
+				break
+			}
+		}
+	}
+	for _, k := range messageKeys {
+		if v, ok := e.Fields[k]; ok && v != "" {
+			if len(e.Raw) == 0 {
+				e.Raw = []byte(e.Message)
+			}
+			e.Message = v
+			delete(e.Fields, k)
+			break
+		}
+	}
+}
+
+// flattenJSON copies v into dst as strings, joining nested object keys with
+// dots.
+func flattenJSON(dst map[string]string, prefix string, v map[string]interface{}) {
+	for k, val := range v {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch x := val.(type) {
+		case map[string]interface{}:
+			flattenJSON(dst, key, x)
+		case []interface{}:
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			_ = enc.Encode(x)
+			dst[key] = strings.TrimSuffix(buf.String(), "\n")
+		case nil:
+			dst[key] = ""
+		case string:
+			dst[key] = x
+		case json.Number:
+			dst[key] = x.String()
+		case bool:
+			dst[key] = strconv.FormatBool(x)
+		}
+	}
+}
+
+// parseFieldTime reads an RFC 3339 timestamp or a Unix epoch in seconds,
+// milliseconds or nanoseconds.
+func parseFieldTime(v string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f <= 0 {
+		return time.Time{}, false
+	}
+	switch {
+	case f >= 1e17: // nanoseconds
+		return time.Unix(0, int64(f)), true
+	case f >= 1e11: // milliseconds
+		return time.UnixMilli(int64(f)), true
+	default:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	}
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// KVParser extracts key-value pairs found anywhere in a semi-structured
+// line, such as
+//
+//	2024-01-02 10:00:01 WARN login failed user=bob src="10.0.0.1 (vpn)"
+//	audit; user: bob; action: delete; target: /etc/hosts
+//
+// sep separates a key from its value ("=" or ":") and pairSep one pair from
+// the next (" " for any whitespace, or e.g. ";" or ","). Values may be
+// double- or single-quoted, with backslash escapes. Keys start with a letter
+// or underscore, so times like 10:00:01 are not taken for pairs.
+type KVParser struct {
+	sep     string
+	pairSep string
+}
+
+// NewKVParser creates a parser; empty separators default to "=" and " ".
+func NewKVParser(sep, pairSep string) *KVParser {
+	if sep == "" {
+		sep = "="
+	}
+	if pairSep == "" {
+		pairSep = " "
+	}
+	return &KVParser{sep: sep, pairSep: pairSep}
+}
+
+// Parse adds the pairs in e.Message to its fields. Returns true if there
+// were any.
+func (p *KVParser) Parse(e *entry.LogEntry) bool {
+	s := e.Message
+	found := false
+	for i := 0; i < len(s); {
+		j := strings.Index(s[i:], p.sep)
+		if j < 0 {
+			break
+		}
+		at := i + j
+		start := at
+		for start > i && isKeyByte(s[start-1]) {
+			start--
+		}
+		if start == at || !isKeyStart(s[start]) {
+			i = at + len(p.sep)
+			continue
+		}
+		key := s[start:at]
+
+		v := at + len(p.sep)
+		for v < len(s) && (s[v] == ' ' || s[v] == '\t') {
+			v++
+		}
+		var value string
+		if v < len(s) && (s[v] == '"' || s[v] == '\'') {
+			q, end, ok := readQuoted(s, v)
+			if !ok {
+				break
+			}
+			value, i = q, end
+		} else {
+			end := p.valueEnd(s, v)
+			value, i = strings.TrimSpace(s[v:end]), end
+		}
+
+		if e.Fields == nil {
+			e.Fields = make(map[string]string)
+		}
+		e.Fields[key] = value
+		found = true
+	}
+	return found
+}
+
+// valueEnd returns where an unquoted value starting at i ends.
+func (p *KVParser) valueEnd(s string, i int) int {
+	if p.pairSep == " " {
+		for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+		return i
+	}
+	if j := strings.Index(s[i:], p.pairSep); j >= 0 {
+		return i + j
+	}
+	return len(s)
+}
+
+// readQuoted reads the string quoted at s[i] (" or '), unescaping
+// backslashes, and returns it with the index after the closing quote.
+func readQuoted(s string, i int) (string, int, bool) {
+	quote := s[i]
+	var sb strings.Builder
+	for i++; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		if c == quote {
+			return sb.String(), i + 1, true
+		}
+		sb.WriteByte(c)
+	}
+	return "", 0, false
+}
+
+func isKeyStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isKeyByte(c byte) bool {
+	return isKeyStart(c) || (c >= '0' && c <= '9') || c == '.' || c == '-'
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// LogfmtParser parses logfmt lines such as
+//
+//	time=2024-01-02T03:04:05Z level=warn msg="slow query" duration=1.2s db=users
+//
+// into fields. Values may be double-quoted with backslash escapes; a bare key
+// gets the value "true". msg, level and time map onto the entry as with
+// JSONParser.
+type LogfmtParser struct{}
+
+// NewLogfmtParser creates a logfmt parser.
+func NewLogfmtParser() *LogfmtParser {
+	return &LogfmtParser{}
+}
+
+// Parse decodes e.Message if it is logfmt, i.e. holds at least two key=value
+// pairs and fewer bare keys than that. Returns true if it was.
+func (p *LogfmtParser) Parse(e *entry.LogEntry) bool {
+	pairs, ok := scanLogfmt(e.Message)
+	if !ok {
+		return false
+	}
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(pairs)/2)
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		e.Fields[pairs[i]] = pairs[i+1]
+	}
+	promoteKeys(e)
+	return true
+}
+
+// scanLogfmt splits s into alternating keys and values.
+func scanLogfmt(s string) ([]string, bool) {
+	var pairs []string
+	withValue, bare := 0, 0
+	for i := 0; i < len(s); {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && s[i] > ' ' && s[i] != '=' && s[i] != '"' {
+			i++
+		}
+		key := s[start:i]
+		if key == "" {
+			return nil, false
+		}
+		if i >= len(s) || s[i] != '=' {
+			if i < len(s) && s[i] == '"' {
+				return nil, false
+			}
+			pairs = append(pairs, key, "true")
+			bare++
+			continue
+		}
+		i++ // '='
+		withValue++
+
+		if i < len(s) && s[i] == '"' {
+			value, end, ok := readQuoted(s, i)
+			if !ok {
+				return nil, false
+			}
+			pairs = append(pairs, key, value)
+			i = end
+			continue
+		}
+		start = i
+		for i < len(s) && s[i] != ' ' && s[i] != '\t' {
+			i++
+		}
+		pairs = append(pairs, key, s[start:i])
+	}
+	// Plain text with a couple of key=value pairs in it is not logfmt.
+	return pairs, withValue >= 2 && bare < withValue
+}
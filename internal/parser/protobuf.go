@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufDecoder decodes protobuf messages of one type, described by a
+// FileDescriptorSet such as `protoc --include_imports --descriptor_set_out`
+// writes. Fields are named as in the .proto file.
+type ProtobufDecoder struct {
+	desc protoreflect.MessageDescriptor
+	json protojson.MarshalOptions
+}
+
+// NewProtobufDecoder loads the descriptor set at path and looks up the
+// message type name (e.g. "logs.v1.LogRecord"). An empty name is allowed
+// when the set defines exactly one message.
+func NewProtobufDecoder(path, name string) (*ProtobufDecoder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read proto descriptor: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse proto descriptor %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("parse proto descriptor %s: %w", path, err)
+	}
+
+	var desc protoreflect.MessageDescriptor
+	if name != "" {
+		d, err := files.FindDescriptorByName(protoreflect.FullName(strings.TrimPrefix(name, ".")))
+		if err != nil {
+			return nil, fmt.Errorf("proto message %s: %w", name, err)
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("proto message %s: not a message type", name)
+		}
+		desc = md
+	} else {
+		var names []string
+		files.RangeFiles(func(f protoreflect.FileDescriptor) bool {
+			for i := 0; i < f.Messages().Len(); i++ {
+				desc = f.Messages().Get(i)
+				names = append(names, string(desc.FullName()))
+			}
+			return true
+		})
+		if len(names) != 1 {
+			return nil, fmt.Errorf("proto descriptor %s defines %d messages; pick one with --proto-message", path, len(names))
+		}
+	}
+	return &ProtobufDecoder{
+		desc: desc,
+		json: protojson.MarshalOptions{UseProtoNames: true},
+	}, nil
+}
+
+// Decode decodes data as the configured message type. Payloads that set
+// none of the type's fields are rejected.
+func (d *ProtobufDecoder) Decode(data []byte) (map[string]interface{}, error) {
+	msg := dynamicpb.NewMessage(d.desc)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	set := false
+	msg.Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+		set = true
+		return false
+	})
+	if !set {
+		return nil, errors.New("no known fields in protobuf payload")
+	}
+
+	b, err := d.json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(string(b)))
+	dec.UseNumber()
+	var record map[string]interface{}
+	if err := dec.Decode(&record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
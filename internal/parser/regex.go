@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// RegexParser extracts fields from log lines with the named groups of a Go
+// regex, for users who already have one:
+//
+//	(?P<client>\S+) \S+ \S+ \[[^\]]+\] "(?P<method>\w+) (?P<path>\S+)
+//
+// GrokParser compiles Grok patterns down to the same thing.
+type RegexParser struct {
+	regex      *regexp.Regexp
+	fieldNames []string
+}
+
+// NewRegexParser compiles expr, which must have at least one named group.
+func NewRegexParser(expr string) (*RegexParser, error) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parse regex: %w", err)
+	}
+	p := newRegexParser(re)
+	named := false
+	for _, name := range p.fieldNames {
+		named = named || name != ""
+	}
+	if !named {
+		return nil, fmt.Errorf("parse regex %q has no named groups, e.g. (?P<status>\\d+)", expr)
+	}
+	return p, nil
+}
+
+func newRegexParser(re *regexp.Regexp) *RegexParser {
+	return &RegexParser{regex: re, fieldNames: re.SubexpNames()}
+}
+
+// Parse extracts structured fields from a log entry's message.
+// Returns true if the pattern matched and fields were extracted.
+func (p *RegexParser) Parse(e *entry.LogEntry) bool {
+	loc := p.regex.FindStringSubmatchIndex(e.Message)
+	if loc == nil {
+		return false
+	}
+
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(p.fieldNames))
+	}
+
+	for i, name := range p.fieldNames {
+		// Skip unnamed groups and alternatives that did not take part.
+		if name == "" || loc[2*i] < 0 {
+			continue
+		}
+		e.Fields[name] = e.Message[loc[2*i]:loc[2*i+1]]
+	}
+
+	return true
+}
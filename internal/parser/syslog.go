@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+var syslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var syslogSeverities = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+// SyslogParser parses RFC 5424 and RFC 3164 (BSD) syslog lines:
+//
+//	<165>1 2024-01-02T03:04:05.678Z host app 1234 ID47 [origin ip="10.0.0.1"] message
+//	<34>Oct 11 22:14:15 host su[230]: message
+//	Oct 11 22:14:15 host sshd[4721]: message    (as in /var/log files)
+//
+// The entry gets the message, timestamp and, when a priority is present, the
+// level from the severity. Fields get facility, severity, hostname, app,
+// procid and msgid as present, and structured data as <sd-id>.<param>.
+type SyslogParser struct{}
+
+// NewSyslogParser creates a syslog parser.
+func NewSyslogParser() *SyslogParser {
+	return &SyslogParser{}
+}
+
+// Parse decodes e.Message if it is a syslog line. Returns true if it was.
+func (p *SyslogParser) Parse(e *entry.LogEntry) bool {
+	line := strings.TrimRight(e.Message, "\r\n")
+	fields := make(map[string]string, 8)
+
+	pri := -1
+	if strings.HasPrefix(line, "<") {
+		end := strings.IndexByte(line, '>')
+		if end < 2 || end > 4 {
+			return false
+		}
+		n, err := strconv.Atoi(line[1:end])
+		if err != nil || n > 191 {
+			return false
+		}
+		pri = n
+		fields["facility"] = syslogFacilities[n>>3]
+		fields["severity"] = syslogSeverities[n&7]
+		line = line[end+1:]
+	}
+
+	var (
+		ts  time.Time
+		msg string
+		ok  bool
+	)
+	if pri >= 0 && strings.HasPrefix(line, "1 ") {
+		ts, msg, ok = parse5424(line[2:], fields)
+	} else {
+		ts, msg, ok = parse3164(line, fields)
+	}
+	if !ok {
+		return false
+	}
+
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(fields))
+	}
+	for k, v := range fields {
+		e.Fields[k] = v
+	}
+	if len(e.Raw) == 0 {
+		e.Raw = []byte(e.Message)
+	}
+	e.Message = msg
+	if !ts.IsZero() {
+		e.Timestamp = ts
+	}
+	if pri >= 0 {
+		e.Level = SyslogLevel(pri & 7)
+	}
+	return true
+}
+
+// parse5424 parses what follows "<PRI>1 ":
+// TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG].
+func parse5424(line string, fields map[string]string) (time.Time, string, bool) {
+	parts := strings.SplitN(line, " ", 6)
+	if len(parts) < 6 {
+		return time.Time{}, "", false
+	}
+	var ts time.Time
+	if parts[0] != "-" {
+		t, err := time.Parse(time.RFC3339Nano, parts[0])
+		if err != nil {
+			return time.Time{}, "", false
+		}
+		ts = t
+	}
+	for i, name := range []string{"hostname", "app", "procid", "msgid"} {
+		if v := parts[i+1]; v != "-" {
+			fields[name] = v
+		}
+	}
+
+	rest := parts[5]
+	if strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	} else {
+		var ok bool
+		if rest, ok = parseStructuredData(rest, fields); !ok {
+			return time.Time{}, "", false
+		}
+	}
+	rest = strings.TrimPrefix(rest, " ")
+	return ts, strings.TrimPrefix(rest, "\ufeff"), true
+}
+
+// parseStructuredData reads [id param="value" ...] elements into fields as
+// id.param and returns the text after them.
+func parseStructuredData(s string, fields map[string]string) (string, bool) {
+	for strings.HasPrefix(s, "[") {
+		s = s[1:]
+		end := strings.IndexAny(s, " ]")
+		if end < 1 {
+			return "", false
+		}
+		id := s[:end]
+		s = s[end:]
+		for {
+			s = strings.TrimLeft(s, " ")
+			if strings.HasPrefix(s, "]") {
+				s = s[1:]
+				break
+			}
+			eq := strings.Index(s, `="`)
+			if eq < 1 {
+				return "", false
+			}
+			name := s[:eq]
+			s = s[eq+2:]
+
+			// PARAM-VALUE escapes ", \ and ] with a backslash.
+			var sb strings.Builder
+			closed := false
+			for i := 0; i < len(s); i++ {
+				c := s[i]
+				if c == '\\' && i+1 < len(s) && strings.IndexByte(`"\]`, s[i+1]) >= 0 {
+					sb.WriteByte(s[i+1])
+					i++
+					continue
+				}
+				if c == '"' {
+					s = s[i+1:]
+					closed = true
+					break
+				}
+				sb.WriteByte(c)
+			}
+			if !closed {
+				return "", false
+			}
+			fields[id+"."+name] = sb.String()
+		}
+	}
+	return s, true
+}
+
+// parse3164 parses TIMESTAMP HOSTNAME TAG: MSG, where TIMESTAMP is
+// "Mmm dd hh:mm:ss" (without a year) or RFC 3339, as written by rsyslog's
+// high-precision format. TAG is app or app[procid].
+func parse3164(line string, fields map[string]string) (time.Time, string, bool) {
+	var ts time.Time
+	if len(line) >= 16 && line[3] == ' ' && line[15] == ' ' {
+		// "Jan  2 15:04:05" pads single-digit days with a space.
+		t, err := time.ParseInLocation(time.Stamp, line[:15], time.Local)
+		if err != nil {
+			return time.Time{}, "", false
+		}
+		now := time.Now()
+		ts = t.AddDate(now.Year(), 0, 0)
+		if ts.After(now.Add(24 * time.Hour)) {
+			// December lines read in January.
+			ts = ts.AddDate(-1, 0, 0)
+		}
+		line = line[16:]
+	} else {
+		sp := strings.IndexByte(line, ' ')
+		if sp < 0 {
+			return time.Time{}, "", false
+		}
+		t, err := time.Parse(time.RFC3339Nano, line[:sp])
+		if err != nil {
+			return time.Time{}, "", false
+		}
+		ts = t
+		line = line[sp+1:]
+	}
+
+	host, rest, ok := strings.Cut(line, " ")
+	if !ok || host == "" {
+		return time.Time{}, "", false
+	}
+	fields["hostname"] = host
+
+	// The tag ends at the first colon; without one the rest is the message.
+	if colon := strings.Index(rest, ": "); colon > 0 && !strings.ContainsAny(rest[:colon], " ") {
+		tag := rest[:colon]
+		rest = rest[colon+2:]
+		if i := strings.IndexByte(tag, '['); i > 0 && strings.HasSuffix(tag, "]") {
+			fields["procid"] = tag[i+1 : len(tag)-1]
+			tag = tag[:i]
+		}
+		fields["app"] = tag
+	}
+	return ts, rest, true
+}
+
+// SyslogLevel maps a syslog severity (0=emerg … 7=debug) onto lx levels.
+func SyslogLevel(sev int) entry.Level {
+	switch {
+	case sev <= 2:
+		return entry.LevelFatal
+	case sev == 3:
+		return entry.LevelError
+	case sev == 4:
+		return entry.LevelWarn
+	case sev <= 6:
+		return entry.LevelInfo
+	default:
+		return entry.LevelDebug
+	}
+}
@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// FieldTypes converts parsed fields to typed values, stored in entry.Values
+// next to the strings in Fields, so filters compare them as numbers (a
+// duration field compares with "250ms" or 0.25) and JSON output keeps their
+// types. Values that fail to convert are left as strings only.
+type FieldTypes struct {
+	names []string
+	types map[string]string
+}
+
+// fieldTypeNames lists the supported conversions.
+var fieldTypeNames = []string{"int", "float", "duration", "bool"}
+
+// ParseFieldTypes parses "name:type" specs, as given to --types:
+// "status:int", "ratio:float", "latency:duration", "cached:bool".
+func ParseFieldTypes(specs []string) (*FieldTypes, error) {
+	t := &FieldTypes{types: make(map[string]string, len(specs))}
+	for _, spec := range specs {
+		name, typ, ok := strings.Cut(strings.TrimSpace(spec), ":")
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid field type %q (want name:type)", spec)
+		}
+		known := false
+		for _, n := range fieldTypeNames {
+			known = known || typ == n
+		}
+		if !known {
+			return nil, fmt.Errorf("invalid field type %q: want one of %s", spec, strings.Join(fieldTypeNames, ", "))
+		}
+		if _, dup := t.types[name]; !dup {
+			t.names = append(t.names, name)
+		}
+		t.types[name] = typ
+	}
+	return t, nil
+}
+
+// Convert stores the typed values of the entry's configured fields.
+func (t *FieldTypes) Convert(e *entry.LogEntry) {
+	for _, name := range t.names {
+		s, ok := e.Fields[name]
+		if !ok {
+			continue
+		}
+		s = strings.TrimSpace(s)
+
+		var v interface{}
+		var err error
+		switch t.types[name] {
+		case "int":
+			v, err = strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				// Accept "1.0" and "1e3" if they are whole numbers.
+				if f, ferr := strconv.ParseFloat(s, 64); ferr == nil && f == float64(int64(f)) {
+					v, err = int64(f), nil
+				}
+			}
+		case "float":
+			v, err = strconv.ParseFloat(s, 64)
+		case "duration":
+			v, err = time.ParseDuration(s)
+		case "bool":
+			var b bool
+			b, err = strconv.ParseBool(s)
+			if err == nil {
+				v = b
+				e.Fields[name] = strconv.FormatBool(b)
+			}
+		}
+		if err != nil {
+			continue
+		}
+		if e.Values == nil {
+			e.Values = make(map[string]interface{}, len(t.names))
+		}
+		e.Values[name] = v
+	}
+}
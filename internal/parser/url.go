@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// urlKeys are the fields holding a request URL or path, as named by the
+// access-log Grok patterns and common JSON loggers, in order of preference.
+var urlKeys = []string{"url", "uri", "request_uri", "path", "request", "http.url", "http.path"}
+
+// routeIDSegment matches path segments that identify a resource rather than
+// an endpoint: numbers, UUIDs and long hex strings (hashes, object IDs).
+var routeIDSegment = regexp.MustCompile(`^(?:\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// URLParser splits a URL field (url, uri, path or request) into path, one
+// query.<name> field per query parameter, host for absolute URLs, and route:
+// the path with IDs replaced by :id, so /users/42/orders/9 and
+// /users/7/orders/1 both group as /users/:id/orders/:id.
+type URLParser struct{}
+
+// NewURLParser creates a URL parser.
+func NewURLParser() *URLParser {
+	return &URLParser{}
+}
+
+// Parse expands the first URL field of e. Returns true if it had one.
+func (p *URLParser) Parse(e *entry.LogEntry) bool {
+	var raw string
+	for _, k := range urlKeys {
+		if v, ok := e.Fields[k]; ok && v != "" && v != "-" {
+			raw = v
+			break
+		}
+	}
+	if raw == "" {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		// Keep what precedes the query when the rest does not parse.
+		path, _, _ := strings.Cut(raw, "?")
+		u = &url.URL{Path: path}
+	}
+
+	if u.Host != "" {
+		e.Fields["host"] = u.Host
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	e.Fields["path"] = path
+	e.Fields["route"] = normalizeRoute(path)
+	for k, vs := range u.Query() {
+		e.Fields["query."+k] = strings.Join(vs, ",")
+	}
+	return true
+}
+
+// normalizeRoute replaces the numeric, UUID and hex segments of a URL path
+// with :id.
+func normalizeRoute(path string) string {
+	segs := strings.Split(path, "/")
+	for i, s := range segs {
+		if routeIDSegment.MatchString(s) {
+			segs[i] = ":id"
+		}
+	}
+	return strings.Join(segs, "/")
+}
@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// userAgentKeys are the fields holding a User-Agent header, as named by the
+// access-log Grok patterns and common JSON loggers, in order of preference.
+var userAgentKeys = []string{"user_agent", "agent", "http_user_agent", "useragent", "http.user_agent"}
+
+// uaBrowsers is checked in order: Chromium-based browsers carry "Chrome/" and
+// "Safari/" tokens too, and Chrome carries "Safari/".
+var uaBrowsers = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`\bEdg(?:e|A|iOS)?/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`\b(?:OPR|Opera)/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`\bSamsungBrowser/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`\b(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`\b(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`\bVersion/([\d.]+).*\bSafari/`)},
+	{"IE", regexp.MustCompile(`\b(?:MSIE |Trident/.*\brv:)([\d.]+)`)},
+}
+
+var uaOSes = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"Windows", regexp.MustCompile(`\bWindows NT ([\d.]+)`)},
+	{"iOS", regexp.MustCompile(`\b(?:iPhone|CPU) OS ([\d_]+)`)},
+	{"Android", regexp.MustCompile(`\bAndroid ([\d.]+)`)},
+	{"macOS", regexp.MustCompile(`\bMac OS X ([\d_.]+)`)},
+	{"ChromeOS", regexp.MustCompile(`\bCrOS \S+ ([\d.]+)`)},
+	{"Linux", regexp.MustCompile(`\bLinux\b()`)},
+}
+
+// windowsVersions maps NT kernel versions to marketing names.
+var windowsVersions = map[string]string{
+	"10.0": "10", "6.3": "8.1", "6.2": "8", "6.1": "7", "6.0": "Vista", "5.1": "XP",
+}
+
+var (
+	uaBot     = regexp.MustCompile(`(?i)\b([\w-]*(?:bot|crawler|spider|slurp))\b(?:/([\d.]+))?`)
+	uaProduct = regexp.MustCompile(`^([\w.-]+)/([\w.-]+)`)
+)
+
+// UserAgentParser splits a User-Agent field (agent, user_agent or
+// http_user_agent) into browser, browser_version, os, os_version and device
+// fields, so traffic can be filtered and counted by client. device is one of
+// desktop, mobile, tablet, bot or other (scripts and libraries such as curl).
+// Parts that cannot be recognized are left out.
+type UserAgentParser struct{}
+
+// NewUserAgentParser creates a User-Agent parser.
+func NewUserAgentParser() *UserAgentParser {
+	return &UserAgentParser{}
+}
+
+// Parse enriches e from its User-Agent field. Returns true if it had one.
+func (p *UserAgentParser) Parse(e *entry.LogEntry) bool {
+	var ua string
+	for _, k := range userAgentKeys {
+		if v, ok := e.Fields[k]; ok {
+			ua = strings.Trim(v, `"`)
+			break
+		}
+	}
+	if ua == "" || ua == "-" {
+		return false
+	}
+
+	set := func(k, v string) {
+		if v != "" {
+			e.Fields[k] = v
+		}
+	}
+
+	if m := uaBot.FindStringSubmatch(ua); m != nil {
+		set("browser", m[1])
+		set("browser_version", m[2])
+		set("device", "bot")
+		return true
+	}
+	if !strings.HasPrefix(ua, "Mozilla/") && !strings.HasPrefix(ua, "Opera/") {
+		if m := uaProduct.FindStringSubmatch(ua); m != nil {
+			set("browser", m[1])
+			set("browser_version", m[2])
+		}
+		set("device", "other")
+		return true
+	}
+
+	for _, b := range uaBrowsers {
+		if m := b.re.FindStringSubmatch(ua); m != nil {
+			set("browser", b.name)
+			set("browser_version", m[1])
+			break
+		}
+	}
+	for _, o := range uaOSes {
+		if m := o.re.FindStringSubmatch(ua); m != nil {
+			version := strings.ReplaceAll(m[1], "_", ".")
+			if o.name == "Windows" {
+				if name, ok := windowsVersions[version]; ok {
+					version = name
+				}
+			}
+			set("os", o.name)
+			set("os_version", version)
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		(strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile")):
+		set("device", "tablet")
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone"):
+		set("device", "mobile")
+	default:
+		set("device", "desktop")
+	}
+	return true
+}
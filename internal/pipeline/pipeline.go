@@ -4,26 +4,42 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
-	"github.com/Geun-Oh/lx/internal/buffer"
 	"github.com/Geun-Oh/lx/internal/entry"
 	"github.com/Geun-Oh/lx/internal/filter"
-	"github.com/Geun-Oh/lx/internal/monitor"
-	"github.com/Geun-Oh/lx/internal/parser"
 	"github.com/Geun-Oh/lx/internal/sink"
 	"github.com/Geun-Oh/lx/internal/source"
 )
 
+// Route sends entries to a sink only when its own filter chain matches them,
+// on top of the pipeline-wide Filters (e.g. everything to the terminal but
+// only ERROR/FATAL to a webhook).
+type Route struct {
+	Sink   sink.Sink
+	Filter *filter.Chain // nil passes everything
+}
+
 // Config holds pipeline configuration.
 type Config struct {
 	Source    source.Source
 	Filters   *filter.Chain
 	Sinks     []sink.Sink
+	Routes    []Route               // sinks with their own filters
+	Retry     *RetryPolicy          // optional; without it a sink error aborts Run
 	Context   *filter.ContextBuffer // optional context lines
-	Stats     *monitor.Stats
-	RingBuf   *buffer.Ring       // optional ring buffer for TUI search
-	Grok      *parser.GrokParser // optional grok parser
 	ShowStats bool
+
+	// Stages parse and enrich each line before filtering, and record the
+	// matches.
+	Stages
+
+	// MaxMatches stops the pipeline after this many matches (0 = no limit),
+	// once any --after context for the last one is written. MaxLines stops
+	// it after this many input lines. Either way the source is cancelled.
+	MaxMatches int
+	MaxLines   int
 }
 
 // Run executes the pipeline: reads from source, filters, and writes to sinks.
@@ -32,68 +48,76 @@ func Run(ctx context.Context, cfg *Config) error {
 	if cfg.Source == nil {
 		return fmt.Errorf("pipeline: source is required")
 	}
-	if len(cfg.Sinks) == 0 {
+	if len(cfg.Sinks) == 0 && len(cfg.Routes) == 0 {
 		return fmt.Errorf("pipeline: at least one sink is required")
 	}
 
+	// Cancelling stops the source when a line or match limit is reached.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	ch, err := cfg.Source.Start(ctx)
 	if err != nil {
 		return fmt.Errorf("pipeline: start source: %w", err)
 	}
+	w := newWriter(cfg)
+
+	var wg sync.WaitGroup
+	if cfg.Alerts != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			watchTimed(ctx, cfg)
+		}()
+	}
 
+	lines, matches := 0, 0
 	for e := range ch {
-		cfg.Stats.RecordLine()
-
-		// Auto-detect log level if not set.
-		if e.Level == entry.LevelUnknown {
-			e.Level = filter.DetectLevel(e.Message)
+		lines++
+		matched, err := process(cfg, w, &e)
+		if err != nil {
+			return err
 		}
-
-		// Parse structured fields via Grok (if configured).
-		if cfg.Grok != nil {
-			cfg.Grok.Parse(&e)
+		if matched {
+			matches++
 		}
 
-		// Store in ring buffer (if configured).
-		if cfg.RingBuf != nil {
-			cfg.RingBuf.Push(e)
+		if cfg.MaxLines > 0 && lines >= cfg.MaxLines {
+			break
 		}
-
-		// Context lines mode.
-		if cfg.Context != nil {
-			entries := cfg.Context.Process(&e)
-			for i := range entries {
-				cfg.Stats.RecordMatch()
-				for _, s := range cfg.Sinks {
-					if err := s.Write(&entries[i]); err != nil {
-						return fmt.Errorf("pipeline: write to %s: %w", s.Name(), err)
-					}
-				}
-			}
-			continue
+		if cfg.MaxMatches > 0 && matches >= cfg.MaxMatches &&
+			(cfg.Context == nil || cfg.Context.Pending() == 0) {
+			break
 		}
-
-		// Standard filter chain.
-		if cfg.Filters != nil && cfg.Filters.Len() > 0 {
-			if !cfg.Filters.Match(&e) {
-				continue
-			}
+	}
+	cancel()
+	wg.Wait()
+
+	// Lines an inverse context buffer was still holding back.
+	if cfg.Context != nil {
+		held := cfg.Context.Flush()
+		if cfg.MaxMatches > 0 && len(held) > cfg.MaxMatches-matches {
+			held = held[:max(cfg.MaxMatches-matches, 0)]
 		}
-
-		cfg.Stats.RecordMatch()
-
-		for _, s := range cfg.Sinks {
-			if err := s.Write(&e); err != nil {
-				return fmt.Errorf("pipeline: write to %s: %w", s.Name(), err)
-			}
+		if err := emit(cfg, w, held); err != nil {
+			return err
 		}
 	}
 
-	// Flush and close sinks.
+	// Flush and close sinks, then deliver pending notifications.
 	for _, s := range cfg.Sinks {
 		_ = s.Flush()
 		_ = s.Close()
 	}
+	for _, r := range cfg.Routes {
+		_ = r.Sink.Flush()
+		_ = r.Sink.Close()
+	}
+	if cfg.Retry != nil && cfg.Retry.DeadLetter != nil {
+		_ = cfg.Retry.DeadLetter.Flush()
+		_ = cfg.Retry.DeadLetter.Close()
+	}
+	cfg.Notify.Close()
 
 	// Print summary if requested.
 	if cfg.ShowStats {
@@ -103,3 +127,67 @@ func Run(ctx context.Context, cfg *Config) error {
 
 	return nil
 }
+
+// process runs one entry through parsing, filtering and the sinks, and
+// reports whether it matched.
+func process(cfg *Config, w *writer, e *entry.LogEntry) (bool, error) {
+	cfg.Enrich(e)
+
+	// Context lines mode.
+	if cfg.Context != nil {
+		out := cfg.Context.Process(e)
+		if cfg.Context.Rejected() {
+			cfg.Reject(e)
+		}
+		if err := emit(cfg, w, out); err != nil {
+			return false, err
+		}
+		return cfg.Context.Matched(), nil
+	}
+
+	// Standard filter chain.
+	if cfg.Filters != nil && cfg.Filters.Len() > 0 {
+		if !cfg.Filters.Match(e) {
+			cfg.Reject(e)
+			return false, nil
+		}
+	}
+
+	cfg.Release(e)
+	return true, w.write(e)
+}
+
+// emit writes entries released by the context buffer.
+func emit(cfg *Config, w *writer, entries []entry.LogEntry) error {
+	for i := range entries {
+		cfg.Release(&entries[i])
+		if err := w.write(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watchTimed checks the silence and rate alert rules and resolves the rules
+// that stopped firing every second until ctx is done, and dispatches
+// notifications for both.
+func watchTimed(ctx context.Context, cfg *Config) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			triggered, resolved := cfg.Alerts.CheckTimed(now)
+			if len(triggered) > 0 {
+				e := cfg.Alerts.TimedEntry(triggered, now)
+				cfg.Notify.Alert(triggered, &e, cfg.RingBuf)
+			}
+			if len(resolved) > 0 {
+				e := cfg.Alerts.ResolvedEntry(resolved, now)
+				cfg.Notify.Resolve(resolved, &e)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/sink"
+)
+
+// RetryPolicy makes sink writes resilient: a failed Write is retried with
+// exponential backoff, and an entry that still cannot be written is spooled
+// to DeadLetter (if set) instead of aborting the pipeline. Once a sink has
+// exhausted its retries, further failures within the cooldown skip straight
+// to the dead letter so one dead endpoint does not stall every line. The
+// backoff sleeps in the processing loop, pausing every source meanwhile, so
+// MaxRetries should stay 0 unless brief sink hiccups are expected.
+type RetryPolicy struct {
+	MaxRetries int           // retries per entry (0 = spool on first failure)
+	Backoff    time.Duration // first retry delay, doubled per attempt (default 100ms)
+	MaxBackoff time.Duration // cap on the delay (default 5s)
+	Cooldown   time.Duration // how long a failed sink skips retries (default 30s)
+	DeadLetter sink.Sink     // receives undeliverable entries; nil drops them
+	OnError    func(error)   // told about each dropped or spooled entry's first failure per cooldown
+}
+
+// writer delivers entries to sinks, applying the retry policy.
+type writer struct {
+	cfg     *Config
+	failing map[sink.Sink]time.Time // sinks in cooldown, until when
+}
+
+func newWriter(cfg *Config) *writer {
+	w := &writer{cfg: cfg, failing: make(map[sink.Sink]time.Time)}
+	if p := cfg.Retry; p != nil {
+		if p.Backoff <= 0 {
+			p.Backoff = 100 * time.Millisecond
+		}
+		if p.MaxBackoff <= 0 {
+			p.MaxBackoff = 5 * time.Second
+		}
+		if p.Cooldown <= 0 {
+			p.Cooldown = 30 * time.Second
+		}
+	}
+	return w
+}
+
+// write hands a matched entry to every sink and to each route whose filter
+// accepts it.
+func (w *writer) write(e *entry.LogEntry) error {
+	for _, s := range w.cfg.Sinks {
+		if err := w.writeSink(s, e); err != nil {
+			return err
+		}
+	}
+	for _, r := range w.cfg.Routes {
+		if r.Filter != nil && !r.Filter.Match(e) {
+			continue
+		}
+		if err := w.writeSink(r.Sink, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSink writes e to s. Without a retry policy the first error is
+// returned; with one, errors are retried and then spooled, never returned.
+func (w *writer) writeSink(s sink.Sink, e *entry.LogEntry) error {
+	err := s.Write(e)
+	if err == nil {
+		return nil
+	}
+	p := w.cfg.Retry
+	if p == nil {
+		return fmt.Errorf("pipeline: write to %s: %w", s.Name(), err)
+	}
+
+	now := time.Now()
+	if until, ok := w.failing[s]; !ok || now.After(until) {
+		delay := p.Backoff
+		for attempt := 0; attempt < p.MaxRetries; attempt++ {
+			time.Sleep(delay)
+			if err = s.Write(e); err == nil {
+				delete(w.failing, s)
+				return nil
+			}
+			delay = min(delay*2, p.MaxBackoff)
+		}
+		w.failing[s] = time.Now().Add(p.Cooldown)
+		if p.OnError != nil {
+			action := "dropping entries"
+			if p.DeadLetter != nil {
+				action = "spooling entries to " + p.DeadLetter.Name()
+			}
+			p.OnError(fmt.Errorf("write to %s failed after %d retries, %s for %s: %w",
+				s.Name(), p.MaxRetries, action, p.Cooldown, err))
+		}
+	}
+
+	if p.DeadLetter != nil {
+		dead := *e
+		dead.Fields = make(map[string]string, len(e.Fields)+2)
+		for k, v := range e.Fields {
+			dead.Fields[k] = v
+		}
+		dead.Fields["dead_letter_sink"] = s.Name()
+		dead.Fields["dead_letter_error"] = err.Error()
+		if derr := p.DeadLetter.Write(&dead); derr != nil {
+			return fmt.Errorf("pipeline: write to dead letter %s: %w", p.DeadLetter.Name(), derr)
+		}
+	}
+	return nil
+}
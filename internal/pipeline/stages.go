@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Geun-Oh/lx/internal/buffer"
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/filter"
+	"github.com/Geun-Oh/lx/internal/monitor"
+	"github.com/Geun-Oh/lx/internal/notify"
+	"github.com/Geun-Oh/lx/internal/parser"
+	"github.com/Geun-Oh/lx/internal/sink"
+)
+
+// Stages are the steps around filtering that every line goes through, both
+// in Run and in the TUI: Enrich decodes, parses and records a line before
+// the filters see it, and Release records and checks a line they let
+// through. Nil stages are skipped.
+type Stages struct {
+	Stats     *monitor.Stats
+	RingBuf   *buffer.Ring            // optional ring buffer for TUI search
+	Persist   sink.Sink               // optional store of every buffered line
+	Grok      *parser.GrokParser      // optional grok parser
+	Regex     *parser.RegexParser     // optional named-group regex parser
+	KV        *parser.KVParser        // optional key-value pair extraction
+	Agent     *parser.UserAgentParser // optional User-Agent enrichment
+	GeoIP     *parser.GeoIP           // optional IP location lookup
+	URL       *parser.URLParser       // optional URL and query expansion
+	Types     *parser.FieldTypes      // optional typed field conversion
+	JSON      *parser.JSONParser      // optional JSON field parsing
+	Syslog    *parser.SyslogParser    // optional syslog header parsing
+	GELF      *parser.GELFParser      // optional GELF decoding
+	Decode    *parser.BinaryParser    // optional binary payload decoding
+	Format    parser.Parser           // optional --parse format (or auto-detection)
+	Alerts    *monitor.AlertEngine    // optional alert rules
+	Notify    *notify.Dispatcher      // optional alert notifications
+	Templates *monitor.Drain          // optional template mining
+
+	// TruncateAt cuts messages longer than this many bytes before they are
+	// stored or written, appending a marker with the number of bytes cut
+	// (0 = off). Filters still see the whole message.
+	TruncateAt int
+
+	// AlertNewTemplates dispatches templates first seen after the learning
+	// period as alerts, besides reporting them through OnTemplate.
+	AlertNewTemplates bool
+
+	// OnTemplate is told about each template first seen after the learning
+	// period; by default it is reported on stderr.
+	OnTemplate func(template string)
+
+	// Sanitize strips ANSI escapes and control characters from each message
+	// before it is parsed; KeepRaw leaves the original bytes in Raw.
+	Sanitize bool
+	KeepRaw  bool
+}
+
+// Enrich runs a line through everything before the filters: payload
+// decoding, sanitizing, header and format parsing, level detection, the
+// stats, alert and template observers, field extraction, and the ring
+// buffer.
+func (s *Stages) Enrich(e *entry.LogEntry) {
+	s.Stats.RecordLine()
+
+	if s.Decode != nil {
+		s.Decode.Parse(e)
+	}
+	if s.Sanitize {
+		e.Sanitize(s.KeepRaw)
+	}
+
+	// Split off syslog headers, then decode GELF, JSON or the --parse
+	// format (if configured).
+	if s.Syslog != nil {
+		s.Syslog.Parse(e)
+	}
+	if s.GELF != nil {
+		s.GELF.Parse(e)
+	}
+	if s.JSON != nil {
+		s.JSON.Parse(e)
+	}
+	if s.Format != nil {
+		s.Format.Parse(e)
+	}
+
+	// Auto-detect log level if not set.
+	if e.Level == entry.LevelUnknown {
+		e.Level = filter.DetectLevel(e.Message)
+	}
+	s.Stats.RecordEntry(e)
+	if s.Alerts != nil {
+		s.Alerts.Observe(e)
+	}
+	s.Notify.Observe(e)
+	if s.Templates != nil {
+		if tmpl, novel := s.Templates.Add(e.Message); novel {
+			if s.OnTemplate != nil {
+				s.OnTemplate(tmpl)
+			} else {
+				fmt.Fprintf(os.Stderr, "lx: new template: %s\n", tmpl)
+			}
+			if s.AlertNewTemplates {
+				s.Notify.Alert([]string{monitor.NewTemplateRule}, e, s.RingBuf)
+			}
+		}
+	}
+
+	// Parse structured fields via Grok, a regex or key-value pairs (if
+	// configured).
+	if s.Grok != nil {
+		s.Grok.Parse(e)
+	}
+	if s.Regex != nil {
+		s.Regex.Parse(e)
+	}
+	if s.KV != nil {
+		s.KV.Parse(e)
+	}
+	if s.Agent != nil {
+		s.Agent.Parse(e)
+	}
+	if s.GeoIP != nil {
+		s.GeoIP.Parse(e)
+	}
+	if s.URL != nil {
+		s.URL.Parse(e)
+	}
+	if s.Types != nil {
+		s.Types.Convert(e)
+	}
+
+	// Store in ring buffer (if configured).
+	if s.RingBuf != nil {
+		stored := *e
+		stored.Truncate(s.TruncateAt)
+		s.RingBuf.Push(stored)
+		if s.Persist != nil {
+			_ = s.Persist.Write(&stored) // a full disk must not stop the pipeline
+		}
+	}
+}
+
+// Reject records a line the filters rejected.
+func (s *Stages) Reject(e *entry.LogEntry) {
+	s.Stats.RecordFiltered(e)
+}
+
+// Release handles a line the filters (or the context buffer) let through:
+// it truncates the message, records the match and checks the alert rules,
+// dispatching notifications. Returns the rules that triggered.
+func (s *Stages) Release(e *entry.LogEntry) []string {
+	e.Truncate(s.TruncateAt)
+	s.Stats.RecordMatch()
+	s.Stats.RecordMatchFields(e)
+	if s.Alerts == nil {
+		return nil
+	}
+	triggered := s.Alerts.Check(e)
+	if len(triggered) > 0 {
+		s.Notify.Alert(triggered, e, s.RingBuf)
+	}
+	return triggered
+}
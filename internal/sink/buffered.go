@@ -0,0 +1,136 @@
+package sink
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// BufferedSink queues entries in memory and hands them to an inner sink in
+// batches, once size entries are pending or interval has passed, followed by
+// a Flush of the inner sink. Paired with a sink writing to a bufio.Writer,
+// this turns one write syscall per line into one per batch.
+type BufferedSink struct {
+	inner    Sink
+	size     int
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending []entry.LogEntry
+	err     error // first asynchronous write error, returned by the next call
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// flusher is implemented by buffered writers (bufio.Writer).
+type flusher interface {
+	Flush() error
+}
+
+// flushWriter flushes w if it buffers.
+func flushWriter(w interface{}) error {
+	if f, ok := w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// NewBufferedSink wraps inner. size is the batch size in entries; interval
+// bounds how long an entry may wait (default 200ms).
+func NewBufferedSink(inner Sink, size int, interval time.Duration) *BufferedSink {
+	if size < 1 {
+		size = 1
+	}
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+	s := &BufferedSink{
+		inner:    inner,
+		size:     size,
+		interval: interval,
+		pending:  make([]entry.LogEntry, 0, size),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Write queues a copy of e, writing the batch out when it is full.
+func (s *BufferedSink) Write(e *entry.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return err
+	}
+	s.pending = append(s.pending, *e)
+	if len(s.pending) >= s.size {
+		return s.drain()
+	}
+	return nil
+}
+
+// drain writes pending entries to the inner sink and flushes it. s.mu must be held.
+func (s *BufferedSink) drain() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	defer func() { s.pending = s.pending[:0] }()
+	for i := range s.pending {
+		if err := s.inner.Write(&s.pending[i]); err != nil {
+			return err
+		}
+	}
+	return s.inner.Flush()
+}
+
+// run drains on the interval so slow streams are not held back.
+func (s *BufferedSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if err := s.drain(); err != nil && s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Flush writes out pending entries.
+func (s *BufferedSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.drain()
+}
+
+// Close stops the timer, writes out pending entries and closes the inner sink.
+func (s *BufferedSink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+		if err = s.Flush(); err != nil {
+			s.inner.Close()
+			return
+		}
+		err = s.inner.Close()
+	})
+	return err
+}
+
+// Name returns the inner sink's identifier.
+func (s *BufferedSink) Name() string {
+	return s.inner.Name()
+}
@@ -0,0 +1,53 @@
+package sink
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// Export writes entries to a new file in dir (the current directory if
+// empty) named after the time, e.g. lx-20261016-145230.jsonl for format
+// "json", or .log for "text" and templates, and returns its path. It is
+// how a snapshot of the ring buffer is saved during an incident.
+func Export(dir, format string, entries []entry.LogEntry) (string, error) {
+	ext := ".log"
+	if format == "json" {
+		ext = ".jsonl"
+	}
+	base := filepath.Join(dir, "lx-"+time.Now().Format(rotatedTimeFormat))
+	path := base + ext
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	for i := 1; os.IsExist(err); i++ {
+		path = fmt.Sprintf("%s.%d%s", base, i, ext)
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	}
+	if err != nil {
+		return "", fmt.Errorf("export: %w", err)
+	}
+
+	w := bufio.NewWriter(f)
+	s, err := newFormatSink(w, format)
+	if err == nil {
+		for i := range entries {
+			if err = s.Write(&entries[i]); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("export %s: %w", path, err)
+	}
+	return path, nil
+}
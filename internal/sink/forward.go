@@ -0,0 +1,310 @@
+package sink
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/msgpack"
+)
+
+// ForwardOptions configures a ForwardSink.
+type ForwardOptions struct {
+	Addr          string        // fluentd / fluent-bit forward input, host:port
+	TagPrefix     string        // tags are <prefix>.<source>, default "lx"
+	BatchSize     int           // entries per Forward-mode message
+	FlushInterval time.Duration // send a partial batch after this long
+	Timeout       time.Duration // dial, write and ack timeout
+	RequireAck    bool          // request and wait for chunk acknowledgements
+	MaxRetries    int           // resend attempts before a batch is dropped
+	OnError       func(error)   // called when a batch is dropped after retries
+}
+
+// ForwardSink hands entries to a fluentd / fluent-bit aggregator using the
+// forward protocol. Entries are batched per tag into Forward mode messages
+// ([tag, [[time, record], ...], option]) with EventTime timestamps; the tag is
+// derived from the entry's source, e.g. "lx.docker.api". The connection is
+// re-established on failure, and with RequireAck each chunk is resent until
+// the server acknowledges it.
+type ForwardSink struct {
+	opts ForwardOptions
+	conn net.Conn
+
+	queue chan *entry.LogEntry
+	flush chan chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewForwardSink validates opts and starts the sender. The first connection
+// is made lazily so lx starts even if the aggregator is briefly down.
+func NewForwardSink(opts ForwardOptions) (*ForwardSink, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("forward: address is required")
+	}
+	if opts.TagPrefix == "" {
+		opts.TagPrefix = "lx"
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	s := &ForwardSink{
+		opts:  opts,
+		queue: make(chan *entry.LogEntry, 1024),
+		flush: make(chan chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Write queues an entry for delivery.
+func (s *ForwardSink) Write(e *entry.LogEntry) error {
+	c := *e
+	s.queue <- &c
+	return nil
+}
+
+// Flush sends queued entries and waits for them to be written (and acked).
+func (s *ForwardSink) Flush() error {
+	ack := make(chan struct{})
+	select {
+	case s.flush <- ack:
+		<-ack
+	case <-s.done:
+	}
+	return nil
+}
+
+// Close flushes and disconnects.
+func (s *ForwardSink) Close() error {
+	s.once.Do(func() {
+		close(s.queue)
+		<-s.done
+	})
+	return nil
+}
+
+// Name returns the sink identifier.
+func (s *ForwardSink) Name() string {
+	return "forward:" + s.opts.Addr
+}
+
+// run batches queued entries by size and interval.
+func (s *ForwardSink) run() {
+	defer close(s.done)
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+
+	batch := make([]*entry.LogEntry, 0, s.opts.BatchSize)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.deliver(batch); err != nil && s.opts.OnError != nil {
+			s.opts.OnError(err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case e, ok := <-s.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= s.opts.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-s.flush:
+			for n := len(s.queue); n > 0; n-- {
+				batch = append(batch, <-s.queue)
+				if len(batch) >= s.opts.BatchSize {
+					send()
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// deliver sends one Forward message per tag in the batch.
+func (s *ForwardSink) deliver(batch []*entry.LogEntry) error {
+	var tags []string
+	byTag := make(map[string][]*entry.LogEntry)
+	for _, e := range batch {
+		tag := forwardTag(s.opts.TagPrefix, e.Source)
+		if _, ok := byTag[tag]; !ok {
+			tags = append(tags, tag)
+		}
+		byTag[tag] = append(byTag[tag], e)
+	}
+
+	for _, tag := range tags {
+		entries := byTag[tag]
+		msg, chunk, err := s.encode(tag, entries)
+		if err != nil {
+			return fmt.Errorf("forward: encode: %w", err)
+		}
+		if err := s.sendWithRetry(msg, chunk); err != nil {
+			return fmt.Errorf("forward: dropped %d entries: %w", len(entries), err)
+		}
+	}
+	return nil
+}
+
+// sendWithRetry writes msg, reconnecting and retrying with backoff.
+func (s *ForwardSink) sendWithRetry(msg []byte, chunk string) error {
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		err := s.send(msg, chunk)
+		if err == nil {
+			return nil
+		}
+		if s.conn != nil {
+			s.conn.Close()
+			s.conn = nil
+		}
+		if attempt >= s.opts.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// send writes one message on the current connection, dialing if needed,
+// and waits for its ack when one was requested.
+func (s *ForwardSink) send(msg []byte, chunk string) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.opts.Addr, s.opts.Timeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+
+	_ = s.conn.SetDeadline(time.Now().Add(s.opts.Timeout))
+	if _, err := s.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	v, err := msgpack.NewDecoder(s.conn).Decode()
+	if err != nil {
+		return fmt.Errorf("waiting for ack: %w", err)
+	}
+	resp, _ := v.(map[string]interface{})
+	if got, _ := resp["ack"].(string); got != chunk {
+		return fmt.Errorf("unexpected ack %v", v)
+	}
+	return nil
+}
+
+// encode builds a Forward mode message for entries sharing a tag.
+func (s *ForwardSink) encode(tag string, entries []*entry.LogEntry) ([]byte, string, error) {
+	b := msgpack.AppendArrayHeader(nil, 3)
+	b = msgpack.AppendString(b, tag)
+	b = msgpack.AppendArrayHeader(b, len(entries))
+	for _, e := range entries {
+		b = msgpack.AppendArrayHeader(b, 2)
+		b = msgpack.AppendExt(b, 0, eventTime(e.Timestamp))
+		var err error
+		if b, err = msgpack.Append(b, forwardRecord(e)); err != nil {
+			return nil, "", err
+		}
+	}
+
+	option := map[string]string{"size": fmt.Sprint(len(entries))}
+	var chunk string
+	if s.opts.RequireAck {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		chunk = base64.StdEncoding.EncodeToString(id)
+		option["chunk"] = chunk
+	}
+	b, err := msgpack.Append(b, option)
+	return b, chunk, err
+}
+
+// forwardRecord flattens an entry into a fluent record. Fields are copied to
+// the top level without overriding the core keys.
+func forwardRecord(e *entry.LogEntry) map[string]string {
+	record := make(map[string]string, len(e.Fields)+4)
+	for k, v := range e.Fields {
+		record[k] = v
+	}
+	record["message"] = e.Message
+	record["stream"] = e.Stream
+	if e.Source != "" {
+		record["source"] = e.Source
+	}
+	if e.Level != entry.LevelUnknown {
+		record["level"] = e.Level.String()
+	}
+	return record
+}
+
+// eventTime encodes t as the forward protocol EventTime extension payload.
+func eventTime(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(t.Nanosecond()))
+	return b
+}
+
+// forwardTag derives a fluent tag from a source name:
+// "docker:api" → "lx.docker.api", "file:/var/log/app.log" → "lx.file.var.log.app_log".
+func forwardTag(prefix, source string) string {
+	if source == "" {
+		return prefix
+	}
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	dot := true
+	for _, r := range source {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			if dot {
+				sb.WriteByte('.')
+				dot = false
+			}
+			sb.WriteRune(r)
+		case r == ':' || r == '/':
+			dot = true
+		default:
+			if dot {
+				sb.WriteByte('.')
+				dot = false
+			}
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
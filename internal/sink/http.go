@@ -0,0 +1,270 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// HTTPOptions configures an HTTPSink.
+type HTTPOptions struct {
+	URL           string
+	Method        string            // default POST
+	Headers       map[string]string // extra request headers
+	Format        string            // "ndjson" (default) or "array" for batches
+	Template      string            // optional text/template rendering each entry, as for --format templates
+	BatchSize     int               // entries per request (1 = one entry per request)
+	FlushInterval time.Duration     // send a partial batch after this long
+	Timeout       time.Duration     // per-request timeout
+	MaxRetries    int               // retries on network errors, 429 and 5xx
+	OnError       func(error)       // called when a batch is dropped after retries
+
+	// Sinks built on HTTPSink (e.g. ClickHouse) override the name used in
+	// Name and error messages, and the batch encoding.
+	name   string
+	encode func([]*entry.LogEntry) ([]byte, string, error)
+}
+
+// HTTPSink POSTs entries to a webhook URL, alone or in batches, as JSON (the
+// same shape as the JSON sink) or as rendered by a template. Requests are sent
+// from a background worker so a slow endpoint does not stall the pipeline
+// until its queue fills; failed requests are retried with exponential backoff.
+type HTTPSink struct {
+	opts   HTTPOptions
+	tmpl   *template.Template
+	client *http.Client
+
+	queue chan *entry.LogEntry
+	flush chan chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// templateFuncs are available to webhook templates.
+var templateFuncs = template.FuncMap{
+	// json renders a value as a JSON literal, e.g. {"text": {{json .Message}}}.
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewHTTPSink validates opts and starts the sender.
+func NewHTTPSink(opts HTTPOptions) (*HTTPSink, error) {
+	if opts.name == "" {
+		opts.name = "webhook"
+	}
+	if opts.URL == "" {
+		return nil, fmt.Errorf("%s: URL is required", opts.name)
+	}
+	if opts.Method == "" {
+		opts.Method = http.MethodPost
+	}
+	if opts.Format == "" {
+		opts.Format = "ndjson"
+	}
+	if opts.Format != "ndjson" && opts.Format != "array" {
+		return nil, fmt.Errorf("%s: unknown format %q (want ndjson or array)", opts.name, opts.Format)
+	}
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 2 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	s := &HTTPSink{
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		queue:  make(chan *entry.LogEntry, 1024),
+		flush:  make(chan chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if opts.Template != "" {
+		t, err := template.New("webhook").Funcs(templateFuncs).Option("missingkey=zero").Parse(opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("%s: template: %w", opts.name, err)
+		}
+		s.tmpl = t
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// Write queues an entry for delivery.
+func (s *HTTPSink) Write(e *entry.LogEntry) error {
+	c := *e
+	s.queue <- &c
+	return nil
+}
+
+// Flush sends any queued entries and waits for the requests to finish.
+func (s *HTTPSink) Flush() error {
+	ack := make(chan struct{})
+	select {
+	case s.flush <- ack:
+		<-ack
+	case <-s.done:
+	}
+	return nil
+}
+
+// Close flushes and stops the sender.
+func (s *HTTPSink) Close() error {
+	s.once.Do(func() {
+		close(s.queue)
+		<-s.done
+	})
+	return nil
+}
+
+// Name returns the sink identifier.
+func (s *HTTPSink) Name() string {
+	return s.opts.name + ":" + s.opts.URL
+}
+
+// run batches queued entries by size and interval.
+func (s *HTTPSink) run() {
+	defer close(s.done)
+
+	batch := make([]*entry.LogEntry, 0, s.opts.BatchSize)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.deliver(batch); err != nil && s.opts.OnError != nil {
+			s.opts.OnError(err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case e, ok := <-s.queue:
+			if !ok {
+				send()
+				return
+			}
+			batch = append(batch, e)
+			if len(batch) >= s.opts.BatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case ack := <-s.flush:
+			// Drain whatever was queued before the flush request.
+			for n := len(s.queue); n > 0; n-- {
+				batch = append(batch, <-s.queue)
+				if len(batch) >= s.opts.BatchSize {
+					send()
+				}
+			}
+			send()
+			close(ack)
+		}
+	}
+}
+
+// deliver encodes a batch and sends it, retrying transient failures.
+func (s *HTTPSink) deliver(batch []*entry.LogEntry) error {
+	encode := s.encode
+	if s.opts.encode != nil {
+		encode = s.opts.encode
+	}
+	body, contentType, err := encode(batch)
+	if err != nil {
+		return fmt.Errorf("%s: encode: %w", s.opts.name, err)
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body, contentType)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.opts.MaxRetries {
+			return fmt.Errorf("%s: dropped %d entries: %w", s.opts.name, len(batch), err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends one request. retry reports whether the failure is transient.
+func (s *HTTPSink) post(body []byte, contentType string) (retry bool, err error) {
+	req, err := http.NewRequest(s.opts.Method, s.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "lx")
+	for k, v := range s.opts.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// encode renders a batch. A single entry is sent as one JSON object (or
+// template output); larger batches as NDJSON or a JSON array.
+func (s *HTTPSink) encode(batch []*entry.LogEntry) ([]byte, string, error) {
+	records := make([][]byte, 0, len(batch))
+	for _, e := range batch {
+		var b []byte
+		if s.tmpl != nil {
+			var buf bytes.Buffer
+			if err := s.tmpl.Execute(&buf, newTemplateEntry(e)); err != nil {
+				return nil, "", err
+			}
+			b = bytes.TrimSpace(buf.Bytes())
+		} else {
+			var err error
+			if b, err = json.Marshal(toJSONEntry(e)); err != nil {
+				return nil, "", err
+			}
+		}
+		records = append(records, b)
+	}
+
+	contentType := "application/json"
+	if s.tmpl != nil && !json.Valid(records[0]) {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	if s.opts.BatchSize == 1 {
+		return records[0], contentType, nil
+	}
+	if s.opts.Format == "array" {
+		return append(append([]byte{'['}, bytes.Join(records, []byte{','})...), ']'), contentType, nil
+	}
+	if contentType == "application/json" {
+		contentType = "application/x-ndjson"
+	}
+	return append(bytes.Join(records, []byte{'\n'}), '\n'), contentType, nil
+}
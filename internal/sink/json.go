@@ -5,18 +5,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/Geun-Oh/lx/internal/entry"
 )
 
 // jsonEntry is the serialization format for JSON Lines output.
 type jsonEntry struct {
-	Timestamp string            `json:"timestamp"`
-	Stream    string            `json:"stream"`
-	Level     string            `json:"level,omitempty"`
-	Source    string            `json:"source,omitempty"`
-	Message   string            `json:"message"`
-	Fields    map[string]string `json:"fields,omitempty"`
+	Timestamp string      `json:"timestamp"`
+	Stream    string      `json:"stream"`
+	Level     string      `json:"level,omitempty"`
+	Source    string      `json:"source,omitempty"`
+	Message   string      `json:"message"`
+	Fields    interface{} `json:"fields,omitempty"` // map[string]string, or with typed values
 }
 
 // JSONSink writes log entries as JSON Lines (one JSON object per line).
@@ -38,6 +39,11 @@ func NewJSONSink(w io.Writer) *JSONSink {
 
 // Write serializes a log entry as a single JSON line.
 func (s *JSONSink) Write(e *entry.LogEntry) error {
+	return s.enc.Encode(toJSONEntry(e))
+}
+
+// toJSONEntry converts a log entry to its JSON Lines representation.
+func toJSONEntry(e *entry.LogEntry) jsonEntry {
 	je := jsonEntry{
 		Timestamp: e.Timestamp.Format("2006-01-02T15:04:05.000Z07:00"),
 		Stream:    e.Stream,
@@ -47,14 +53,32 @@ func (s *JSONSink) Write(e *entry.LogEntry) error {
 	if e.Level != entry.LevelUnknown {
 		je.Level = e.Level.String()
 	}
-	if len(e.Fields) > 0 {
+	if len(e.Values) > 0 {
+		je.Fields = typedFields(e)
+	} else if len(e.Fields) > 0 {
 		je.Fields = e.Fields
 	}
-	return s.enc.Encode(je)
+	return je
+}
+
+// typedFields merges Fields with the typed Values from --types; durations
+// are written as seconds.
+func typedFields(e *entry.LogEntry) map[string]interface{} {
+	fields := make(map[string]interface{}, len(e.Fields))
+	for k, v := range e.Fields {
+		fields[k] = v
+	}
+	for k, v := range e.Values {
+		if d, ok := v.(time.Duration); ok {
+			v = d.Seconds()
+		}
+		fields[k] = v
+	}
+	return fields
 }
 
-// Flush is a no-op for JSON sink.
-func (s *JSONSink) Flush() error { return nil }
+// Flush flushes the writer if it is buffered.
+func (s *JSONSink) Flush() error { return flushWriter(s.w) }
 
 // Close is a no-op for JSON sink.
 func (s *JSONSink) Close() error { return nil }
@@ -62,29 +86,81 @@ func (s *JSONSink) Close() error { return nil }
 // Name returns the sink identifier.
 func (s *JSONSink) Name() string { return "json" }
 
-// FileSink writes log entries to a file.
+// newFormatSink returns the uncolored formatter for format ("json", "text" or
+// a template) writing to w.
+func newFormatSink(w io.Writer, format string) (Sink, error) {
+	switch {
+	case format == "json":
+		return NewJSONSink(w), nil
+	case IsTemplateFormat(format):
+		return NewTemplateSink(w, format)
+	default:
+		return NewTerminalSink(w, false, false), nil
+	}
+}
+
+// compressedFlushInterval bounds how much compressed output a crash can lose.
+const compressedFlushInterval = time.Second
+
+// FileSink writes log entries to a file, optionally rotating and compressing it.
 type FileSink struct {
 	inner Sink
-	file  *os.File
+	file  *rotatingFile
+	stop  chan struct{}
+	done  chan struct{}
 }
 
 // NewFileSink creates a sink that writes to the given file path.
-// The format parameter selects the inner formatter: "json" or "text" (default).
-func NewFileSink(path string, format string) (*FileSink, error) {
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+// The format parameter selects the inner formatter: "json", "text" (default)
+// or a text/template (see TemplateSink).
+// rot controls size/age based rotation; its zero value never rotates.
+func NewFileSink(path string, format string, rot Rotation) (*FileSink, error) {
+	return NewCompressedFileSink(path, format, "", rot)
+}
+
+// NewCompressedFileSink is like NewFileSink but compresses the output with
+// codec ("gzip" or "zstd"; "" writes plain text). Compressed data is flushed
+// every second so a crash loses at most the last moment of output.
+func NewCompressedFileSink(path, format, codec string, rot Rotation) (*FileSink, error) {
+	switch codec {
+	case "", "gzip", "zstd":
+	default:
+		return nil, fmt.Errorf("unknown compression %q (want gzip or zstd)", codec)
+	}
+
+	f, err := openRotatingFile(path, rot, codec)
 	if err != nil {
-		return nil, fmt.Errorf("open output file %s: %w", path, err)
+		return nil, err
 	}
 
-	var inner Sink
-	switch format {
-	case "json":
-		inner = NewJSONSink(f)
-	default:
-		inner = NewTerminalSink(f, false)
+	inner, err := newFormatSink(f, format)
+	if err != nil {
+		f.Close()
+		return nil, err
 	}
 
-	return &FileSink{inner: inner, file: f}, nil
+	s := &FileSink{inner: inner, file: f}
+	if codec != "" {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.flushLoop()
+	}
+	return s, nil
+}
+
+// flushLoop periodically flushes the compressor until Close.
+func (s *FileSink) flushLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(compressedFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_ = s.file.flush()
+		}
+	}
 }
 
 // Write delegates to the inner sink.
@@ -99,6 +175,10 @@ func (s *FileSink) Flush() error {
 
 // Close flushes and closes the file.
 func (s *FileSink) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
 	if err := s.Flush(); err != nil {
 		return err
 	}
@@ -107,5 +187,5 @@ func (s *FileSink) Close() error {
 
 // Name returns the sink identifier.
 func (s *FileSink) Name() string {
-	return "file:" + s.file.Name()
+	return "file:" + s.file.path
 }
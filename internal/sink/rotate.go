@@ -0,0 +1,300 @@
+package sink
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Rotation configures when a FileSink starts a new file. The zero value
+// disables rotation.
+type Rotation struct {
+	MaxSize    int64         // rotate before a write would exceed this many bytes (0 = no limit)
+	MaxAge     time.Duration // rotate once the current file is this old (0 = no limit)
+	MaxBackups int           // rotated files to keep (0 = keep all)
+	Compress   bool          // gzip rotated files
+}
+
+// enabled reports whether any rotation trigger is set.
+func (r Rotation) enabled() bool {
+	return r.MaxSize > 0 || r.MaxAge > 0
+}
+
+// rotatedTimeFormat is the suffix appended to rotated file names.
+const rotatedTimeFormat = "20060102-150405"
+
+// encoder is a streaming compressor (gzip.Writer, zstd.Encoder).
+type encoder interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// rotatingFile is an io.Writer over a log file that renames it aside and
+// starts a fresh one when the size or age limit is reached. Sinks write whole
+// lines per call, so files are always split on line boundaries.
+//
+// With a codec set, data is compressed as it is written and each file is a
+// complete gzip/zstd stream; appending to an existing file adds a new member,
+// which both formats (and lx's own readers) accept.
+type rotatingFile struct {
+	mu     sync.Mutex
+	path   string
+	rot    Rotation
+	codec  string
+	f      *os.File
+	w      io.Writer // f, or enc writing to f
+	enc    encoder
+	size   int64 // bytes in f, counted after compression
+	opened time.Time
+	wg     sync.WaitGroup // pending background compressions
+	bgMu   sync.Mutex     // serializes compression and pruning
+}
+
+// openRotatingFile opens (appending to) path, compressing with codec
+// ("gzip", "zstd" or "" for none).
+func openRotatingFile(path string, rot Rotation, codec string) (*rotatingFile, error) {
+	rf := &rotatingFile{path: path, rot: rot, codec: codec}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open output file %s: %w", rf.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat output file %s: %w", rf.path, err)
+	}
+	rf.f, rf.size, rf.opened = f, info.Size(), time.Now()
+
+	cw := &countingWriter{w: f, n: &rf.size}
+	switch rf.codec {
+	case "gzip":
+		rf.enc = gzip.NewWriter(cw)
+	case "zstd":
+		zw, err := zstd.NewWriter(cw)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("zstd output file %s: %w", rf.path, err)
+		}
+		rf.enc = zw
+	default:
+		rf.enc = nil
+		rf.w = cw
+		return nil
+	}
+	rf.w = rf.enc
+	return nil
+}
+
+// closeFile finishes the compressed stream (if any) and closes the file.
+func (rf *rotatingFile) closeFile() error {
+	if rf.enc != nil {
+		if err := rf.enc.Close(); err != nil {
+			rf.f.Close()
+			return err
+		}
+	}
+	return rf.f.Close()
+}
+
+// countingWriter adds the bytes written through it to *n.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// Write appends p, rotating first if it would cross a limit.
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.size > 0 && rf.rot.enabled() &&
+		((rf.rot.MaxSize > 0 && rf.size+int64(len(p)) > rf.rot.MaxSize) ||
+			(rf.rot.MaxAge > 0 && time.Since(rf.opened) >= rf.rot.MaxAge)) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	return rf.w.Write(p)
+}
+
+// rotate moves the current file aside, opens a new one and prunes backups.
+func (rf *rotatingFile) rotate() error {
+	if err := rf.closeFile(); err != nil {
+		return err
+	}
+
+	rotated := rf.path + "." + time.Now().Format(rotatedTimeFormat)
+	for i := 1; fileExists(rotated) || fileExists(rotated+".gz"); i++ {
+		rotated = fmt.Sprintf("%s.%s.%d", rf.path, time.Now().Format(rotatedTimeFormat), i)
+	}
+	if err := os.Rename(rf.path, rotated); err != nil {
+		return fmt.Errorf("rotate %s: %w", rf.path, err)
+	}
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	rf.wg.Add(1)
+	go func() {
+		defer rf.wg.Done()
+		rf.bgMu.Lock()
+		defer rf.bgMu.Unlock()
+		if rf.rot.Compress && rf.codec == "" {
+			_ = gzipFile(rotated)
+		}
+		rf.prune()
+	}()
+	return nil
+}
+
+// prune removes the oldest rotated files beyond MaxBackups.
+func (rf *rotatingFile) prune() {
+	if rf.rot.MaxBackups <= 0 {
+		return
+	}
+	dir, base := filepath.Split(rf.path)
+	entries, _ := os.ReadDir(filepath.Clean(dir + "."))
+	type backup struct {
+		path string
+		mod  time.Time
+	}
+	var backups []backup
+	for _, de := range entries {
+		if de.IsDir() || !isRotatedName(base, de.Name()) {
+			continue
+		}
+		m := filepath.Join(dir, de.Name())
+		if info, err := os.Stat(m); err == nil {
+			backups = append(backups, backup{m, info.ModTime()})
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].mod.Equal(backups[j].mod) {
+			return backups[i].mod.Before(backups[j].mod)
+		}
+		return backups[i].path < backups[j].path
+	})
+	for len(backups) > rf.rot.MaxBackups {
+		_ = os.Remove(backups[0].path)
+		backups = backups[1:]
+	}
+}
+
+// isRotatedName reports whether name is a backup of base made by rotate:
+// <base>.<rotatedTimeFormat>[.N][.gz].
+func isRotatedName(base, name string) bool {
+	rest, ok := strings.CutPrefix(name, base+".")
+	if !ok {
+		return false
+	}
+	rest = strings.TrimSuffix(rest, ".gz")
+	stamp, n, hasN := strings.Cut(rest, ".")
+	if _, err := time.Parse(rotatedTimeFormat, stamp); err != nil {
+		return false
+	}
+	if hasN {
+		if _, err := strconv.Atoi(n); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// flush pushes buffered compressed data to the file, so that everything
+// written so far can be decompressed even if lx dies without closing it.
+func (rf *rotatingFile) flush() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.enc == nil {
+		return nil
+	}
+	return rf.enc.Flush()
+}
+
+// Sync flushes the current file to disk.
+func (rf *rotatingFile) Sync() error {
+	if err := rf.flush(); err != nil {
+		return err
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.f.Sync()
+}
+
+// Close closes the current file and waits for pending compressions.
+func (rf *rotatingFile) Close() error {
+	rf.mu.Lock()
+	err := rf.closeFile()
+	rf.mu.Unlock()
+	rf.wg.Wait()
+	return err
+}
+
+// gzipFile compresses path to path.gz and removes the original. The
+// modification time is preserved so backups still prune oldest-first.
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".gz.tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	_ = os.Chtimes(tmp, info.ModTime(), info.ModTime())
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
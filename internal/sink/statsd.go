@@ -0,0 +1,274 @@
+package sink
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/monitor"
+)
+
+// maxStatsDPacket keeps datagrams under a typical MTU.
+const maxStatsDPacket = 1432
+
+// maxStatsDValues bounds the distinct values of a CountBy field reported
+// per interval; the rest are summed up as "(other)".
+const maxStatsDValues = 200
+
+// StatsDOptions configures a StatsDSink.
+type StatsDOptions struct {
+	Addr          string               // host:port of the StatsD agent
+	Prefix        string               // metric name prefix, default "lx"
+	Tags          []string             // DogStatsD tags added to every metric ("env:prod")
+	DogStatsD     bool                 // use tags instead of dotted names for level/rule
+	FlushInterval time.Duration        // how often metrics are sent, default 10s
+	Alerts        *monitor.AlertEngine // optional; per-rule alert counters
+	CountBy       []string             // fields to count lines by, per value
+	OnError       func(error)          // called when a packet cannot be sent
+}
+
+// StatsDSink reports matched entries as StatsD metrics instead of writing
+// them: a lines counter per level, one per value of each CountBy field
+// (lx.by_status.500), an alerts counter per alert rule, and a gauge of the
+// matched line rate over the last interval. Counters are aggregated locally
+// and sent once per interval over UDP.
+type StatsDSink struct {
+	opts StatsDOptions
+	conn net.Conn
+
+	mu        sync.Mutex
+	levels    map[string]int64
+	groups    map[string]map[string]int64 // field -> value -> count
+	lines     int64
+	lastFlush time.Time
+	alerts    map[string]int // rule counts already reported
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewStatsDSink dials the agent and starts the reporting loop.
+func NewStatsDSink(opts StatsDOptions) (*StatsDSink, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = "lx"
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 10 * time.Second
+	}
+	if len(opts.Tags) > 0 {
+		opts.DogStatsD = true
+	}
+
+	conn, err := net.Dial("udp", opts.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+
+	s := &StatsDSink{
+		opts:      opts,
+		conn:      conn,
+		levels:    make(map[string]int64),
+		groups:    make(map[string]map[string]int64),
+		lastFlush: time.Now(),
+		alerts:    make(map[string]int),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s, nil
+}
+
+// Write counts the entry under its level and CountBy field values.
+func (s *StatsDSink) Write(e *entry.LogEntry) error {
+	level := "unknown"
+	if e.Level != entry.LevelUnknown {
+		level = strings.ToLower(e.Level.String())
+	}
+	s.mu.Lock()
+	s.levels[level]++
+	s.lines++
+	for _, f := range s.opts.CountBy {
+		v, ok := e.Fields[f]
+		if !ok {
+			continue
+		}
+		values := s.groups[f]
+		if values == nil {
+			values = make(map[string]int64)
+			s.groups[f] = values
+		}
+		if _, seen := values[v]; !seen && len(values) >= maxStatsDValues {
+			v = monitor.OtherValue
+		}
+		values[v]++
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Flush sends the metrics accumulated so far.
+func (s *StatsDSink) Flush() error {
+	return s.send(false)
+}
+
+// Close sends remaining metrics and stops reporting.
+func (s *StatsDSink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+		err = s.send(true)
+		s.conn.Close()
+	})
+	return err
+}
+
+// Name returns the sink identifier.
+func (s *StatsDSink) Name() string {
+	return "statsd:" + s.opts.Addr
+}
+
+func (s *StatsDSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.send(false); err != nil && s.opts.OnError != nil {
+				s.opts.OnError(err)
+			}
+		}
+	}
+}
+
+// send formats pending counters and the rate gauge and writes them in as
+// few datagrams as possible. With onlyPending, nothing is sent when no
+// entries or alerts were counted since the last send (so Close right after
+// Flush does not report a spurious zero rate).
+func (s *StatsDSink) send(onlyPending bool) error {
+	s.mu.Lock()
+	if onlyPending && s.lines == 0 && !s.alertsPending() {
+		s.mu.Unlock()
+		return nil
+	}
+	levels := s.levels
+	groups := s.groups
+	lines := s.lines
+	s.levels = make(map[string]int64)
+	s.groups = make(map[string]map[string]int64)
+	s.lines = 0
+	now := time.Now()
+	elapsed := now.Sub(s.lastFlush).Seconds()
+	s.lastFlush = now
+
+	var alertDeltas map[string]int
+	if s.opts.Alerts != nil {
+		alertDeltas = make(map[string]int)
+		for rule, n := range s.opts.Alerts.Counts() {
+			if d := n - s.alerts[rule]; d > 0 {
+				alertDeltas[rule] = d
+			}
+			s.alerts[rule] = n
+		}
+	}
+	s.mu.Unlock()
+
+	var metrics []string
+	for _, level := range sortedKeys(levels) {
+		metrics = append(metrics, s.metric("lines", "level", level, fmt.Sprintf("%d|c", levels[level])))
+	}
+	for _, f := range s.opts.CountBy {
+		for _, v := range sortedKeys(groups[f]) {
+			metrics = append(metrics, s.metric("by_"+statsdSanitize(f), f, v, fmt.Sprintf("%d|c", groups[f][v])))
+		}
+	}
+	for _, rule := range sortedKeys(alertDeltas) {
+		metrics = append(metrics, s.metric("alerts", "rule", rule, fmt.Sprintf("%d|c", alertDeltas[rule])))
+	}
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(lines) / elapsed
+	}
+	metrics = append(metrics, s.metric("rate", "", "", fmt.Sprintf("%.2f|g", rate)))
+
+	var packet strings.Builder
+	for _, m := range metrics {
+		if packet.Len() > 0 && packet.Len()+1+len(m) > maxStatsDPacket {
+			if _, err := s.conn.Write([]byte(packet.String())); err != nil {
+				return fmt.Errorf("statsd: %w", err)
+			}
+			packet.Reset()
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(m)
+	}
+	if _, err := s.conn.Write([]byte(packet.String())); err != nil {
+		return fmt.Errorf("statsd: %w", err)
+	}
+	return nil
+}
+
+// alertsPending reports whether any rule triggered since the last send.
+func (s *StatsDSink) alertsPending() bool {
+	if s.opts.Alerts == nil {
+		return false
+	}
+	for rule, n := range s.opts.Alerts.Counts() {
+		if n > s.alerts[rule] {
+			return true
+		}
+	}
+	return false
+}
+
+// metric renders one line. With DogStatsD the dimension becomes a tag
+// (lx.lines:3|c|#level:error); otherwise it is appended to the name
+// (lx.lines.error:3|c).
+func (s *StatsDSink) metric(name, tagKey, tagValue, value string) string {
+	full := s.opts.Prefix + "." + name
+	tags := s.opts.Tags
+	if tagKey != "" {
+		if s.opts.DogStatsD {
+			tags = append(append([]string(nil), tags...), tagKey+":"+statsdSanitize(tagValue))
+		} else {
+			full += "." + statsdSanitize(tagValue)
+		}
+	}
+	line := full + ":" + value
+	if s.opts.DogStatsD && len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line
+}
+
+// statsdSanitize makes a rule pattern or level safe as a name segment or
+// tag value.
+func statsdSanitize(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
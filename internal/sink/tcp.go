@@ -0,0 +1,236 @@
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// TCPOptions configures a TCPSink.
+type TCPOptions struct {
+	Addr      string        // host:port to connect to
+	Format    string        // "text" (default), "json" or a template
+	QueueSize int           // lines held while disconnected (oldest dropped beyond this)
+	Timeout   time.Duration // dial/write timeout, and how long Close waits to drain
+	OnError   func(error)   // called on connection loss and dropped lines
+}
+
+// TCPSink writes entries as lines (text, JSON or templated) to a TCP
+// endpoint such as a logstash tcp input or `nc -l`. Lines are queued in
+// memory and written by a background worker that reconnects with backoff
+// whenever the connection fails, so a restarting receiver loses nothing as
+// long as the queue does not overflow.
+type TCPSink struct {
+	opts TCPOptions
+	fmt  Sink
+	buf  bytes.Buffer
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	lines    [][]byte
+	inflight int
+	dropped  int
+	closed   bool
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewTCPSink validates opts and starts the writer. The connection is made
+// lazily, so lx starts even while the receiver is down.
+func NewTCPSink(opts TCPOptions) (*TCPSink, error) {
+	if opts.Addr == "" {
+		return nil, fmt.Errorf("tcp sink: address is required")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	s := &TCPSink{opts: opts, done: make(chan struct{})}
+	f, err := newFormatSink(&s.buf, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	s.fmt = f
+	s.cond = sync.NewCond(&s.mu)
+	go s.run()
+	return s, nil
+}
+
+// Write formats e and queues the line.
+func (s *TCPSink) Write(e *entry.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Reset()
+	if err := s.fmt.Write(e); err != nil {
+		return err
+	}
+	line := append([]byte(nil), s.buf.Bytes()...)
+
+	if len(s.lines) >= s.opts.QueueSize {
+		s.lines = s.lines[1:]
+		s.dropped++
+	}
+	s.lines = append(s.lines, line)
+	s.cond.Signal()
+	return nil
+}
+
+// Flush waits (up to the timeout) until every queued line has been written,
+// and fails if some are still queued then.
+func (s *TCPSink) Flush() error {
+	deadline := time.Now().Add(s.opts.Timeout)
+	for {
+		s.mu.Lock()
+		pending := len(s.lines) + s.inflight
+		s.mu.Unlock()
+		if pending == 0 {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("tcp sink %s: %d lines still queued after %s", s.opts.Addr, pending, s.opts.Timeout)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// Close drains the queue (up to the timeout) and disconnects.
+func (s *TCPSink) Close() error {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		<-s.done
+
+		s.mu.Lock()
+		lost := s.dropped + len(s.lines)
+		s.mu.Unlock()
+		if lost > 0 && s.opts.OnError != nil {
+			s.opts.OnError(fmt.Errorf("tcp sink %s: %d lines not delivered", s.opts.Addr, lost))
+		}
+	})
+	return nil
+}
+
+// Name returns the sink identifier.
+func (s *TCPSink) Name() string {
+	return "tcp:" + s.opts.Addr
+}
+
+// run writes queued lines, reconnecting with backoff. After Close it keeps
+// trying until the queue is empty or the timeout passes.
+func (s *TCPSink) run() {
+	defer close(s.done)
+
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	var closeDeadline time.Time
+	backoff := 500 * time.Millisecond
+	reported := false
+
+	for {
+		s.mu.Lock()
+		for len(s.lines) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			if closeDeadline.IsZero() {
+				closeDeadline = time.Now().Add(s.opts.Timeout)
+			}
+			if len(s.lines) == 0 || time.Now().After(closeDeadline) {
+				s.mu.Unlock()
+				return
+			}
+		}
+		batch := s.lines
+		if len(batch) > 256 {
+			batch = batch[:256]
+		}
+		s.lines = s.lines[len(batch):]
+		s.inflight = len(batch)
+		s.mu.Unlock()
+
+		n, err := func() (int, error) {
+			if conn != nil && !peerOpen(conn) {
+				conn.Close()
+				conn = nil
+			}
+			if conn == nil {
+				c, err := net.DialTimeout("tcp", s.opts.Addr, s.opts.Timeout)
+				if err != nil {
+					return 0, err
+				}
+				conn = c
+			}
+			_ = conn.SetWriteDeadline(time.Now().Add(s.opts.Timeout))
+			return conn.Write(bytes.Join(batch, nil))
+		}()
+
+		s.mu.Lock()
+		s.inflight = 0
+		if err == nil {
+			s.mu.Unlock()
+			backoff = 500 * time.Millisecond
+			reported = false
+			continue
+		}
+
+		// Put the lines not completely written back in front, keeping the
+		// queue bounded. A line cut off mid-way is sent again in full.
+		for len(batch) > 0 && n >= len(batch[0]) {
+			n -= len(batch[0])
+			batch = batch[1:]
+		}
+		s.lines = append(append(make([][]byte, 0, len(batch)+len(s.lines)), batch...), s.lines...)
+		if over := len(s.lines) - s.opts.QueueSize; over > 0 {
+			s.lines = s.lines[over:]
+			s.dropped += over
+		}
+		s.mu.Unlock()
+
+		if conn != nil {
+			conn.Close()
+			conn = nil
+		}
+		if !reported && s.opts.OnError != nil {
+			s.opts.OnError(fmt.Errorf("tcp sink %s: %w (retrying)", s.opts.Addr, err))
+			reported = true
+		}
+		wait := backoff
+		if !closeDeadline.IsZero() {
+			wait = min(wait, time.Until(closeDeadline))
+		}
+		time.Sleep(wait)
+		if backoff *= 2; backoff > 30*time.Second {
+			backoff = 30 * time.Second
+		}
+	}
+}
+
+// peerOpen reports whether the receiver still has the connection open. A
+// write to a connection the peer already closed usually succeeds and the data
+// is silently lost, so check for a pending EOF first. Receivers are not
+// expected to send anything; any data they do send is discarded.
+func peerOpen(conn net.Conn) bool {
+	var b [512]byte
+	_ = conn.SetReadDeadline(time.Now())
+	_, err := conn.Read(b[:])
+	_ = conn.SetReadDeadline(time.Time{})
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return true
+	}
+	return err == nil
+}
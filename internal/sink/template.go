@@ -0,0 +1,101 @@
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// TemplateSink writes each entry through a user-supplied text/template, e.g.
+// '{{.Timestamp}} {{.Fields.status}} {{.Message}}'. A newline is appended
+// unless the template output already ends with one. Missing fields render
+// as empty strings.
+type TemplateSink struct {
+	w    io.Writer
+	tmpl *template.Template
+	buf  bytes.Buffer
+}
+
+// templateEntry is the value templates are executed against.
+type templateEntry struct {
+	Timestamp templateTime
+	Stream    string
+	Level     string // empty when unknown
+	Source    string
+	Message   string
+	Fields    map[string]string
+	Seq       uint64
+}
+
+// newTemplateEntry returns the template value of e.
+func newTemplateEntry(e *entry.LogEntry) templateEntry {
+	te := templateEntry{
+		Timestamp: templateTime{e.Timestamp},
+		Stream:    e.Stream,
+		Source:    e.Source,
+		Message:   e.Message,
+		Fields:    e.Fields,
+		Seq:       e.Seq,
+	}
+	if e.Level != entry.LevelUnknown {
+		te.Level = e.Level.String()
+	}
+	return te
+}
+
+// templateTime prints as RFC3339 but keeps time.Time's methods, so
+// {{.Timestamp.Format "15:04:05.000"}} and {{.Timestamp.Unix}} work too.
+type templateTime struct {
+	time.Time
+}
+
+// String formats the time like the text sink.
+func (t templateTime) String() string {
+	return t.Format(time.RFC3339)
+}
+
+// IsTemplateFormat reports whether a --format value is a template rather
+// than a named format.
+func IsTemplateFormat(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// NewTemplateSink parses text and returns a sink writing to w.
+func NewTemplateSink(w io.Writer, text string) (*TemplateSink, error) {
+	if w == nil {
+		w = os.Stdout
+	}
+	t, err := template.New("format").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("format template: %w", err)
+	}
+	return &TemplateSink{w: w, tmpl: t}, nil
+}
+
+// Write renders e and writes the result as one line.
+func (s *TemplateSink) Write(e *entry.LogEntry) error {
+	s.buf.Reset()
+	if err := s.tmpl.Execute(&s.buf, newTemplateEntry(e)); err != nil {
+		return fmt.Errorf("format template: %w", err)
+	}
+	if !bytes.HasSuffix(s.buf.Bytes(), []byte{'\n'}) {
+		s.buf.WriteByte('\n')
+	}
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+// Flush flushes the writer if it is buffered.
+func (s *TemplateSink) Flush() error { return flushWriter(s.w) }
+
+// Close is a no-op for the template sink.
+func (s *TemplateSink) Close() error { return nil }
+
+// Name returns the sink identifier.
+func (s *TemplateSink) Name() string { return "template" }
@@ -21,29 +21,36 @@ const (
 
 // TerminalSink writes log entries to a terminal with optional ANSI color.
 type TerminalSink struct {
-	w     io.Writer
-	color bool
+	w          io.Writer
+	color      bool
+	showSource bool
 }
 
 // NewTerminalSink creates a sink that writes to the given writer.
 // If color is true, output will include ANSI color codes based on log level.
-func NewTerminalSink(w io.Writer, color bool) *TerminalSink {
+// If showSource is true, each line is prefixed with the entry's source name,
+// which is useful when several sources are merged.
+func NewTerminalSink(w io.Writer, color, showSource bool) *TerminalSink {
 	if w == nil {
 		w = os.Stdout
 	}
-	return &TerminalSink{w: w, color: color}
+	return &TerminalSink{w: w, color: color, showSource: showSource}
 }
 
 // Write outputs a formatted log entry.
 func (s *TerminalSink) Write(e *entry.LogEntry) error {
 	ts := e.Timestamp.Format(time.RFC3339)
+	stream := e.Stream
+	if s.showSource && e.Source != "" {
+		stream = e.Source + "][" + e.Stream
+	}
 
 	if !s.color {
 		if e.Level != entry.LevelUnknown {
-			_, err := fmt.Fprintf(s.w, "[%s][%s][%s]: %s\n", ts, e.Stream, e.Level, e.Message)
+			_, err := fmt.Fprintf(s.w, "[%s][%s][%s]: %s\n", ts, stream, e.Level, e.Message)
 			return err
 		}
-		_, err := fmt.Fprintf(s.w, "[%s][%s]: %s\n", ts, e.Stream, e.Message)
+		_, err := fmt.Fprintf(s.w, "[%s][%s]: %s\n", ts, stream, e.Message)
 		return err
 	}
 
@@ -52,7 +59,7 @@ func (s *TerminalSink) Write(e *entry.LogEntry) error {
 	if e.Level != entry.LevelUnknown {
 		_, err := fmt.Fprintf(s.w, "%s[%s]%s[%s]%s[%s]%s: %s\n",
 			colorGray, ts, colorReset,
-			e.Stream,
+			stream,
 			levelColor, e.Level, colorReset,
 			e.Message,
 		)
@@ -60,14 +67,14 @@ func (s *TerminalSink) Write(e *entry.LogEntry) error {
 	}
 	_, err := fmt.Fprintf(s.w, "%s[%s]%s[%s]: %s\n",
 		colorGray, ts, colorReset,
-		e.Stream,
+		stream,
 		e.Message,
 	)
 	return err
 }
 
-// Flush is a no-op for terminal output.
-func (s *TerminalSink) Flush() error { return nil }
+// Flush flushes the writer if it is buffered.
+func (s *TerminalSink) Flush() error { return flushWriter(s.w) }
 
 // Close is a no-op for terminal output.
 func (s *TerminalSink) Close() error { return nil }
@@ -0,0 +1,149 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checkpointHead is how many leading bytes of a file identify it, so that a
+// rotated or replaced file is not resumed at the old file's offset.
+const checkpointHead = 256
+
+// checkpointInterval is how often a Checkpoint is written while reading.
+const checkpointInterval = time.Second
+
+// FileOffset is where reading a file stopped: Offset bytes in, in the file
+// whose first Head bytes hash to Hash.
+type FileOffset struct {
+	Offset int64  `json:"offset"`
+	Head   int    `json:"head"`
+	Hash   string `json:"hash"`
+}
+
+// Checkpoint records how far each file of a FileSource has been read, in a
+// small JSON file, so that the next session resumes there instead of
+// reading the files again (or, with --tail, skipping what was written while
+// lx was not running). Compressed files are read once and not checkpointed.
+type Checkpoint struct {
+	path string
+
+	mu    sync.Mutex
+	files map[string]FileOffset
+	dirty bool
+}
+
+// LoadCheckpoint reads the checkpoint file at path; a missing file is an
+// empty checkpoint.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, files: make(map[string]FileOffset)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.files); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the offsets to the checkpoint file, if they changed, through a
+// temporary file so a crash never leaves it half written.
+func (c *Checkpoint) Save() error {
+	c.mu.Lock()
+	if !c.dirty {
+		c.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(c.files, "", "  ")
+	c.dirty = false
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// saveEvery saves the checkpoint every interval until ctx is done.
+func (c *Checkpoint) saveEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.Save()
+		}
+	}
+}
+
+// resume positions f at the recorded offset of path and reports whether it
+// did; it does not when there is none, or the file was replaced or
+// truncated since, and then forgets the old offset.
+func (c *Checkpoint) resume(path string, f *openedFile) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o, ok := c.files[path]
+	if !ok {
+		return false, nil
+	}
+	st, err := f.f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if hash, _ := fileHead(f, o.Head); hash != o.Hash || st.Size() < o.Offset {
+		delete(c.files, path)
+		c.dirty = true
+		return false, nil
+	}
+	_, err = f.f.Seek(o.Offset, io.SeekStart)
+	return err == nil, err
+}
+
+// set records that path has been read up to offset.
+func (c *Checkpoint) set(path string, f *openedFile, offset int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := c.files[path]
+	if o.Head < checkpointHead && o.Offset != offset {
+		// The head is still growing with a new file; hash what is there.
+		o.Hash, o.Head = fileHead(f, checkpointHead)
+	}
+	o.Offset = offset
+	c.files[path] = o
+	c.dirty = true
+}
+
+// fileHead hashes the first n bytes of f (fewer if it is shorter) and
+// returns the hash and the bytes hashed.
+func fileHead(f *openedFile, n int) (string, int) {
+	buf := make([]byte, n)
+	n, _ = f.f.ReadAt(buf, 0)
+	h := fnv.New64a()
+	h.Write(buf[:n])
+	return fmt.Sprintf("%016x", h.Sum64()), n
+}
@@ -0,0 +1,88 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// startTimestampedCommand runs a container runtime's log command (podman logs,
+// crictl logs, ...) whose stdout/stderr mirror the container's streams with an
+// RFC3339 timestamp prefix on every line.
+func startTimestampedCommand(ctx context.Context, bin string, args []string, name string, seq *atomic.Uint64) (<-chan entry.LogEntry, error) {
+	cmd := exec.CommandContext(ctx, bin, args...)
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("%s stdout pipe: %w", bin, err)
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("%s stderr pipe: %w", bin, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s logs start: %w (is %s installed?)", bin, err, bin)
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	read := func(stream string, r io.Reader) {
+		defer wg.Done()
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			ts, msg := parseLeadingTimestamp(scanner.Text())
+			ch <- entry.LogEntry{
+				Timestamp: ts,
+				Stream:    stream,
+				Source:    name,
+				Message:   msg,
+				Raw:       []byte(msg),
+				Seq:       seq.Add(1),
+			}
+		}
+	}
+
+	go read("stdout", stdoutPipe)
+	go read("stderr", stderrPipe)
+
+	go func() {
+		wg.Wait()
+		_ = cmd.Wait()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// parseLeadingTimestamp splits an RFC3339 timestamp prefix off a log line.
+// Unlike Docker, Podman does not pad fractional seconds or force UTC.
+func parseLeadingTimestamp(line string) (time.Time, string) {
+	tsStr, msg, ok := strings.Cut(line, " ")
+	if !ok {
+		return time.Now(), line
+	}
+	ts, err := time.Parse(time.RFC3339Nano, tsStr)
+	if err != nil {
+		return time.Now(), line
+	}
+	return ts, msg
+}
@@ -0,0 +1,101 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// openedFile is a log file prepared for reading, possibly through a decompressor.
+type openedFile struct {
+	f          *os.File
+	r          io.Reader
+	release    func()
+	compressed bool // compressed files are read once, never followed
+}
+
+// Close releases the decompressor (if any) and the underlying file.
+func (o *openedFile) Close() error {
+	if o.release != nil {
+		o.release()
+	}
+	return o.f.Close()
+}
+
+// openLogFile opens path and wraps it in a gzip or zstd reader when the file
+// is compressed. Detection uses magic bytes; a .gz/.zst extension on a file
+// without them (e.g. an empty archive) still marks it as not followable.
+func openLogFile(path string) (*openedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 4)
+	n, _ := f.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("gzip %s: %w", path, err)
+		}
+		return &openedFile{f: f, r: zr, release: func() { zr.Close() }, compressed: true}, nil
+
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("zstd %s: %w", path, err)
+		}
+		return &openedFile{f: f, r: zr, release: zr.Close, compressed: true}, nil
+	}
+
+	return &openedFile{f: f, r: f, compressed: hasArchiveExt(path)}, nil
+}
+
+// hasArchiveExt reports whether path carries a compressed-file extension.
+func hasArchiveExt(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".zst", ".zstd":
+		return true
+	}
+	return false
+}
+
+// decompressStream peeks at r's magic bytes and wraps it in a gzip or zstd
+// reader when compressed. Used for non-seekable streams such as object downloads.
+func decompressStream(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	head, _ := br.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gzip: %w", err)
+		}
+		return zr, func() { zr.Close() }, nil
+	case bytes.HasPrefix(head, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("zstd: %w", err)
+		}
+		return zr, zr.Close, nil
+	}
+	return br, func() {}, nil
+}
@@ -1,28 +1,38 @@
 package source
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os/exec"
 	"sync/atomic"
 	"time"
 
 	"github.com/Geun-Oh/lx/internal/entry"
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
 )
 
-// DockerSource reads logs from a Docker container via `docker logs --follow`.
+// ContainerOptions controls which container logs are fetched (Docker and Podman).
+type ContainerOptions struct {
+	Follow bool      // keep streaming new lines (and reattach after restarts)
+	Since  time.Time // only return lines after this time (zero = from the beginning)
+	Until  time.Time // only return lines before this time (zero = no limit)
+	Tail   int       // start with the last N lines (<0 = all)
+}
+
+// DockerSource reads logs from a Docker container via the Docker Engine API
+// client, so the docker CLI is not required.
 type DockerSource struct {
 	container string
-	follow    bool
+	opts      ContainerOptions
 	seq       atomic.Uint64
 }
 
 // NewDockerSource creates a source that reads from a Docker container's logs.
-func NewDockerSource(container string, follow bool) *DockerSource {
+func NewDockerSource(container string, opts ContainerOptions) *DockerSource {
 	return &DockerSource{
 		container: container,
-		follow:    follow,
+		opts:      opts,
 	}
 }
 
@@ -31,91 +41,131 @@ func (s *DockerSource) Name() string {
 	return fmt.Sprintf("docker:%s", s.container)
 }
 
-// Start executes `docker logs` and returns a channel of log entries.
+// Start connects to the Docker daemon and returns a channel of log entries.
+// When following, the source reattaches after the container restarts and
+// resumes from the last timestamp it delivered.
 func (s *DockerSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
-	args := []string{"logs"}
-	if s.follow {
-		args = append(args, "--follow")
-	}
-	args = append(args, "--timestamps", s.container)
-
-	cmd := exec.CommandContext(ctx, "docker", args...)
-
-	// Docker sends stdout and stderr interleaved via stderr when using --follow.
-	// Capture both.
-	stdoutPipe, err := cmd.StdoutPipe()
+	client, err := newDockerClient()
 	if err != nil {
-		return nil, fmt.Errorf("docker stdout pipe: %w", err)
+		return nil, err
 	}
-	stderrPipe, err := cmd.StderrPipe()
+
+	info, err := client.ContainerInspect(ctx, s.container)
 	if err != nil {
-		return nil, fmt.Errorf("docker stderr pipe: %w", err)
+		client.Close()
+		return nil, fmt.Errorf("docker inspect %s: %w", s.container, err)
 	}
 
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("docker logs start: %w (is docker running?)", err)
+	body, err := containerLogs(ctx, client, s.container, logsQuery{
+		follow: s.opts.Follow,
+		since:  s.opts.Since,
+		until:  s.opts.Until,
+		tail:   s.opts.Tail,
+	})
+	if err != nil {
+		client.Close()
+		return nil, err
 	}
 
 	ch := make(chan entry.LogEntry, 256)
 
 	go func() {
 		defer close(ch)
-
-		done := make(chan struct{})
-
-		// Read stdout.
-		go func() {
-			scanner := bufio.NewScanner(stdoutPipe)
-			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-			for scanner.Scan() {
+		defer client.Close()
+
+		var last time.Time
+		for {
+			tty := info.Config != nil && info.Config.Tty
+			_ = demuxLines(body, tty, func(stream, line string) {
+				ts, msg := parseDockerTimestamp(line)
+				last = ts
 				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
-				ts, msg := parseDockerTimestamp(scanner.Text())
-				ch <- entry.LogEntry{
+				case ch <- entry.LogEntry{
 					Timestamp: ts,
-					Stream:    "stdout",
+					Stream:    stream,
 					Source:    s.Name(),
 					Message:   msg,
+					Raw:       []byte(msg),
 					Seq:       s.seq.Add(1),
-				}
-			}
-			done <- struct{}{}
-		}()
-
-		// Read stderr.
-		go func() {
-			scanner := bufio.NewScanner(stderrPipe)
-			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-			for scanner.Scan() {
-				select {
+				}:
 				case <-ctx.Done():
-					return
-				default:
 				}
-				ts, msg := parseDockerTimestamp(scanner.Text())
-				ch <- entry.LogEntry{
-					Timestamp: ts,
-					Stream:    "stderr",
-					Source:    s.Name(),
-					Message:   msg,
-					Seq:       s.seq.Add(1),
+			})
+			body.Close()
+
+			if !s.opts.Follow || ctx.Err() != nil {
+				return
+			}
+			if !s.opts.Until.IsZero() && time.Now().After(s.opts.Until) {
+				return
+			}
+
+			// The stream ended: the container stopped. Wait for it to come back.
+			info, err = s.waitRunning(ctx, client)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.notice(ctx, ch, err.Error())
 				}
+				return
 			}
-			done <- struct{}{}
-		}()
 
-		// Wait for both readers.
-		<-done
-		<-done
-		_ = cmd.Wait()
+			since := s.opts.Since
+			if !last.IsZero() {
+				since = last.Add(time.Nanosecond)
+			}
+			body, err = containerLogs(ctx, client, s.container, logsQuery{follow: true, since: since, until: s.opts.Until, tail: -1})
+			if err != nil {
+				if ctx.Err() == nil {
+					s.notice(ctx, ch, err.Error())
+				}
+				return
+			}
+		}
 	}()
 
 	return ch, nil
 }
 
+// waitRunning polls the daemon until the container is running again. It
+// fails if the container was removed or ctx is cancelled first; other errors
+// (e.g. the daemon restarting) are retried.
+func (s *DockerSource) waitRunning(ctx context.Context, client *client.Client) (container.InspectResponse, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return container.InspectResponse{}, ctx.Err()
+		case <-ticker.C:
+		}
+
+		info, err := client.ContainerInspect(ctx, s.container)
+		if cerrdefs.IsNotFound(err) {
+			return info, fmt.Errorf("docker container %s was removed", s.container)
+		}
+		if err == nil && info.State != nil && info.State.Running {
+			return info, nil
+		}
+	}
+}
+
+// notice emits a synthetic lx entry, e.g. why the source stopped.
+func (s *DockerSource) notice(ctx context.Context, ch chan<- entry.LogEntry, msg string) {
+	select {
+	case ch <- entry.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    "lx",
+		Level:     entry.LevelWarn,
+		Source:    s.Name(),
+		Message:   msg,
+		Raw:       []byte(msg),
+		Seq:       s.seq.Add(1),
+	}:
+	case <-ctx.Done():
+	}
+}
+
 // parseDockerTimestamp extracts the timestamp from a Docker log line.
 // Docker --timestamps format: "2025-01-26T13:32:19.123456789Z message..."
 func parseDockerTimestamp(line string) (time.Time, string) {
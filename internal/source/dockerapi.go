@@ -0,0 +1,117 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// newDockerClient connects to the Docker daemon the way the docker CLI does:
+// DOCKER_HOST picks the endpoint (the local socket by default),
+// DOCKER_TLS_VERIFY and DOCKER_CERT_PATH configure TLS, and the API version
+// is negotiated unless DOCKER_API_VERSION pins it.
+func newDockerClient() (*client.Client, error) {
+	c, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, fmt.Errorf("docker client: %w", err)
+	}
+	return c, nil
+}
+
+// logsQuery holds the parameters for a container logs request.
+type logsQuery struct {
+	follow bool
+	since  time.Time
+	until  time.Time
+	tail   int // <0 means all
+}
+
+// containerLogs opens the container log stream. The caller must close the
+// returned body.
+func containerLogs(ctx context.Context, c *client.Client, name string, q logsQuery) (io.ReadCloser, error) {
+	opts := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     q.follow,
+		Tail:       "all",
+	}
+	if !q.since.IsZero() {
+		opts.Since = formatDockerTime(q.since)
+	}
+	if !q.until.IsZero() {
+		opts.Until = formatDockerTime(q.until)
+	}
+	if q.tail >= 0 {
+		opts.Tail = strconv.Itoa(q.tail)
+	}
+	body, err := c.ContainerLogs(ctx, name, opts)
+	if err != nil {
+		return nil, fmt.Errorf("docker logs %s: %w", name, err)
+	}
+	return body, nil
+}
+
+// formatDockerTime renders t as the "seconds.nanoseconds" form the API accepts.
+func formatDockerTime(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// demuxLines decodes a Docker log stream and calls emit for every complete
+// line. Non-TTY containers multiplex stdout and stderr, which stdcopy
+// separates; TTY containers send raw stdout.
+func demuxLines(r io.Reader, tty bool, emit func(stream, line string)) error {
+	if tty {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			emit("stdout", strings.TrimSuffix(scanner.Text(), "\r"))
+		}
+		return scanner.Err()
+	}
+
+	stdout := &lineWriter{stream: "stdout", emit: emit}
+	stderr := &lineWriter{stream: "stderr", emit: emit}
+	_, err := stdcopy.StdCopy(stdout, stderr, r)
+	stdout.flush()
+	stderr.flush()
+	return err
+}
+
+// lineWriter splits what is written to it into lines for emit. Frames may
+// split lines, so a partial line is kept until its newline arrives.
+type lineWriter struct {
+	stream  string
+	partial string
+	emit    func(stream, line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	data := w.partial + string(p)
+	for {
+		idx := strings.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		w.emit(w.stream, data[:idx])
+		data = data[idx+1:]
+	}
+	w.partial = data
+	return len(p), nil
+}
+
+// flush emits the last line if it had no newline.
+func (w *lineWriter) flush() {
+	if w.partial != "" {
+		w.emit(w.stream, w.partial)
+		w.partial = ""
+	}
+}
@@ -13,10 +13,39 @@ import (
 	"github.com/Geun-Oh/lx/internal/entry"
 )
 
+// RestartPolicy controls whether ExecSource reruns its command after it exits.
+type RestartPolicy string
+
+const (
+	RestartNever     RestartPolicy = "no"
+	RestartAlways    RestartPolicy = "always"
+	RestartOnFailure RestartPolicy = "on-failure"
+)
+
+// ParseRestartPolicy validates a --restart value.
+func ParseRestartPolicy(s string) (RestartPolicy, error) {
+	switch p := RestartPolicy(s); p {
+	case "", RestartNever:
+		return RestartNever, nil
+	case RestartAlways, RestartOnFailure:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid restart policy %q (want no, always or on-failure)", s)
+}
+
+// Restart backoff bounds. The delay doubles after each quick exit and resets
+// once the command has stayed up for restartResetAfter.
+const (
+	restartMinBackoff = time.Second
+	restartMaxBackoff = 30 * time.Second
+	restartResetAfter = 10 * time.Second
+)
+
 // ExecSource executes a command and streams its stdout/stderr as LogEntry values.
 type ExecSource struct {
 	command string
 	args    []string
+	restart RestartPolicy
 	seq     atomic.Uint64
 }
 
@@ -25,17 +54,80 @@ func NewExecSource(command string, args []string) *ExecSource {
 	return &ExecSource{
 		command: command,
 		args:    args,
+		restart: RestartNever,
 	}
 }
 
+// SetRestart sets the restart policy applied when the command exits.
+// Each restart is announced with a synthetic INFO entry on the "lx" stream.
+func (s *ExecSource) SetRestart(p RestartPolicy) {
+	s.restart = p
+}
+
 // Name returns the source identifier.
 func (s *ExecSource) Name() string {
 	return fmt.Sprintf("exec:%s", s.command)
 }
 
 // Start executes the command and returns a channel of log entries.
-// The channel is closed when the command exits or ctx is cancelled.
+// The channel is closed when the command exits (and is not restarted)
+// or ctx is cancelled.
 func (s *ExecSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	ch := make(chan entry.LogEntry, 256)
+
+	done, err := s.launch(ctx, ch)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(ch)
+
+		backoff := restartMinBackoff
+		restarts := 0
+		for {
+			started := time.Now()
+			exitErr := <-done
+
+			if ctx.Err() != nil || s.restart == RestartNever ||
+				(s.restart == RestartOnFailure && exitErr == nil) {
+				return
+			}
+
+			if time.Since(started) >= restartResetAfter {
+				backoff = restartMinBackoff
+			}
+			restarts++
+			status := "exited"
+			if exitErr != nil {
+				status = exitErr.Error()
+			}
+			s.notice(ctx, ch, fmt.Sprintf("%s %s, restarting in %s (restart #%d)", s.command, status, backoff, restarts))
+
+			// Keep trying until the command starts again or ctx ends.
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, restartMaxBackoff)
+
+				done, err = s.launch(ctx, ch)
+				if err == nil {
+					break
+				}
+				s.notice(ctx, ch, fmt.Sprintf("restart of %s failed: %v, retrying in %s", s.command, err, backoff))
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// launch starts one run of the command. The returned channel yields the
+// command's exit error once its output has been fully read.
+func (s *ExecSource) launch(ctx context.Context, ch chan<- entry.LogEntry) (<-chan error, error) {
 	cmd := exec.CommandContext(ctx, s.command, s.args...)
 
 	stdoutPipe, err := cmd.StdoutPipe()
@@ -52,20 +144,35 @@ func (s *ExecSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
 		return nil, fmt.Errorf("start command: %w", err)
 	}
 
-	ch := make(chan entry.LogEntry, 256)
 	var wg sync.WaitGroup
 	wg.Add(2)
 
 	go s.readStream(ctx, "stdout", stdoutPipe, ch, &wg)
 	go s.readStream(ctx, "stderr", stderrPipe, ch, &wg)
 
+	done := make(chan error, 1)
 	go func() {
 		wg.Wait()
-		_ = cmd.Wait()
-		close(ch)
+		done <- cmd.Wait()
 	}()
 
-	return ch, nil
+	return done, nil
+}
+
+// notice emits a synthetic lx entry (e.g. restart announcements).
+func (s *ExecSource) notice(ctx context.Context, ch chan<- entry.LogEntry, msg string) {
+	select {
+	case ch <- entry.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    "lx",
+		Level:     entry.LevelInfo,
+		Source:    s.Name(),
+		Message:   msg,
+		Raw:       []byte(msg),
+		Seq:       s.seq.Add(1),
+	}:
+	case <-ctx.Done():
+	}
 }
 
 // readStream reads lines from a pipe and sends them to the channel.
@@ -4,87 +4,324 @@ import (
 	"bufio"
 	"context"
 	"fmt"
-	"os"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/Geun-Oh/lx/internal/entry"
 )
 
+// globRescanInterval controls how often a followed glob is re-expanded to
+// pick up files created after startup.
+const globRescanInterval = 2 * time.Second
+
 // FileSource reads log lines from a file, optionally following new writes (tail -f).
+// The path may be a glob (e.g. /var/log/app/*.log), in which case every matching
+// file is read concurrently and each entry's Source names the file it came from.
+// Gzip and zstd compressed files are decompressed transparently.
 type FileSource struct {
-	path   string
-	follow bool
-	seq    atomic.Uint64
+	path       string
+	follow     bool
+	bounds     TimeRange
+	tail       int
+	checkpoint *Checkpoint
+	seq        atomic.Uint64
 }
 
-// NewFileSource creates a source that reads from a file.
+// NewFileSource creates a source that reads from a file or glob pattern.
 // If follow is true, it continues reading as new lines are appended.
 func NewFileSource(path string, follow bool) *FileSource {
 	return &FileSource{
 		path:   path,
 		follow: follow,
+		tail:   -1,
 	}
 }
 
+// SetTail makes the source start with the last n lines of each file present
+// at startup instead of the whole file (n < 0 reads everything). Plain files
+// are read backwards from the end, so large files are not scanned in full.
+func (s *FileSource) SetTail(n int) {
+	s.tail = n
+}
+
+// SetTimeRange limits output to lines whose timestamp (parsed from the line
+// itself) falls within r, and stamps entries with that time. Lines without a
+// timestamp, such as stack trace continuations, inherit the timestamp of the
+// line before them.
+func (s *FileSource) SetTimeRange(r TimeRange) {
+	s.bounds = r
+}
+
+// SetCheckpoint makes the source resume each file where c says a previous
+// session stopped (instead of the tail setting), and record in c how far it
+// reads. c is saved every second and when reading ends.
+func (s *FileSource) SetCheckpoint(c *Checkpoint) {
+	s.checkpoint = c
+}
+
 // Name returns the source identifier.
 func (s *FileSource) Name() string {
 	return fmt.Sprintf("file:%s", s.path)
 }
 
-// Start opens the file and returns a channel of log entries.
+// Start opens the file(s) and returns a channel of log entries.
 func (s *FileSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
-	f, err := os.Open(s.path)
+	if !isGlob(s.path) {
+		f, err := openLogFile(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("open file %s: %w", s.path, err)
+		}
+		if err := s.seekStart(f, s.path, true); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("tail file %s: %w", s.path, err)
+		}
+
+		var notifier *fileNotifier
+		if s.follow {
+			notifier = newFileNotifier(ctx)
+		}
+
+		ch := make(chan entry.LogEntry, 256)
+		stop := s.saveCheckpoint(ctx)
+		go func() {
+			defer close(ch)
+			defer stop()
+			s.readFile(ctx, f, s.path, notifier, ch)
+		}()
+		return ch, nil
+	}
+
+	matches, err := filepath.Glob(s.path)
 	if err != nil {
-		return nil, fmt.Errorf("open file %s: %w", s.path, err)
+		return nil, fmt.Errorf("invalid glob %s: %w", s.path, err)
+	}
+	if len(matches) == 0 && !s.follow {
+		return nil, fmt.Errorf("no files match %s", s.path)
+	}
+
+	var notifier *fileNotifier
+	if s.follow {
+		notifier = newFileNotifier(ctx)
 	}
 
 	ch := make(chan entry.LogEntry, 256)
+	var wg sync.WaitGroup
+	seen := make(map[string]bool)
+
+	// Files present at startup honour the tail setting; files that appear
+	// later are new and read from the beginning.
+	startFile := func(path string, initial bool) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+
+		f, err := openLogFile(path)
+		if err != nil {
+			return
+		}
+		if err := s.seekStart(f, path, initial); err != nil {
+			f.Close()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.readFile(ctx, f, path, notifier, ch)
+		}()
+	}
+
+	for _, m := range matches {
+		startFile(m, true)
+	}
 
+	stop := s.saveCheckpoint(ctx)
 	go func() {
 		defer close(ch)
-		defer f.Close()
+		defer stop()
 
-		scanner := bufio.NewScanner(f)
-		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-		for {
-			for scanner.Scan() {
+		// Keep expanding the glob so files created later are tailed too.
+		if s.follow {
+			ticker := time.NewTicker(globRescanInterval)
+			defer ticker.Stop()
+		loop:
+			for {
 				select {
 				case <-ctx.Done():
-					return
-				default:
-				}
-
-				raw := scanner.Bytes()
-				rawCopy := make([]byte, len(raw))
-				copy(rawCopy, raw)
-
-				ch <- entry.LogEntry{
-					Timestamp: time.Now(),
-					Stream:    "file",
-					Source:    s.Name(),
-					Message:   scanner.Text(),
-					Raw:       rawCopy,
-					Seq:       s.seq.Add(1),
+					break loop
+				case <-ticker.C:
+					matches, _ := filepath.Glob(s.path)
+					for _, m := range matches {
+						startFile(m, false)
+					}
 				}
 			}
+		}
 
-			if !s.follow {
-				return
-			}
+		wg.Wait()
+	}()
+
+	return ch, nil
+}
+
+// seekStart positions f where reading path starts: where the checkpoint
+// says the last session stopped, else (for files present at startup) at the
+// last s.tail lines.
+func (s *FileSource) seekStart(f *openedFile, path string, initial bool) error {
+	if s.checkpoint != nil && !f.compressed {
+		resumed, err := s.checkpoint.resume(path, f)
+		if err != nil || resumed {
+			return err
+		}
+	}
+	if !initial {
+		return nil
+	}
+	return s.seekTail(f)
+}
+
+// saveCheckpoint saves the checkpoint, if any, every second until ctx is done
+// or the returned function is called, which saves it a last time.
+func (s *FileSource) saveCheckpoint(ctx context.Context) func() {
+	if s.checkpoint == nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go s.checkpoint.saveEvery(ctx, checkpointInterval)
+	return func() {
+		cancel()
+		_ = s.checkpoint.Save()
+	}
+}
+
+// seekTail positions f at its last s.tail lines.
+func (s *FileSource) seekTail(f *openedFile) error {
+	if s.tail < 0 {
+		return nil
+	}
+	if f.r != f.f {
+		// Decompressed streams cannot seek; keep only the last lines.
+		r, err := tailReader(f.r, s.tail)
+		if err != nil {
+			return err
+		}
+		f.r = r
+		return nil
+	}
+	off, err := tailOffset(f.f, s.tail)
+	if err != nil {
+		return err
+	}
+	_, err = f.f.Seek(off, io.SeekStart)
+	return err
+}
+
+// readFile scans f line by line, sending entries tagged with its path.
+// It closes f when the file is exhausted (or ctx is cancelled when following).
+// While following, it sleeps until notifier reports a write, or polls if the
+// file cannot be watched. Compressed files are read once even when following.
+func (s *FileSource) readFile(ctx context.Context, f *openedFile, path string, notifier *fileNotifier, ch chan<- entry.LogEntry) {
+	defer f.Close()
+
+	name := "file:" + path
+	follow := s.follow && !f.compressed
+	var wake <-chan struct{}
+	if follow {
+		wake = notifier.subscribe(path)
+	}
+
+	// With a checkpoint, count the bytes each line took, line ending
+	// included, to record how far the file has been read.
+	track := s.checkpoint != nil && !f.compressed
+	var offset int64
+	var advance int
+	if track {
+		offset, _ = f.f.Seek(0, io.SeekCurrent)
+	}
+	split := func(data []byte, atEOF bool) (int, []byte, error) {
+		n, token, err := bufio.ScanLines(data, atEOF)
+		advance = n
+		return n, token, err
+	}
+	mark := func() {
+		offset += int64(advance)
+		if track {
+			s.checkpoint.set(path, f, offset)
+		}
+	}
 
-			// Poll for new data when following.
+	scanner := bufio.NewScanner(f.r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	scanner.Split(split)
+
+	var lineTime time.Time
+	for {
+		for scanner.Scan() {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(100 * time.Millisecond):
-				// Reset scanner error state and continue reading.
-				scanner = bufio.NewScanner(f)
-				scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+			default:
+			}
+
+			if !s.bounds.IsZero() {
+				if ts, ok := parseLineTime(scanner.Text()); ok {
+					lineTime = ts
+				}
+				if lineTime.IsZero() {
+					if !s.bounds.Since.IsZero() {
+						mark()
+						continue
+					}
+				} else if !s.bounds.Contains(lineTime) {
+					mark()
+					continue
+				}
+			}
+
+			raw := scanner.Bytes()
+			rawCopy := make([]byte, len(raw))
+			copy(rawCopy, raw)
+
+			ts := lineTime
+			if ts.IsZero() {
+				ts = time.Now()
+			}
+
+			ch <- entry.LogEntry{
+				Timestamp: ts,
+				Stream:    "file",
+				Source:    name,
+				Message:   scanner.Text(),
+				Raw:       rawCopy,
+				Seq:       s.seq.Add(1),
 			}
+			mark()
 		}
-	}()
 
-	return ch, nil
+		if !follow {
+			return
+		}
+
+		// Wait for new data when following.
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-time.After(notifier.interval(wake)):
+		}
+
+		// Reset scanner error state and continue reading.
+		scanner = bufio.NewScanner(f.r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		scanner.Split(split)
+	}
+}
+
+// isGlob reports whether path contains shell glob metacharacters.
+func isGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
 }
@@ -0,0 +1,336 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/msgpack"
+)
+
+// ForwardSource implements the receiving side of the Fluentd forward protocol
+// (msgpack over TCP), so fluent-bit / fluentd agents can point their `forward`
+// output at lx. Message, Forward, PackedForward and CompressedPackedForward
+// modes are supported; chunk acknowledgements are sent when requested.
+type ForwardSource struct {
+	addr string
+	seq  atomic.Uint64
+}
+
+// NewForwardSource creates a forward protocol listener on addr (e.g. ":24224").
+func NewForwardSource(addr string) *ForwardSource {
+	return &ForwardSource{addr: addr}
+}
+
+// Name returns the source identifier.
+func (s *ForwardSource) Name() string {
+	return fmt.Sprintf("forward:%s", s.addr)
+}
+
+// Start binds the listener and returns a channel of log entries.
+func (s *ForwardSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("forward listen %s: %w", s.addr, err)
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		conns := make(map[net.Conn]struct{})
+
+		go func() {
+			<-ctx.Done()
+			ln.Close()
+			mu.Lock()
+			for c := range conns {
+				c.Close()
+			}
+			mu.Unlock()
+		}()
+
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				break
+			}
+			mu.Lock()
+			conns[conn] = struct{}{}
+			mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.serveConn(ctx, conn, ch)
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				conn.Close()
+			}()
+		}
+
+		wg.Wait()
+		close(ch)
+	}()
+
+	return ch, nil
+}
+
+// serveConn decodes forward protocol messages from one connection.
+func (s *ForwardSource) serveConn(ctx context.Context, conn net.Conn, ch chan<- entry.LogEntry) {
+	dec := msgpack.NewDecoder(conn)
+	remote := conn.RemoteAddr().String()
+
+	for {
+		v, err := dec.Decode()
+		if err != nil {
+			return
+		}
+		msg, ok := v.([]interface{})
+		if !ok || len(msg) < 2 {
+			continue
+		}
+		tag, _ := msg[0].(string)
+
+		var option map[string]interface{}
+		switch payload := msg[1].(type) {
+		case []interface{}:
+			// Forward mode: [tag, [[time, record], ...], option?]
+			for _, ev := range payload {
+				pair, ok := ev.([]interface{})
+				if ok && len(pair) >= 2 {
+					if !s.emit(ctx, ch, tag, pair[0], pair[1], remote) {
+						return
+					}
+				}
+			}
+			option = optionAt(msg, 2)
+
+		case string, []byte:
+			// PackedForward mode: [tag, <msgpack stream of [time, record]>, option?]
+			option = optionAt(msg, 2)
+			data := toBytes(payload)
+			if c, _ := option["compressed"].(string); c == "gzip" {
+				zr, err := gzip.NewReader(bytes.NewReader(data))
+				if err != nil {
+					continue
+				}
+				// Bound the inflated size like the decoder bounds a message.
+				data, err = io.ReadAll(io.LimitReader(zr, msgpack.DefaultMaxSize+1))
+				if err != nil || len(data) > msgpack.DefaultMaxSize {
+					continue
+				}
+			}
+			inner := msgpack.NewDecoder(bytes.NewReader(data))
+			for {
+				ev, err := inner.Decode()
+				if err != nil {
+					break
+				}
+				pair, ok := ev.([]interface{})
+				if ok && len(pair) >= 2 {
+					if !s.emit(ctx, ch, tag, pair[0], pair[1], remote) {
+						return
+					}
+				}
+			}
+
+		default:
+			// Message mode: [tag, time, record, option?]
+			if len(msg) < 3 {
+				continue
+			}
+			if !s.emit(ctx, ch, tag, msg[1], msg[2], remote) {
+				return
+			}
+			option = optionAt(msg, 3)
+		}
+
+		// At-least-once delivery: acknowledge the chunk id if one was sent.
+		if chunk, ok := option["chunk"].(string); ok {
+			ack := msgpack.AppendString(msgpack.AppendString(msgpack.AppendMapHeader(nil, 1), "ack"), chunk)
+			if _, err := conn.Write(ack); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// emit converts one forward event into an entry. Returns false if ctx was cancelled.
+func (s *ForwardSource) emit(ctx context.Context, ch chan<- entry.LogEntry, tag string, rawTime, rawRecord interface{}, remote string) bool {
+	record, _ := rawRecord.(map[string]interface{})
+	e := recordToEntry(record)
+	e.Timestamp = forwardTime(rawTime)
+	e.Stream = "forward"
+	e.Source = s.Name()
+	e.Fields["tag"] = tag
+	e.Fields["remote_addr"] = remote
+	e.Seq = s.seq.Add(1)
+
+	select {
+	case ch <- e:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recordToEntry maps a structured record (fluent, GELF-style, ...) onto a LogEntry:
+// log/message/msg becomes Message, level/severity sets Level, and all other scalar
+// values are copied into Fields.
+func recordToEntry(record map[string]interface{}) entry.LogEntry {
+	e := entry.LogEntry{Fields: make(map[string]string, len(record)+2)}
+
+	msgKey := ""
+	for _, k := range []string{"log", "message", "msg"} {
+		if v, ok := record[k]; ok {
+			if str := stringify(v); str != "" {
+				e.Message = str
+				msgKey = k
+				break
+			}
+		}
+	}
+	if msgKey == "" && len(record) > 0 {
+		raw, _ := json.Marshal(jsonSafe(record))
+		e.Message = string(raw)
+	}
+
+	for k, v := range record {
+		if k == msgKey {
+			continue
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			raw, _ := json.Marshal(jsonSafe(v))
+			e.Fields[k] = string(raw)
+		default:
+			e.Fields[k] = stringify(v)
+		}
+	}
+
+	for _, k := range []string{"level", "severity"} {
+		if lv, ok := e.Fields[k]; ok {
+			if l := entry.ParseLevel(lv); l != entry.LevelUnknown {
+				e.Level = l
+				break
+			}
+		}
+	}
+
+	e.Raw = []byte(e.Message)
+	return e
+}
+
+// payloadToEntry maps a message payload from a queue or stream: a JSON object
+// goes through recordToEntry, anything else becomes the message verbatim.
+func payloadToEntry(data []byte) entry.LogEntry {
+	data = bytes.TrimRight(data, "\r\n")
+	if len(data) > 0 && data[0] == '{' {
+		var record map[string]interface{}
+		if err := json.Unmarshal(data, &record); err == nil {
+			return recordToEntry(record)
+		}
+	}
+	return entry.LogEntry{
+		Message: string(data),
+		Fields:  make(map[string]string),
+		Raw:     data,
+	}
+}
+
+// forwardTime decodes a forward protocol timestamp: integer seconds, float
+// seconds, or the EventTime extension (type 0: uint32 seconds + uint32 nanos).
+func forwardTime(v interface{}) time.Time {
+	switch t := v.(type) {
+	case int64:
+		return time.Unix(t, 0)
+	case uint64:
+		return time.Unix(int64(t), 0)
+	case float64:
+		sec := int64(t)
+		return time.Unix(sec, int64((t-float64(sec))*1e9))
+	case msgpack.Ext:
+		if t.Type == 0 && len(t.Data) == 8 {
+			sec := binary.BigEndian.Uint32(t.Data[:4])
+			nsec := binary.BigEndian.Uint32(t.Data[4:])
+			return time.Unix(int64(sec), int64(nsec))
+		}
+	}
+	return time.Now()
+}
+
+// optionAt returns msg[i] as an option map, or nil.
+func optionAt(msg []interface{}, i int) map[string]interface{} {
+	if i < len(msg) {
+		if m, ok := msg[i].(map[string]interface{}); ok {
+			return m
+		}
+	}
+	return nil
+}
+
+// toBytes returns the raw bytes of a str or bin value.
+func toBytes(v interface{}) []byte {
+	switch b := v.(type) {
+	case []byte:
+		return b
+	case string:
+		return []byte(b)
+	}
+	return nil
+}
+
+// stringify renders a decoded scalar value as a string.
+func stringify(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// jsonSafe converts msgpack-decoded values into types encoding/json can marshal
+// readably ([]byte as string, Ext as its raw data).
+func jsonSafe(v interface{}) interface{} {
+	switch val := v.(type) {
+	case []byte:
+		return string(val)
+	case msgpack.Ext:
+		return val.Data
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, x := range val {
+			out[i] = jsonSafe(x)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, x := range val {
+			out[k] = jsonSafe(x)
+		}
+		return out
+	}
+	return v
+}
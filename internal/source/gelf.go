@@ -0,0 +1,120 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+// GELF UDP framing: a datagram is either a whole message, possibly gzip or
+// zlib compressed, or one chunk of a larger one:
+//
+//	0x1e 0x0f | message id (8 bytes) | sequence number | sequence count | data
+//
+// The chunks' data, joined in order, form the (possibly compressed) message.
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+const (
+	gelfChunkHeader = 12
+	gelfMaxChunks   = 128
+	gelfChunkTTL    = 5 * time.Second // as in Graylog
+	gelfMaxMessage  = 8 << 20         // decompressed size limit
+)
+
+// gelfChunks reassembles chunked GELF messages. Incomplete messages are
+// dropped after gelfChunkTTL.
+type gelfChunks struct {
+	mu      sync.Mutex
+	pending map[uint64]*gelfPartial
+}
+
+type gelfPartial struct {
+	first time.Time
+	parts [][]byte
+	have  int
+}
+
+func newGELFChunks() *gelfChunks {
+	return &gelfChunks{pending: make(map[uint64]*gelfPartial)}
+}
+
+// add takes one datagram and returns the decoded message once it is
+// complete, or nil.
+func (c *gelfChunks) add(d []byte) []byte {
+	if !bytes.HasPrefix(d, gelfChunkMagic) {
+		return gelfDecompress(d)
+	}
+	if len(d) < gelfChunkHeader {
+		return nil
+	}
+	id := binary.BigEndian.Uint64(d[2:10])
+	seq, count := int(d[10]), int(d[11])
+	if count == 0 || count > gelfMaxChunks || seq >= count {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, p := range c.pending {
+		if now.Sub(p.first) > gelfChunkTTL {
+			delete(c.pending, k)
+		}
+	}
+
+	p := c.pending[id]
+	if p == nil {
+		p = &gelfPartial{first: now, parts: make([][]byte, count)}
+		c.pending[id] = p
+	}
+	if len(p.parts) != count || p.parts[seq] != nil {
+		return nil
+	}
+	p.parts[seq] = append([]byte(nil), d[gelfChunkHeader:]...)
+	p.have++
+	if p.have < count {
+		return nil
+	}
+	delete(c.pending, id)
+	return gelfDecompress(bytes.Join(p.parts, nil))
+}
+
+// gelfDecompress inflates a gzip or zlib payload; anything else is returned
+// as is. Broken payloads yield nil.
+func gelfDecompress(d []byte) []byte {
+	var r io.ReadCloser
+	var err error
+	switch {
+	case bytes.HasPrefix(d, gzipMagic):
+		r, err = gzip.NewReader(bytes.NewReader(d))
+	case len(d) > 1 && d[0] == 0x78 && (uint16(d[0])<<8|uint16(d[1]))%31 == 0:
+		r, err = zlib.NewReader(bytes.NewReader(d))
+	default:
+		return d
+	}
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, gelfMaxMessage))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// splitNull is a bufio.SplitFunc for null-byte delimited GELF over TCP.
+func splitNull(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
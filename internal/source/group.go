@@ -0,0 +1,109 @@
+package source
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// grouper recognizes a multi-line block, such as a Go panic or a Python
+// traceback, in a stream of lines and builds the entry that replaces it.
+type grouper interface {
+	// start reports whether line begins a block.
+	start(line string) bool
+	// next reports whether line continues the block begun so far.
+	next(line string) bool
+	// finish builds the grouped entry from the block's first entry and lines.
+	finish(first entry.LogEntry, lines []string) entry.LogEntry
+}
+
+const (
+	groupFlushAfter = time.Second
+	groupMaxLines   = 10000
+)
+
+// groupEntries copies in to out, folding the blocks g recognizes into single
+// entries. A block ends at the first line that does not continue it, after
+// groupMaxLines lines, or when no line arrives for groupFlushAfter (for
+// followed sources). out is closed when in is.
+func groupEntries(ctx context.Context, in <-chan entry.LogEntry, out chan<- entry.LogEntry, g grouper) {
+	defer close(out)
+
+	var (
+		first entry.LogEntry
+		lines []string
+	)
+	timer := time.NewTimer(groupFlushAfter)
+	timer.Stop()
+
+	send := func(e entry.LogEntry) bool {
+		select {
+		case out <- e:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	flush := func() bool {
+		if lines == nil {
+			return true
+		}
+		timer.Stop()
+		e := g.finish(first, lines)
+		lines = nil
+		return send(e)
+	}
+
+	for {
+		select {
+		case e, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+			if lines != nil {
+				if len(lines) < groupMaxLines && g.next(e.Message) {
+					lines = append(lines, e.Message)
+					timer.Reset(groupFlushAfter)
+					continue
+				}
+				if !flush() {
+					return
+				}
+			}
+			if g.start(e.Message) {
+				first = e
+				lines = []string{e.Message}
+				timer.Reset(groupFlushAfter)
+				continue
+			}
+			if !send(e) {
+				return
+			}
+
+		case <-timer.C:
+			if !flush() {
+				return
+			}
+		}
+	}
+}
+
+// trimTrailingBlank drops blank lines from the end of a block.
+func trimTrailingBlank(lines []string) []string {
+	for len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// copyFields returns a copy of fields with room for n more.
+func copyFields(fields map[string]string, n int) map[string]string {
+	c := make(map[string]string, len(fields)+n)
+	for k, v := range fields {
+		c[k] = v
+	}
+	return c
+}
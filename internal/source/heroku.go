@@ -0,0 +1,190 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/parser"
+)
+
+// HerokuDrainSource is an HTTP(S) endpoint speaking Heroku's logplex drain
+// format, so `heroku drains:add https://host:port/` can point at lx. Each POST
+// carries octet-counted RFC 5424 syslog frames; the dyno name (web.1, router)
+// and its process type are parsed into Fields.
+type HerokuDrainSource struct {
+	addr     string
+	certFile string
+	keyFile  string
+	token    string // expected Logplex-Drain-Token; empty accepts any drain
+	seq      atomic.Uint64
+}
+
+// NewHerokuDrainSource creates a drain listening on addr. If certFile and
+// keyFile are set the endpoint serves HTTPS. If token is set, requests from
+// other drains are rejected.
+func NewHerokuDrainSource(addr, certFile, keyFile, token string) *HerokuDrainSource {
+	return &HerokuDrainSource{addr: addr, certFile: certFile, keyFile: keyFile, token: token}
+}
+
+// Name returns the source identifier.
+func (s *HerokuDrainSource) Name() string {
+	return fmt.Sprintf("heroku:%s", s.addr)
+}
+
+// Start binds the listener and serves until ctx is cancelled.
+func (s *HerokuDrainSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("heroku drain listen %s: %w", s.addr, err)
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+	srv := &http.Server{
+		Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handle(ctx, w, r, ch) }),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	go func() {
+		defer close(ch)
+		if s.certFile != "" {
+			_ = srv.ServeTLS(ln, s.certFile, s.keyFile)
+		} else {
+			_ = srv.Serve(ln)
+		}
+		<-shutdownDone
+	}()
+
+	return ch, nil
+}
+
+// handle splits one logplex POST into frames and emits an entry per frame.
+func (s *HerokuDrainSource) handle(ctx context.Context, w http.ResponseWriter, r *http.Request, ch chan<- entry.LogEntry) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	drainToken := r.Header.Get("Logplex-Drain-Token")
+	if s.token != "" && drainToken != s.token {
+		http.Error(w, "unknown drain", http.StatusForbidden)
+		return
+	}
+
+	br := bufio.NewReader(io.LimitReader(r.Body, maxHTTPBody))
+	for {
+		frame, err := readLogplexFrame(br)
+		if err != nil {
+			break
+		}
+		e, ok := parseHerokuSyslog(frame)
+		if !ok {
+			continue
+		}
+		e.Source = s.Name()
+		if drainToken != "" {
+			e.Fields["drain_token"] = drainToken
+		}
+		e.Seq = s.seq.Add(1)
+
+		select {
+		case ch <- e:
+		case <-ctx.Done():
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readLogplexFrame reads one octet-counted frame: "<length> <message>".
+func readLogplexFrame(r *bufio.Reader) (string, error) {
+	lenStr, err := r.ReadString(' ')
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+	if err != nil || n <= 0 || n > maxHTTPBody {
+		return "", fmt.Errorf("logplex: bad frame length %q", lenStr)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// parseHerokuSyslog parses a logplex syslog line:
+//
+//	<190>1 2024-01-02T03:04:05.678+00:00 host app web.1 - message
+//
+// Heroku sends no structured data, so the MSGID is followed directly by the message.
+func parseHerokuSyslog(frame string) (entry.LogEntry, bool) {
+	frame = strings.TrimRight(frame, "\r\n")
+	if !strings.HasPrefix(frame, "<") {
+		return entry.LogEntry{}, false
+	}
+	end := strings.IndexByte(frame, '>')
+	if end < 0 {
+		return entry.LogEntry{}, false
+	}
+	pri, err := strconv.Atoi(frame[1:end])
+	if err != nil {
+		return entry.LogEntry{}, false
+	}
+
+	// VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID MSG
+	parts := strings.SplitN(frame[end+1:], " ", 7)
+	if len(parts) < 6 {
+		return entry.LogEntry{}, false
+	}
+	msg := ""
+	if len(parts) == 7 {
+		msg = parts[6]
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		ts = time.Now()
+	}
+	dyno := parts[4]
+	processType, _, _ := strings.Cut(dyno, ".")
+
+	fields := map[string]string{
+		"hostname":     parts[2],
+		"app":          parts[3], // "app" for dyno output, "heroku" for platform logs
+		"dyno":         dyno,
+		"process_type": processType,
+	}
+
+	e := entry.LogEntry{
+		Timestamp: ts,
+		Stream:    "heroku",
+		Message:   msg,
+		Fields:    fields,
+		Raw:       []byte(msg),
+	}
+	// Heroku tags almost everything local7.info, so only trust
+	// priorities that say something more specific.
+	if lv := parser.SyslogLevel(pri & 7); pri&7 != 6 {
+		e.Level = lv
+	}
+	return e, true
+}
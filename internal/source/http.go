@@ -0,0 +1,226 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// maxHTTPBody caps a single POST payload.
+const maxHTTPBody = 32 << 20
+
+// HTTPSource runs an HTTP server that accepts POSTed log payloads, turning lx
+// into an ad-hoc receiver for Vector, Fluent Bit's http output, or curl.
+// Bodies may be newline-delimited text/JSON or a JSON array of strings/objects.
+type HTTPSource struct {
+	addr string
+	seq  atomic.Uint64
+}
+
+// NewHTTPSource creates a source listening on addr (e.g. ":8080").
+func NewHTTPSource(addr string) *HTTPSource {
+	return &HTTPSource{addr: addr}
+}
+
+// Name returns the source identifier.
+func (s *HTTPSource) Name() string {
+	return fmt.Sprintf("http:%s", s.addr)
+}
+
+// Start binds the listener and serves until ctx is cancelled. If serving
+// fails first, the source ends with an error line instead of hanging.
+func (s *HTTPSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("http listen %s: %w", s.addr, err)
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+	srv := &http.Server{
+		Handler:           http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { s.handle(ctx, w, r, ch) }),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve(ln) }()
+
+	go func() {
+		defer close(ch)
+		var err error
+		select {
+		case <-ctx.Done():
+		case err = <-serveErr:
+		}
+		// Shutdown waits for handlers to drain so none of them sends on a
+		// closed channel.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			msg := fmt.Sprintf("http server on %s failed: %v", s.addr, err)
+			select {
+			case ch <- entry.LogEntry{
+				Timestamp: time.Now(),
+				Stream:    "lx",
+				Level:     entry.LevelError,
+				Source:    s.Name(),
+				Message:   msg,
+				Raw:       []byte(msg),
+				Seq:       s.seq.Add(1),
+			}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// handle decodes one request body and emits an entry per log line/record.
+func (s *HTTPSource) handle(ctx context.Context, w http.ResponseWriter, r *http.Request, ch chan<- entry.LogEntry) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		w.Header().Set("Allow", "POST, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, status, err := readHTTPBody(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	remote := r.RemoteAddr
+	emit := func(msg string, fields map[string]string) bool {
+		if fields == nil {
+			fields = make(map[string]string, 1)
+		}
+		fields["remote_addr"] = remote
+		select {
+		case ch <- entry.LogEntry{
+			Timestamp: time.Now(),
+			Stream:    "http",
+			Source:    s.Name(),
+			Message:   msg,
+			Fields:    fields,
+			Raw:       []byte(msg),
+			Seq:       s.seq.Add(1),
+		}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var records []json.RawMessage
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			http.Error(w, "invalid JSON array: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, rec := range records {
+			msg, fields := decodeRecord(rec)
+			if !emit(msg, fields) {
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !emit(line, nil) {
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readHTTPBody reads the request body, inflating it if it is gzip-encoded
+// (as Fluent Bit and Vector send it with compression on). A body over
+// maxHTTPBody, before or after inflating, is refused whole rather than cut,
+// so no record is split; the error comes with the status to answer.
+func readHTTPBody(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	var in io.Reader = http.MaxBytesReader(w, r.Body, maxHTTPBody)
+	switch enc := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); enc {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer zr.Close()
+		in = io.LimitReader(zr, maxHTTPBody+1)
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported Content-Encoding %q (want gzip or none)", enc)
+	}
+
+	body, err := io.ReadAll(in)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || len(body) > maxHTTPBody {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("body over %d bytes", maxHTTPBody)
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	return body, 0, nil
+}
+
+// messageKeys are checked in order to find the log line inside a JSON record.
+var messageKeys = []string{"message", "msg", "log"}
+
+// decodeRecord turns one element of a JSON array payload into a message and fields.
+// Strings become the message verbatim; objects use their message/msg/log key as the
+// message and keep remaining scalar values as fields.
+func decodeRecord(raw json.RawMessage) (string, map[string]string) {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str, nil
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return string(raw), nil
+	}
+
+	fields := make(map[string]string, len(obj))
+	msg := ""
+	for _, k := range messageKeys {
+		if v, ok := obj[k].(string); ok {
+			msg = v
+			delete(obj, k)
+			break
+		}
+	}
+	if msg == "" {
+		msg = string(raw)
+	}
+	for k, v := range obj {
+		switch val := v.(type) {
+		case string:
+			fields[k] = val
+		case float64, bool:
+			fields[k] = fmt.Sprint(val)
+		}
+	}
+	return msg, fields
+}
@@ -0,0 +1,131 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/parser"
+)
+
+// JournalSource reads a systemd unit's logs via `journalctl -u <unit> -o json`.
+type JournalSource struct {
+	unit   string
+	follow bool
+	seq    atomic.Uint64
+}
+
+// NewJournalSource creates a source for the given systemd unit (e.g. nginx.service).
+func NewJournalSource(unit string, follow bool) *JournalSource {
+	return &JournalSource{unit: unit, follow: follow}
+}
+
+// Name returns the source identifier.
+func (s *JournalSource) Name() string {
+	return fmt.Sprintf("unit:%s", s.unit)
+}
+
+// Start executes journalctl and returns a channel of log entries.
+func (s *JournalSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	args := []string{"-u", s.unit, "-o", "json", "--no-pager"}
+	if s.follow {
+		args = append(args, "-f")
+	}
+
+	cmd := exec.CommandContext(ctx, "journalctl", args...)
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("journalctl start: %w (is systemd available?)", err)
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+		defer func() { _ = cmd.Wait() }()
+
+		scanner := bufio.NewScanner(stdoutPipe)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			var rec map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				continue
+			}
+			ch <- s.toEntry(rec)
+		}
+	}()
+
+	return ch, nil
+}
+
+// toEntry converts a journalctl JSON record into a LogEntry.
+func (s *JournalSource) toEntry(rec map[string]interface{}) entry.LogEntry {
+	msg := journalString(rec["MESSAGE"])
+
+	ts := time.Now()
+	if us, err := strconv.ParseInt(journalString(rec["__REALTIME_TIMESTAMP"]), 10, 64); err == nil {
+		ts = time.UnixMicro(us)
+	}
+
+	fields := make(map[string]string, 4)
+	for _, k := range []string{"_PID", "_HOSTNAME", "SYSLOG_IDENTIFIER"} {
+		if v := journalString(rec[k]); v != "" {
+			fields[k] = v
+		}
+	}
+
+	level := entry.LevelUnknown
+	if p := journalString(rec["PRIORITY"]); p != "" {
+		fields["priority"] = p
+		if n, err := strconv.Atoi(p); err == nil {
+			level = parser.SyslogLevel(n)
+		}
+	}
+
+	return entry.LogEntry{
+		Timestamp: ts,
+		Stream:    "journal",
+		Level:     level,
+		Source:    s.Name(),
+		Message:   msg,
+		Fields:    fields,
+		Raw:       []byte(msg),
+		Seq:       s.seq.Add(1),
+	}
+}
+
+// journalString renders a journal field. journalctl encodes non-UTF-8 values
+// as arrays of byte values.
+func journalString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		b := make([]byte, 0, len(val))
+		for _, x := range val {
+			if n, ok := x.(float64); ok {
+				b = append(b, byte(n))
+			}
+		}
+		return string(b)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(val)
+	}
+}
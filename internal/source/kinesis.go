@@ -0,0 +1,283 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+const (
+	// kinesisPollInterval is the delay between GetRecords calls on a shard
+	// that is caught up (Kinesis allows 5 calls/s per shard).
+	kinesisPollInterval = time.Second
+	// kinesisShardRescan is how often the shard list is refreshed to pick up
+	// child shards created by resharding.
+	kinesisShardRescan = 30 * time.Second
+)
+
+// KinesisSource consumes a Kinesis data stream, reading every shard
+// concurrently. When a shard is closed by resharding its children are
+// picked up and read from their start. When following, only records added
+// after startup are read; otherwise the retained records are read and the
+// source exits once every shard is caught up. Calls go through the aws CLI
+// so its configured credentials and region are reused.
+type KinesisSource struct {
+	stream string
+	follow bool
+	seq    atomic.Uint64
+}
+
+// NewKinesisSource creates a source for the named stream.
+func NewKinesisSource(stream string, follow bool) *KinesisSource {
+	return &KinesisSource{stream: stream, follow: follow}
+}
+
+// Name returns the source identifier.
+func (s *KinesisSource) Name() string {
+	return "kinesis:" + s.stream
+}
+
+// kinesisShard is the subset of ListShards output lx uses.
+type kinesisShard struct {
+	ShardID             string `json:"ShardId"`
+	ParentShardID       string `json:"ParentShardId"`
+	AdjacentParentShard string `json:"AdjacentParentShardId"`
+	SequenceNumberRange struct {
+		EndingSequenceNumber string `json:"EndingSequenceNumber"`
+	} `json:"SequenceNumberRange"`
+}
+
+// kinesisRecord is one record from GetRecords; Data is base64 in the CLI's JSON.
+type kinesisRecord struct {
+	Data                        []byte  `json:"Data"`
+	PartitionKey                string  `json:"PartitionKey"`
+	SequenceNumber              string  `json:"SequenceNumber"`
+	ApproximateArrivalTimestamp float64 `json:"ApproximateArrivalTimestamp"`
+}
+
+// Start lists the shards (surfacing CLI/auth errors immediately) and starts a
+// reader per shard.
+func (s *KinesisSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	shards, err := s.listShards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			started  = make(map[string]bool)
+			finished = make(map[string]bool)
+			changed  = make(chan struct{}, 1)
+		)
+
+		// parentsDone reports whether a shard's parents have been drained (or
+		// were never being read), so its records are delivered in order.
+		parentsDone := func(sh kinesisShard) bool {
+			for _, p := range []string{sh.ParentShardID, sh.AdjacentParentShard} {
+				if p != "" && started[p] && !finished[p] {
+					return false
+				}
+			}
+			return true
+		}
+
+		launch := func(shards []kinesisShard, initial bool) int {
+			mu.Lock()
+			defer mu.Unlock()
+			n := 0
+			for _, sh := range shards {
+				if started[sh.ShardID] || !parentsDone(sh) {
+					continue
+				}
+				closed := sh.SequenceNumberRange.EndingSequenceNumber != ""
+				iterType := "TRIM_HORIZON"
+				if initial && s.follow {
+					if closed {
+						continue // nothing new will ever arrive
+					}
+					iterType = "LATEST"
+				}
+				started[sh.ShardID] = true
+				n++
+
+				wg.Add(1)
+				go func(id string) {
+					defer wg.Done()
+					s.readShard(ctx, id, iterType, ch)
+					mu.Lock()
+					finished[id] = true
+					mu.Unlock()
+					select {
+					case changed <- struct{}{}:
+					default:
+					}
+				}(sh.ShardID)
+			}
+			return n
+		}
+
+		launch(shards, true)
+
+		if s.follow {
+			ticker := time.NewTicker(kinesisShardRescan)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					wg.Wait()
+					return
+				case <-ticker.C:
+				case <-changed:
+				}
+				if shards, err := s.listShards(ctx); err == nil {
+					launch(shards, false)
+				}
+			}
+		}
+
+		// Not following: once the running shards are drained, start the
+		// children that were waiting on them, until none are left.
+		for {
+			wg.Wait()
+			if ctx.Err() != nil || launch(shards, false) == 0 {
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// readShard follows one shard until it is closed (resharded), caught up when
+// not following, or ctx is cancelled.
+func (s *KinesisSource) readShard(ctx context.Context, shardID, iterType string, ch chan<- entry.LogEntry) {
+	var it struct {
+		ShardIterator string `json:"ShardIterator"`
+	}
+	if err := s.aws(ctx, &it, "get-shard-iterator", "--stream-name", s.stream,
+		"--shard-id", shardID, "--shard-iterator-type", iterType); err != nil {
+		return
+	}
+	iterator := it.ShardIterator
+
+	for iterator != "" {
+		var out struct {
+			Records            []kinesisRecord `json:"Records"`
+			NextShardIterator  *string         `json:"NextShardIterator"`
+			MillisBehindLatest int64           `json:"MillisBehindLatest"`
+		}
+		if err := s.aws(ctx, &out, "get-records", "--shard-iterator", iterator, "--limit", "1000"); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// Throttling or a transient failure: back off and retry.
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * kinesisPollInterval):
+			}
+			continue
+		}
+
+		for i := range out.Records {
+			for _, e := range s.toEntries(&out.Records[i], shardID) {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+
+		if out.NextShardIterator == nil {
+			return // shard closed by resharding
+		}
+		iterator = *out.NextShardIterator
+
+		if len(out.Records) == 0 && out.MillisBehindLatest == 0 {
+			if !s.follow {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(kinesisPollInterval):
+			}
+		}
+	}
+}
+
+// toEntries converts a record. Gzipped payloads (e.g. CloudWatch Logs
+// subscriptions) are decompressed, and multi-line payloads yield one entry per line.
+func (s *KinesisSource) toEntries(r *kinesisRecord, shardID string) []entry.LogEntry {
+	data := r.Data
+	if bytes.HasPrefix(data, gzipMagic) {
+		if zr, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+			if plain, err := io.ReadAll(zr); err == nil {
+				data = plain
+			}
+		}
+	}
+
+	ts := time.Now()
+	if r.ApproximateArrivalTimestamp > 0 {
+		sec := int64(r.ApproximateArrivalTimestamp)
+		ts = time.Unix(sec, int64((r.ApproximateArrivalTimestamp-float64(sec))*1e9))
+	}
+
+	var entries []entry.LogEntry
+	for _, line := range bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n")) {
+		e := payloadToEntry(line)
+		e.Timestamp = ts
+		e.Stream = "kinesis"
+		e.Source = s.Name()
+		e.Fields["shard_id"] = shardID
+		e.Fields["partition_key"] = r.PartitionKey
+		e.Fields["sequence_number"] = r.SequenceNumber
+		e.Seq = s.seq.Add(1)
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// listShards returns every shard of the stream, following pagination.
+func (s *KinesisSource) listShards(ctx context.Context) ([]kinesisShard, error) {
+	var out struct {
+		Shards []kinesisShard `json:"Shards"`
+	}
+	if err := s.aws(ctx, &out, "list-shards", "--stream-name", s.stream); err != nil {
+		return nil, err
+	}
+	return out.Shards, nil
+}
+
+// aws runs `aws kinesis <args>` and decodes its JSON output into v.
+func (s *KinesisSource) aws(ctx context.Context, v interface{}, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "aws", append(append([]string{"kinesis"}, args...), "--output", "json")...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("aws kinesis %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	if err := json.Unmarshal(out, v); err != nil {
+		return fmt.Errorf("aws kinesis %s: decode: %w", args[0], err)
+	}
+	return nil
+}
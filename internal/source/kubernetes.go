@@ -0,0 +1,331 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// KubernetesSource tails every container of the pods matching a label
+// selector. When following it keeps watching the selector: pods that appear
+// are tailed as soon as their containers run, restarted containers are
+// re-attached, and deleted pods are dropped, each change announced with a
+// synthetic entry on the "lx" stream. It drives kubectl, so the current
+// kubeconfig context and credentials are used.
+type KubernetesSource struct {
+	selector  string
+	namespace string // empty = kubectl's current namespace
+	opts      ContainerOptions
+	seq       atomic.Uint64
+}
+
+// NewKubernetesSource creates a source for pods matching selector (e.g. "app=api").
+func NewKubernetesSource(selector, namespace string, opts ContainerOptions) *KubernetesSource {
+	return &KubernetesSource{selector: selector, namespace: namespace, opts: opts}
+}
+
+// Name returns the source identifier.
+func (s *KubernetesSource) Name() string {
+	return "k8s:" + s.selector
+}
+
+// k8sPod is the subset of a Pod object lx inspects.
+type k8sPod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName string `json:"nodeName"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string `json:"phase"`
+		ContainerStatuses []struct {
+			Name         string `json:"name"`
+			RestartCount int    `json:"restartCount"`
+			State        struct {
+				Running *struct{} `json:"running"`
+			} `json:"state"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// k8sWatchEvent is one line of `kubectl get --watch --output-watch-events`.
+type k8sWatchEvent struct {
+	Type   string `json:"type"`
+	Object k8sPod `json:"object"`
+}
+
+// k8sTail tracks one running `kubectl logs` for a pod container.
+type k8sTail struct {
+	cancel context.CancelFunc
+	last   time.Time // timestamp of the last delivered line
+}
+
+// k8sWatchRetry is the delay before re-running an ended pod watch. While
+// the watch keeps failing the delay doubles, up to k8sWatchMaxRetry.
+const (
+	k8sWatchRetry    = 2 * time.Second
+	k8sWatchMaxRetry = time.Minute
+)
+
+// Start launches `kubectl get pods` (watching when following) and tails
+// containers as they become ready.
+func (s *KubernetesSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	events, watchErr, err := s.watch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+
+		type ended struct {
+			key  string
+			last time.Time
+		}
+		var wg sync.WaitGroup
+		done := make(chan ended)
+		tails := make(map[string]*k8sTail) // key: namespace/pod/container
+		known := make(map[string]bool)     // pods announced so far
+		resume := make(map[string]time.Time)
+
+		startTail := func(p *k8sPod, container string) {
+			key := p.Metadata.Namespace + "/" + p.Metadata.Name + "/" + container
+			if tails[key] != nil {
+				return
+			}
+			tctx, cancel := context.WithCancel(ctx)
+			t := &k8sTail{cancel: cancel}
+			tails[key] = t
+
+			largs := []string{"logs", "--timestamps", "-n", p.Metadata.Namespace, p.Metadata.Name, "-c", container}
+			if s.opts.Follow {
+				largs = append(largs, "--follow")
+			}
+			since := resume[key]
+			switch {
+			case !since.IsZero():
+				largs = append(largs, "--since-time", since.Add(time.Nanosecond).Format(time.RFC3339Nano))
+			case !s.opts.Since.IsZero():
+				largs = append(largs, "--since-time", s.opts.Since.Format(time.RFC3339Nano))
+			}
+			if since.IsZero() && s.opts.Tail >= 0 {
+				largs = append(largs, "--tail", strconv.Itoa(s.opts.Tail))
+			}
+
+			name := "k8s:" + p.Metadata.Namespace + "/" + p.Metadata.Name
+			in, err := startTimestampedCommand(tctx, "kubectl", largs, name, &s.seq)
+			if err != nil {
+				cancel()
+				delete(tails, key)
+				return
+			}
+
+			fields := map[string]string{
+				"namespace": p.Metadata.Namespace,
+				"pod":       p.Metadata.Name,
+				"container": container,
+			}
+			if p.Spec.NodeName != "" {
+				fields["node"] = p.Spec.NodeName
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var last time.Time
+				for e := range in {
+					if !s.opts.Until.IsZero() && e.Timestamp.After(s.opts.Until) {
+						continue
+					}
+					e.Fields = make(map[string]string, len(fields))
+					for k, v := range fields {
+						e.Fields[k] = v
+					}
+					last = e.Timestamp
+					select {
+					case ch <- e:
+					case <-ctx.Done():
+					}
+				}
+				select {
+				case done <- ended{key: key, last: last}:
+				case <-ctx.Done():
+				}
+			}()
+		}
+
+		notice := func(p *k8sPod, level entry.Level, msg string) {
+			e := entry.LogEntry{
+				Timestamp: time.Now(),
+				Stream:    "lx",
+				Level:     level,
+				Source:    s.Name(),
+				Message:   msg,
+				Raw:       []byte(msg),
+				Seq:       s.seq.Add(1),
+			}
+			if p != nil {
+				e.Fields = map[string]string{"namespace": p.Metadata.Namespace, "pod": p.Metadata.Name}
+			}
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		}
+
+		var rewatch <-chan time.Time // set while waiting to re-run the watch
+		backoff := k8sWatchRetry
+
+		for events != nil || rewatch != nil || len(tails) > 0 {
+			select {
+			case <-ctx.Done():
+				for _, t := range tails {
+					t.cancel()
+				}
+				wg.Wait()
+				return
+
+			case ev, ok := <-events:
+				if !ok {
+					events = nil
+					err := <-watchErr
+					if !s.opts.Follow {
+						if err != nil {
+							notice(nil, entry.LevelWarn, err.Error())
+						}
+						continue
+					}
+					// Server-side watch timeouts end kubectl; start over,
+					// backing off while it fails (API server down, expired
+					// credentials, ...).
+					delay := k8sWatchRetry
+					if err != nil {
+						delay = backoff
+						backoff = min(backoff*2, k8sWatchMaxRetry)
+						notice(nil, entry.LevelWarn, fmt.Sprintf("%v, retrying in %s", err, delay))
+					}
+					rewatch = time.After(delay)
+					continue
+				}
+				backoff = k8sWatchRetry
+				p := &ev.Object
+				podKey := p.Metadata.Namespace + "/" + p.Metadata.Name
+
+				if ev.Type == "DELETED" {
+					if known[podKey] {
+						delete(known, podKey)
+						notice(p, entry.LevelInfo, fmt.Sprintf("pod %s removed", p.Metadata.Name))
+					}
+					for key, t := range tails {
+						if strings.HasPrefix(key, podKey+"/") {
+							t.cancel()
+						}
+					}
+					continue
+				}
+
+				if !known[podKey] && s.opts.Follow {
+					known[podKey] = true
+					notice(p, entry.LevelInfo, fmt.Sprintf("pod %s added (%s, node %s)", p.Metadata.Name, strings.ToLower(p.Status.Phase), p.Spec.NodeName))
+				}
+				for _, cs := range p.Status.ContainerStatuses {
+					if cs.State.Running != nil {
+						startTail(p, cs.Name)
+					}
+				}
+
+			case <-rewatch:
+				rewatch = nil
+				var err error
+				events, watchErr, err = s.watch(ctx)
+				if err != nil {
+					notice(nil, entry.LevelWarn, fmt.Sprintf("%v, retrying in %s", err, backoff))
+					rewatch = time.After(backoff)
+					backoff = min(backoff*2, k8sWatchMaxRetry)
+				}
+
+			case e := <-done:
+				if !e.last.IsZero() {
+					resume[e.key] = e.last
+				}
+				delete(tails, e.key)
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// watch runs `kubectl get pods` for the selector, watching when following,
+// and decodes pod events. The error channel yields kubectl's exit status once
+// the event channel is closed.
+func (s *KubernetesSource) watch(ctx context.Context) (<-chan k8sWatchEvent, <-chan error, error) {
+	args := []string{"get", "pods", "-l", s.selector, "-o", "json"}
+	if s.namespace != "" {
+		args = append(args, "-n", s.namespace)
+	}
+	if s.opts.Follow {
+		args = append(args, "--watch", "--output-watch-events")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "kubectl", args...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("kubectl stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("kubectl get pods: %w (is kubectl installed?)", err)
+	}
+
+	events := make(chan k8sWatchEvent)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(events)
+		dec := json.NewDecoder(stdout)
+		if s.opts.Follow {
+			for {
+				var ev k8sWatchEvent
+				if err := dec.Decode(&ev); err != nil {
+					break
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+				}
+			}
+		} else {
+			var list struct {
+				Items []k8sPod `json:"items"`
+			}
+			if err := dec.Decode(&list); err == nil {
+				for _, p := range list.Items {
+					select {
+					case events <- k8sWatchEvent{Type: "ADDED", Object: p}:
+					case <-ctx.Done():
+					}
+				}
+			}
+		}
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			errc <- fmt.Errorf("kubectl get pods: %w: %s", err, strings.TrimSpace(stderr.String()))
+		} else {
+			errc <- nil
+		}
+	}()
+	return events, errc, nil
+}
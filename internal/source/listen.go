@@ -0,0 +1,183 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// maxDatagram is the largest UDP payload accepted.
+const maxDatagram = 64 * 1024
+
+// ListenSource accepts raw log lines over TCP connections or UDP datagrams,
+// netcat-style. Each line becomes an entry with the sender in Fields["remote_addr"].
+type ListenSource struct {
+	network string // "tcp" or "udp"
+	addr    string
+	seq     atomic.Uint64
+
+	gelf *gelfChunks // GELF framing instead of lines
+}
+
+// NewListenSource creates a line listener. network must be "tcp" or "udp".
+func NewListenSource(network, addr string) *ListenSource {
+	return &ListenSource{network: network, addr: addr}
+}
+
+// NewGELFListenSource creates a listener using GELF framing: each UDP
+// datagram (chunked and/or gzip/zlib compressed) or null-terminated TCP
+// frame becomes one entry holding the GELF JSON.
+func NewGELFListenSource(network, addr string) *ListenSource {
+	return &ListenSource{network: network, addr: addr, gelf: newGELFChunks()}
+}
+
+// Name returns the source identifier.
+func (s *ListenSource) Name() string {
+	return fmt.Sprintf("%s:%s", s.network, s.addr)
+}
+
+// Start binds the listener and returns a channel of log entries.
+// The listener is closed when ctx is cancelled.
+func (s *ListenSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	switch s.network {
+	case "tcp":
+		ln, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			return nil, fmt.Errorf("tcp listen %s: %w", s.addr, err)
+		}
+		ch := make(chan entry.LogEntry, 256)
+		go s.serveTCP(ctx, ln, ch)
+		return ch, nil
+
+	case "udp":
+		conn, err := net.ListenPacket("udp", s.addr)
+		if err != nil {
+			return nil, fmt.Errorf("udp listen %s: %w", s.addr, err)
+		}
+		ch := make(chan entry.LogEntry, 256)
+		go s.serveUDP(ctx, conn, ch)
+		return ch, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported network %q (want tcp or udp)", s.network)
+	}
+}
+
+// serveTCP accepts connections and reads lines from each until ctx is cancelled.
+func (s *ListenSource) serveTCP(ctx context.Context, ln net.Listener, ch chan<- entry.LogEntry) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	conns := make(map[net.Conn]struct{})
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		mu.Lock()
+		for c := range conns {
+			c.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			break
+		}
+		// A connection accepted after the cancellation swept conns would
+		// otherwise stay open and block wg.Wait.
+		mu.Lock()
+		if ctx.Err() != nil {
+			mu.Unlock()
+			conn.Close()
+			break
+		}
+		conns[conn] = struct{}{}
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				delete(conns, conn)
+				mu.Unlock()
+				conn.Close()
+			}()
+
+			remote := conn.RemoteAddr().String()
+			scanner := bufio.NewScanner(conn)
+			scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+			if s.gelf != nil {
+				scanner.Split(splitNull)
+			}
+			for scanner.Scan() {
+				if !s.emit(ctx, ch, scanner.Bytes(), remote) {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(ch)
+}
+
+// serveUDP reads datagrams, splitting each into lines, until ctx is cancelled.
+func (s *ListenSource) serveUDP(ctx context.Context, conn net.PacketConn, ch chan<- entry.LogEntry) {
+	defer close(ch)
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, maxDatagram)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		remote := addr.String()
+		if s.gelf != nil {
+			if msg := s.gelf.add(buf[:n]); msg != nil && !s.emit(ctx, ch, msg, remote) {
+				return
+			}
+			continue
+		}
+		for _, line := range bytes.Split(bytes.TrimRight(buf[:n], "\n"), []byte("\n")) {
+			if !s.emit(ctx, ch, line, remote) {
+				return
+			}
+		}
+	}
+}
+
+// emit sends one line as an entry. Returns false if ctx was cancelled.
+func (s *ListenSource) emit(ctx context.Context, ch chan<- entry.LogEntry, line []byte, remote string) bool {
+	line = bytes.TrimRight(line, "\r")
+	rawCopy := make([]byte, len(line))
+	copy(rawCopy, line)
+
+	select {
+	case ch <- entry.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    s.network,
+		Source:    s.Name(),
+		Message:   string(rawCopy),
+		Fields:    map[string]string{"remote_addr": remote},
+		Raw:       rawCopy,
+		Seq:       s.seq.Add(1),
+	}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,196 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// MultiSource starts several sources and merges their entries into one channel.
+// Each entry keeps its originating Source, and Seq is renumbered so it stays
+// monotonic across the merged stream.
+//
+// With a zero window entries are emitted in arrival order. With a positive
+// window they are interleaved by Timestamp: an entry is released as soon as
+// every still-open input has something queued (so finite, time-ordered inputs
+// merge exactly), or once it has waited longer than the window.
+type MultiSource struct {
+	sources []Source
+	window  time.Duration
+	seq     atomic.Uint64
+}
+
+// NewMultiSource combines sources, ordering by timestamp within window (0 disables ordering).
+func NewMultiSource(window time.Duration, sources ...Source) *MultiSource {
+	return &MultiSource{sources: sources, window: window}
+}
+
+// Name returns the source identifier.
+func (m *MultiSource) Name() string {
+	names := make([]string, len(m.sources))
+	for i, s := range m.sources {
+		names[i] = s.Name()
+	}
+	return strings.Join(names, " + ")
+}
+
+// Sources returns the underlying sources.
+func (m *MultiSource) Sources() []Source {
+	return m.sources
+}
+
+// Start starts every source and merges their channels. If any source fails to
+// start, the already-started ones are stopped and the error returned.
+func (m *MultiSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	chans := make([]<-chan entry.LogEntry, 0, len(m.sources))
+	for _, s := range m.sources {
+		ch, err := s.Start(ctx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("%s: %w", s.Name(), err)
+		}
+		chans = append(chans, ch)
+	}
+
+	out := make(chan entry.LogEntry, 256)
+	if m.window <= 0 {
+		go m.fanIn(ctx, cancel, chans, out)
+	} else {
+		go m.ordered(ctx, cancel, chans, out)
+	}
+	return out, nil
+}
+
+// fanIn forwards entries in arrival order.
+func (m *MultiSource) fanIn(ctx context.Context, cancel context.CancelFunc, chans []<-chan entry.LogEntry, out chan<- entry.LogEntry) {
+	defer cancel()
+	defer close(out)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex // keeps Seq assignment and send order consistent
+	wg.Add(len(chans))
+	for _, ch := range chans {
+		go func(ch <-chan entry.LogEntry) {
+			defer wg.Done()
+			for e := range ch {
+				mu.Lock()
+				e.Seq = m.seq.Add(1)
+				select {
+				case out <- e:
+				case <-ctx.Done():
+				}
+				mu.Unlock()
+			}
+		}(ch)
+	}
+	wg.Wait()
+}
+
+// taggedEntry carries an entry (or a close notification) from input idx.
+type taggedEntry struct {
+	idx    int
+	e      entry.LogEntry
+	closed bool
+}
+
+// pendingEntry is an entry waiting in the reorder buffer.
+type pendingEntry struct {
+	e       entry.LogEntry
+	arrived time.Time
+}
+
+// ordered merges inputs by timestamp using per-input queues.
+func (m *MultiSource) ordered(ctx context.Context, cancel context.CancelFunc, chans []<-chan entry.LogEntry, out chan<- entry.LogEntry) {
+	defer cancel()
+	defer close(out)
+
+	in := make(chan taggedEntry, 256)
+	for i, ch := range chans {
+		go func(i int, ch <-chan entry.LogEntry) {
+			for e := range ch {
+				select {
+				case in <- taggedEntry{idx: i, e: e}:
+				case <-ctx.Done():
+				}
+			}
+			select {
+			case in <- taggedEntry{idx: i, closed: true}:
+			case <-ctx.Done():
+			}
+		}(i, ch)
+	}
+
+	queues := make([][]pendingEntry, len(chans))
+	open := make([]bool, len(chans))
+	for i := range open {
+		open[i] = true
+	}
+	remaining := len(chans)
+
+	// release emits every entry that is safe to emit. When force is set
+	// (all inputs closed) everything left is flushed in timestamp order.
+	release := func(now time.Time, force bool) bool {
+		for {
+			minIdx := -1
+			ready := true
+			for i, q := range queues {
+				if len(q) == 0 {
+					if open[i] {
+						ready = false
+					}
+					continue
+				}
+				if minIdx < 0 || q[0].e.Timestamp.Before(queues[minIdx][0].e.Timestamp) {
+					minIdx = i
+				}
+			}
+			if minIdx < 0 {
+				return true
+			}
+			head := queues[minIdx][0]
+			if !force && !ready && now.Sub(head.arrived) < m.window {
+				return true
+			}
+
+			queues[minIdx] = queues[minIdx][1:]
+			head.e.Seq = m.seq.Add(1)
+			select {
+			case out <- head.e:
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+
+	ticker := time.NewTicker(max(m.window/4, time.Millisecond))
+	defer ticker.Stop()
+
+	for remaining > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case t := <-in:
+			if t.closed {
+				open[t.idx] = false
+				remaining--
+			} else {
+				queues[t.idx] = append(queues[t.idx], pendingEntry{e: t.e, arrived: time.Now()})
+			}
+			if !release(time.Now(), false) {
+				return
+			}
+		case now := <-ticker.C:
+			if !release(now, false) {
+				return
+			}
+		}
+	}
+	release(time.Now(), true)
+}
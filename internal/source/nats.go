@@ -0,0 +1,183 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// NATSSource subscribes to NATS subjects (wildcards `*` and `>` allowed) and
+// emits each message as an entry with its subject in Fields. It implements the
+// plain-text NATS client protocol directly; TLS-only servers are not supported.
+type NATSSource struct {
+	url      string // nats://[user:pass@|token@]host:port
+	subjects []string
+	seq      atomic.Uint64
+}
+
+// NewNATSSource creates a source subscribed to the given subjects.
+func NewNATSSource(url string, subjects []string) *NATSSource {
+	return &NATSSource{url: url, subjects: subjects}
+}
+
+// Name returns the source identifier.
+func (s *NATSSource) Name() string {
+	return "nats:" + strings.Join(s.subjects, ",")
+}
+
+// natsInfo is the subset of the server INFO message lx inspects.
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// natsConnect is the CONNECT payload sent after INFO.
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+// Start connects, subscribes and returns a channel of entries.
+func (s *NATSSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	raw := s.url
+	if !strings.Contains(raw, "://") {
+		raw = "nats://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL %q: %w", s.url, err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host += ":4222"
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("nats connect %s: %w", host, err)
+	}
+	r := bufio.NewReader(conn)
+
+	// The server greets with INFO.
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return nil, fmt.Errorf("nats %s: unexpected greeting %q", host, strings.TrimSpace(line))
+	}
+	var info natsInfo
+	_ = json.Unmarshal([]byte(strings.TrimSpace(line[5:])), &info)
+	if info.TLSRequired {
+		conn.Close()
+		return nil, fmt.Errorf("nats %s: server requires TLS, which is not supported", host)
+	}
+
+	cc := natsConnect{Name: "lx", Lang: "go", Version: "0.2.0"}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			cc.User, cc.Pass = u.User.Username(), pass
+		} else {
+			cc.Token = u.User.Username()
+		}
+	}
+	payload, _ := json.Marshal(cc)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CONNECT %s\r\n", payload)
+	for i, subj := range s.subjects {
+		fmt.Fprintf(&sb, "SUB %s %d\r\n", subj, i+1)
+	}
+	sb.WriteString("PING\r\n")
+	if _, err := io.WriteString(conn, sb.String()); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats subscribe: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+		defer conn.Close()
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			switch {
+			case line == "PING":
+				if _, err := io.WriteString(conn, "PONG\r\n"); err != nil {
+					return
+				}
+			case strings.HasPrefix(line, "-ERR"):
+				msg := "nats: " + strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'")
+				select {
+				case ch <- entry.LogEntry{
+					Timestamp: time.Now(),
+					Stream:    "lx",
+					Level:     entry.LevelWarn,
+					Source:    s.Name(),
+					Message:   msg,
+					Raw:       []byte(msg),
+					Seq:       s.seq.Add(1),
+				}:
+				case <-ctx.Done():
+				}
+				return
+			case strings.HasPrefix(line, "MSG "):
+				// MSG <subject> <sid> [reply-to] <#bytes>
+				parts := strings.Fields(line)
+				if len(parts) < 4 {
+					return
+				}
+				size, err := strconv.Atoi(parts[len(parts)-1])
+				if err != nil {
+					return
+				}
+				buf := make([]byte, size+2)
+				if _, err := io.ReadFull(r, buf); err != nil {
+					return
+				}
+				msg := buf[:size]
+
+				select {
+				case ch <- entry.LogEntry{
+					Timestamp: time.Now(),
+					Stream:    "nats",
+					Source:    s.Name(),
+					Message:   string(msg),
+					Fields:    map[string]string{"subject": parts[1]},
+					Raw:       msg,
+					Seq:       s.seq.Add(1),
+				}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
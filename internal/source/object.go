@@ -0,0 +1,227 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// awsLsLine matches `aws s3 ls --recursive` output: "DATE TIME SIZE KEY".
+var awsLsLine = regexp.MustCompile(`^\S+\s+\S+\s+\d+\s+(.+)$`)
+
+// ObjectSource reads archived logs from object storage (s3:// or gs://).
+// The key may contain a glob (e.g. s3://bucket/app/2024-06-*); every matching
+// object is streamed in key order, decompressed if needed, and fed through the
+// normal pipeline. Listing and downloads go through the aws / gcloud CLIs so
+// their configured credentials are reused.
+type ObjectSource struct {
+	url    string
+	scheme string // "s3" or "gs"
+	bucket string
+	key    string // key or key glob within the bucket
+	seq    atomic.Uint64
+}
+
+// NewObjectSource parses an s3:// or gs:// URL into an object source.
+func NewObjectSource(url string) (*ObjectSource, error) {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok || (scheme != "s3" && scheme != "gs") {
+		return nil, fmt.Errorf("object URL must start with s3:// or gs://: %q", url)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("object URL has no bucket: %q", url)
+	}
+	return &ObjectSource{url: url, scheme: scheme, bucket: bucket, key: key}, nil
+}
+
+// Name returns the source identifier.
+func (s *ObjectSource) Name() string {
+	return s.url
+}
+
+// Start lists the matching objects and streams them one after another. An
+// object that cannot be read is reported on the "lx" stream and skipped.
+func (s *ObjectSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	keys, err := s.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no objects match %s", s.url)
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+		for _, key := range keys {
+			err := s.readObject(ctx, key, ch)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				s.notice(ctx, ch, err.Error())
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// list returns the object keys matching s.key, sorted.
+func (s *ObjectSource) list(ctx context.Context) ([]string, error) {
+	if !isGlob(s.key) {
+		return []string{s.key}, nil
+	}
+
+	// List everything under the literal prefix, then match the glob locally.
+	prefix := s.key[:strings.IndexAny(s.key, "*?[")]
+
+	var cmd *exec.Cmd
+	switch s.scheme {
+	case "s3":
+		cmd = exec.CommandContext(ctx, "aws", "s3", "ls", "--recursive", fmt.Sprintf("s3://%s/%s", s.bucket, prefix))
+	default:
+		cmd = exec.CommandContext(ctx, "gcloud", "storage", "ls", fmt.Sprintf("gs://%s/%s**", s.bucket, prefix))
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w: %s", s.url, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var keys []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var key string
+		switch s.scheme {
+		case "s3":
+			m := awsLsLine.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			key = m[1]
+		default:
+			if strings.HasSuffix(line, "/") || strings.HasSuffix(line, ":") {
+				continue // directory markers
+			}
+			key = strings.TrimPrefix(line, fmt.Sprintf("gs://%s/", s.bucket))
+		}
+		if ok, _ := path.Match(s.key, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// readObject streams a single object through the decompressor and emits its
+// lines. It fails if the download does, e.g. when access is denied.
+func (s *ObjectSource) readObject(ctx context.Context, key string, ch chan<- entry.LogEntry) error {
+	objURL := fmt.Sprintf("%s://%s/%s", s.scheme, s.bucket, key)
+
+	dctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var cmd *exec.Cmd
+	switch s.scheme {
+	case "s3":
+		cmd = exec.CommandContext(dctx, "aws", "s3", "cp", objURL, "-")
+	default:
+		cmd = exec.CommandContext(dctx, "gcloud", "storage", "cat", objURL)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("object stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("download %s: %w", objURL, err)
+	}
+
+	err = s.readLines(ctx, objURL, stdout, ch)
+	if err != nil {
+		cancel() // stop the download; its exit status is moot
+	}
+	if werr := cmd.Wait(); err == nil && werr != nil {
+		err = fmt.Errorf("download %s: %w: %s", objURL, werr, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+// readLines decompresses r if needed and emits its lines. Lines are stamped
+// with the time they start with, or that of the last line that had one, so
+// --since/--until select by when the lines were logged.
+func (s *ObjectSource) readLines(ctx context.Context, objURL string, r io.Reader, ch chan<- entry.LogEntry) error {
+	r, release, err := decompressStream(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", objURL, err)
+	}
+	defer release()
+
+	var lineTime time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		raw := scanner.Bytes()
+		rawCopy := make([]byte, len(raw))
+		copy(rawCopy, raw)
+
+		if ts, ok := parseLineTime(scanner.Text()); ok {
+			lineTime = ts
+		}
+		ts := lineTime
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+
+		select {
+		case ch <- entry.LogEntry{
+			Timestamp: ts,
+			Stream:    s.scheme,
+			Source:    objURL,
+			Message:   string(rawCopy),
+			Raw:       rawCopy,
+			Seq:       s.seq.Add(1),
+		}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", objURL, err)
+	}
+	return nil
+}
+
+// notice emits a synthetic lx entry, e.g. an object that could not be read.
+func (s *ObjectSource) notice(ctx context.Context, ch chan<- entry.LogEntry, msg string) {
+	select {
+	case ch <- entry.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    "lx",
+		Level:     entry.LevelWarn,
+		Source:    s.Name(),
+		Message:   msg,
+		Raw:       []byte(msg),
+		Seq:       s.seq.Add(1),
+	}:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,100 @@
+package source
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// PanicGroupSource wraps a source and folds Go runtime panics and goroutine
+// dumps into single FATAL entries, so filters and alerts on "panic" see the
+// whole trace rather than its first line:
+//
+//	panic: runtime error: invalid memory address or nil pointer dereference
+//	[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4553f6]
+//
+//	goroutine 1 [running]:
+//	main.main()
+//		/app/main.go:12 +0x16
+//	exit status 2
+//
+// The grouped entry keeps the first line's timestamp and source and gets
+// Fields "panic" (the panic or fatal error message), "signal" and
+// "goroutines" (the number of goroutines in the dump) where present. A dump
+// ends at the first line that does not look like part of it, or when no line
+// arrives for a second (for followed sources).
+type PanicGroupSource struct {
+	src Source
+}
+
+var (
+	// panicStart matches the first line of a panic or dump.
+	panicStart = regexp.MustCompile(`^(?:panic: |fatal error: |SIG[A-Z]+: |goroutine \d+ \[[^\]]*\]:$)`)
+
+	// panicLine matches the other lines a dump is made of: goroutine
+	// headers, frames and their file lines, signal and register lines.
+	panicLine = regexp.MustCompile(`^(?:$|\s|goroutine \d+ \[|\[signal |panic: |fatal error: |created by |exit status \d+|\.\.\.|PC=|[a-z0-9]{2,3} +0x[0-9a-f]+$|[\w./*()\[\]{}-]+\(.*\)$)`)
+
+	panicSignal = regexp.MustCompile(`^\[signal (SIG[A-Z]+)|^(SIG[A-Z]+): `)
+)
+
+// NewPanicGroupSource wraps src.
+func NewPanicGroupSource(src Source) *PanicGroupSource {
+	return &PanicGroupSource{src: src}
+}
+
+// Name returns the wrapped source's name.
+func (s *PanicGroupSource) Name() string {
+	return s.src.Name()
+}
+
+// Start starts the wrapped source and groups its entries.
+func (s *PanicGroupSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	in, err := s.src.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan entry.LogEntry, 256)
+	go groupEntries(ctx, in, out, panicGrouper{})
+	return out, nil
+}
+
+// panicGrouper recognizes Go panics and goroutine dumps.
+type panicGrouper struct{}
+
+func (panicGrouper) start(line string) bool { return panicStart.MatchString(line) }
+func (panicGrouper) next(line string) bool  { return panicLine.MatchString(line) }
+
+// finish builds the grouped entry from the dump's lines.
+func (panicGrouper) finish(first entry.LogEntry, lines []string) entry.LogEntry {
+	lines = trimTrailingBlank(lines)
+
+	e := first
+	e.Message = strings.Join(lines, "\n")
+	e.Raw = []byte(e.Message)
+	e.Level = entry.LevelFatal
+	fields := copyFields(first.Fields, 3)
+
+	goroutines := 0
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "goroutine "):
+			goroutines++
+		case fields["panic"] == "" && strings.HasPrefix(l, "panic: "):
+			fields["panic"] = strings.TrimSuffix(strings.TrimPrefix(l, "panic: "), " [recovered]")
+		case fields["panic"] == "" && strings.HasPrefix(l, "fatal error: "):
+			fields["panic"] = strings.TrimPrefix(l, "fatal error: ")
+		}
+		if m := panicSignal.FindStringSubmatch(l); m != nil && fields["signal"] == "" {
+			fields["signal"] = m[1] + m[2]
+		}
+	}
+	if goroutines > 0 {
+		fields["goroutines"] = strconv.Itoa(goroutines)
+	}
+	e.Fields = fields
+	return e
+}
@@ -0,0 +1,76 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/docker/docker/client"
+)
+
+// PodmanSource reads logs from a Podman container via `podman logs --timestamps`.
+type PodmanSource struct {
+	container string
+	opts      ContainerOptions
+	seq       atomic.Uint64
+}
+
+// NewPodmanSource creates a source that reads from a Podman container's logs.
+func NewPodmanSource(container string, opts ContainerOptions) *PodmanSource {
+	return &PodmanSource{
+		container: container,
+		opts:      opts,
+	}
+}
+
+// Name returns the source identifier.
+func (s *PodmanSource) Name() string {
+	return fmt.Sprintf("podman:%s", s.container)
+}
+
+// Start executes `podman logs` and returns a channel of log entries.
+func (s *PodmanSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	args := []string{"logs", "--timestamps"}
+	if s.opts.Follow {
+		args = append(args, "--follow")
+	}
+	if !s.opts.Since.IsZero() {
+		args = append(args, "--since", s.opts.Since.Format(time.RFC3339Nano))
+	}
+	if !s.opts.Until.IsZero() {
+		args = append(args, "--until", s.opts.Until.Format(time.RFC3339Nano))
+	}
+	if s.opts.Tail >= 0 {
+		args = append(args, "--tail", strconv.Itoa(s.opts.Tail))
+	}
+	args = append(args, s.container)
+
+	return startTimestampedCommand(ctx, "podman", args, s.Name(), &s.seq)
+}
+
+// NewContainerSource picks the container runtime automatically: the Docker
+// daemon if its socket is reachable, otherwise the podman CLI if installed.
+func NewContainerSource(container string, opts ContainerOptions) (Source, error) {
+	if dockerAvailable() {
+		return NewDockerSource(container, opts), nil
+	}
+	if _, err := exec.LookPath("podman"); err == nil {
+		return NewPodmanSource(container, opts), nil
+	}
+	return nil, fmt.Errorf("no container runtime found: Docker socket unreachable and podman not installed")
+}
+
+// dockerAvailable reports whether a Docker daemon endpoint is configured or present.
+func dockerAvailable() bool {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return true
+	}
+	_, err := os.Stat(strings.TrimPrefix(client.DefaultDockerHost, "unix://"))
+	return err == nil
+}
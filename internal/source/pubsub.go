@@ -0,0 +1,168 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+const (
+	// pubsubPollInterval is the wait after a pull that returned nothing.
+	pubsubPollInterval = 2 * time.Second
+	// pubsubBatch is the maximum number of messages per pull.
+	pubsubBatch = 100
+)
+
+// PubSubSource pulls messages from a Google Cloud Pub/Sub subscription.
+// Messages are acknowledged only after they have been handed to the pipeline,
+// so anything pulled but not delivered (e.g. on shutdown) is redelivered.
+// Message attributes are copied into Fields. It relies on the gcloud CLI
+// for authentication.
+type PubSubSource struct {
+	subscription string
+	project      string
+	follow       bool
+	seq          atomic.Uint64
+}
+
+// NewPubSubSource creates a source for the given subscription. When follow is
+// false the source exits once a pull returns no messages.
+func NewPubSubSource(subscription, project string, follow bool) *PubSubSource {
+	return &PubSubSource{subscription: subscription, project: project, follow: follow}
+}
+
+// Name returns the source identifier.
+func (s *PubSubSource) Name() string {
+	return "pubsub:" + s.subscription
+}
+
+// pubsubMessage is one received message as printed by `gcloud pubsub subscriptions pull`.
+type pubsubMessage struct {
+	AckID   string `json:"ackId"`
+	Message struct {
+		Data        []byte            `json:"data"`
+		Attributes  map[string]string `json:"attributes"`
+		MessageID   string            `json:"messageId"`
+		PublishTime time.Time         `json:"publishTime"`
+		OrderingKey string            `json:"orderingKey"`
+	} `json:"message"`
+}
+
+// Start performs the first pull synchronously (so auth and subscription
+// errors surface immediately) and keeps pulling in the background.
+func (s *PubSubSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	batch, err := s.pull(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			acks := make([]string, 0, len(batch))
+			for i := range batch {
+				select {
+				case ch <- s.toEntry(&batch[i]):
+					acks = append(acks, batch[i].AckID)
+				case <-ctx.Done():
+					s.ack(context.Background(), acks)
+					return
+				}
+			}
+			if err := s.ack(ctx, acks); err != nil && ctx.Err() != nil {
+				return
+			}
+
+			if len(batch) == 0 {
+				if !s.follow {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(pubsubPollInterval):
+				}
+			}
+
+			batch, err = s.pull(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				batch = nil // transient failure: retry next tick
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// pull fetches up to pubsubBatch messages without acknowledging them.
+func (s *PubSubSource) pull(ctx context.Context) ([]pubsubMessage, error) {
+	out, err := s.gcloud(ctx, "pull", s.subscription, "--format=json", fmt.Sprintf("--limit=%d", pubsubBatch))
+	if err != nil {
+		return nil, err
+	}
+	var msgs []pubsubMessage
+	if err := json.Unmarshal(out, &msgs); err != nil {
+		return nil, fmt.Errorf("gcloud pubsub pull: decode: %w", err)
+	}
+	return msgs, nil
+}
+
+// ack acknowledges delivered messages.
+func (s *PubSubSource) ack(ctx context.Context, ackIDs []string) error {
+	if len(ackIDs) == 0 {
+		return nil
+	}
+	_, err := s.gcloud(ctx, "ack", s.subscription, "--ack-ids="+strings.Join(ackIDs, ","))
+	return err
+}
+
+// gcloud runs `gcloud pubsub subscriptions <verb> ...`.
+func (s *PubSubSource) gcloud(ctx context.Context, verb string, args ...string) ([]byte, error) {
+	full := append([]string{"pubsub", "subscriptions", verb}, args...)
+	if s.project != "" {
+		full = append(full, "--project="+s.project)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "gcloud", full...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gcloud pubsub %s: %w: %s", verb, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// toEntry converts a message; attributes become Fields.
+func (s *PubSubSource) toEntry(m *pubsubMessage) entry.LogEntry {
+	e := payloadToEntry(m.Message.Data)
+	for k, v := range m.Message.Attributes {
+		e.Fields[k] = v
+	}
+	e.Fields["message_id"] = m.Message.MessageID
+	if m.Message.OrderingKey != "" {
+		e.Fields["ordering_key"] = m.Message.OrderingKey
+	}
+
+	e.Timestamp = m.Message.PublishTime
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	e.Stream = "pubsub"
+	e.Source = s.Name()
+	e.Seq = s.seq.Add(1)
+	return e
+}
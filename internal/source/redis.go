@@ -0,0 +1,369 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// RedisSource emits messages published to Redis pub/sub channels or appended to
+// a Redis Stream. Channel names containing glob characters use PSUBSCRIBE.
+// It speaks RESP directly, so no Redis client library is required.
+type RedisSource struct {
+	addr     string // host:port or redis://[:password@]host:port[/db]
+	channels []string
+	stream   string
+	follow   bool
+	seq      atomic.Uint64
+}
+
+// NewRedisPubSubSource creates a source subscribed to the given channels.
+func NewRedisPubSubSource(addr string, channels []string) *RedisSource {
+	return &RedisSource{addr: addr, channels: channels, follow: true}
+}
+
+// NewRedisStreamSource creates a source reading the given stream key. When
+// follow is false it reads the existing entries and exits; otherwise it blocks
+// for new entries only.
+func NewRedisStreamSource(addr, stream string, follow bool) *RedisSource {
+	return &RedisSource{addr: addr, stream: stream, follow: follow}
+}
+
+// Name returns the source identifier.
+func (s *RedisSource) Name() string {
+	if s.stream != "" {
+		return "redis-stream:" + s.stream
+	}
+	return "redis:" + strings.Join(s.channels, ",")
+}
+
+// Start connects, subscribes or starts reading the stream, and returns a channel of entries.
+func (s *RedisSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	conn, err := dialRedis(ctx, s.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unblock pending reads on cancellation.
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	ch := make(chan entry.LogEntry, 256)
+
+	if s.stream != "" {
+		go func() {
+			defer close(ch)
+			defer conn.Close()
+			s.readStream(ctx, conn, ch)
+		}()
+		return ch, nil
+	}
+
+	var plain, patterns []string
+	for _, c := range s.channels {
+		if strings.ContainsAny(c, "*?[") {
+			patterns = append(patterns, c)
+		} else {
+			plain = append(plain, c)
+		}
+	}
+	if len(plain) > 0 {
+		if err := conn.send(append([]string{"SUBSCRIBE"}, plain...)...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if len(patterns) > 0 {
+		if err := conn.send(append([]string{"PSUBSCRIBE"}, patterns...)...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	go func() {
+		defer close(ch)
+		defer conn.Close()
+
+		for {
+			v, err := conn.read()
+			if err != nil {
+				s.lost(ctx, ch, err)
+				return
+			}
+			msg, ok := v.([]interface{})
+			if !ok || len(msg) < 3 {
+				continue
+			}
+
+			var channel, payload string
+			switch stringify(msg[0]) {
+			case "message":
+				channel, payload = stringify(msg[1]), stringify(msg[2])
+			case "pmessage":
+				if len(msg) < 4 {
+					continue
+				}
+				channel, payload = stringify(msg[2]), stringify(msg[3])
+			default:
+				continue // subscribe confirmations
+			}
+
+			select {
+			case ch <- entry.LogEntry{
+				Timestamp: time.Now(),
+				Stream:    "redis",
+				Source:    s.Name(),
+				Message:   payload,
+				Fields:    map[string]string{"channel": channel},
+				Raw:       []byte(payload),
+				Seq:       s.seq.Add(1),
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// readStream reads stream entries with XREAD, blocking for new ones when following.
+func (s *RedisSource) readStream(ctx context.Context, conn *respConn, ch chan<- entry.LogEntry) {
+	lastID := "0"
+	if s.follow {
+		// Start after the current last entry. XREAD's "$" would have to be
+		// resolved again on every call, skipping entries added in between.
+		id, err := s.lastStreamID(conn)
+		if err != nil {
+			s.lost(ctx, ch, err)
+			return
+		}
+		lastID = id
+	}
+
+	for {
+		args := []string{"XREAD", "COUNT", "500"}
+		if s.follow {
+			args = append(args, "BLOCK", "5000")
+		}
+		args = append(args, "STREAMS", s.stream, lastID)
+		if err := conn.send(args...); err != nil {
+			s.lost(ctx, ch, err)
+			return
+		}
+		v, err := conn.read()
+		if err != nil {
+			s.lost(ctx, ch, err)
+			return
+		}
+
+		n := 0
+		streams, _ := v.([]interface{})
+		for _, st := range streams {
+			pair, ok := st.([]interface{})
+			if !ok || len(pair) < 2 {
+				continue
+			}
+			items, _ := pair[1].([]interface{})
+			for _, it := range items {
+				item, ok := it.([]interface{})
+				if !ok || len(item) < 2 {
+					continue
+				}
+				id := stringify(item[0])
+				kv, _ := item[1].([]interface{})
+				record := make(map[string]interface{}, len(kv)/2)
+				for i := 0; i+1 < len(kv); i += 2 {
+					record[stringify(kv[i])] = stringify(kv[i+1])
+				}
+
+				e := recordToEntry(record)
+				e.Timestamp = streamIDTime(id)
+				e.Stream = "redis"
+				e.Source = s.Name()
+				e.Fields["stream_id"] = id
+				e.Seq = s.seq.Add(1)
+
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+				lastID = id
+				n++
+			}
+		}
+
+		if n == 0 && !s.follow {
+			return
+		}
+	}
+}
+
+// lastStreamID returns the ID of the newest entry in the stream, or "0-0" if
+// it is empty or does not exist yet.
+func (s *RedisSource) lastStreamID(conn *respConn) (string, error) {
+	if err := conn.send("XREVRANGE", s.stream, "+", "-", "COUNT", "1"); err != nil {
+		return "", err
+	}
+	v, err := conn.read()
+	if err != nil {
+		return "", err
+	}
+	items, _ := v.([]interface{})
+	if len(items) == 0 {
+		return "0-0", nil
+	}
+	if item, ok := items[0].([]interface{}); ok && len(item) > 0 {
+		return stringify(item[0]), nil
+	}
+	return "0-0", nil
+}
+
+// lost reports why the source stopped reading, unless it was cancelled.
+func (s *RedisSource) lost(ctx context.Context, ch chan<- entry.LogEntry, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	msg := err.Error()
+	if err == io.EOF {
+		msg = "redis closed the connection"
+	}
+	select {
+	case ch <- entry.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    "lx",
+		Level:     entry.LevelWarn,
+		Source:    s.Name(),
+		Message:   msg,
+		Raw:       []byte(msg),
+		Seq:       s.seq.Add(1),
+	}:
+	case <-ctx.Done():
+	}
+}
+
+// streamIDTime extracts the millisecond timestamp from a stream ID ("<ms>-<seq>").
+func streamIDTime(id string) time.Time {
+	ms, _, _ := strings.Cut(id, "-")
+	if v, err := strconv.ParseInt(ms, 10, 64); err == nil {
+		return time.UnixMilli(v)
+	}
+	return time.Now()
+}
+
+// respConn is a minimal RESP2 connection.
+type respConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// dialRedis connects and performs AUTH/SELECT from a redis:// URL if given.
+func dialRedis(ctx context.Context, addr string) (*respConn, error) {
+	host, password, db := addr, "", ""
+	if strings.HasPrefix(addr, "redis://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis URL %q: %w", addr, err)
+		}
+		host = u.Host
+		if u.User != nil {
+			password, _ = u.User.Password()
+			if password == "" {
+				password = u.User.Username()
+			}
+		}
+		db = strings.TrimPrefix(u.Path, "/")
+	}
+	if !strings.Contains(host, ":") {
+		host += ":6379"
+	}
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("redis connect %s: %w", host, err)
+	}
+	conn := &respConn{Conn: nc, r: bufio.NewReader(nc)}
+
+	for _, cmd := range [][]string{{"AUTH", password}, {"SELECT", db}} {
+		if cmd[1] == "" {
+			continue
+		}
+		if err := conn.send(cmd...); err != nil {
+			nc.Close()
+			return nil, err
+		}
+		if _, err := conn.read(); err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("redis %s: %w", cmd[0], err)
+		}
+	}
+	return conn, nil
+}
+
+// send writes a command as a RESP array of bulk strings.
+func (c *respConn) send(args ...string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(a), a)
+	}
+	_, err := io.WriteString(c.Conn, sb.String())
+	return err
+}
+
+// read parses one RESP value. Error replies are returned as Go errors.
+func (c *respConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis: %s", line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		arr := make([]interface{}, n)
+		for i := range arr {
+			if arr[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
@@ -0,0 +1,287 @@
+package source
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// slowQueryIdleFlush is how long a followed statement may wait for its next
+// line before it is emitted anyway.
+const slowQueryIdleFlush = time.Second
+
+var (
+	mysqlUserHost  = regexp.MustCompile(`^# User@Host: (\S+?)\[[^\]]*\] @ (\S*) ?\[([^\]]*)\]`)
+	mysqlStats     = regexp.MustCompile(`(\w+): ([\d.]+)`)
+	mysqlSetTime   = regexp.MustCompile(`^SET timestamp=(\d+);$`)
+	mysqlUseDB     = regexp.MustCompile(`^(?i)use (\S+);$`)
+	pgDuration     = regexp.MustCompile(`duration: ([\d.]+) ms\s+(?:statement|execute [^:]*|parse [^:]*|bind [^:]*): (.*)$`)
+	pgUserDB       = regexp.MustCompile(`\b([\w.-]+)@([\w.-]+)\b`)
+	mysqlFileNoise = []string{"Tcp port:", "Time                 Id Command", "Time Id Command"}
+)
+
+// SlowQuerySource reads a MySQL or PostgreSQL slow query log and emits one
+// entry per statement, joining the multi-line records (MySQL "# Time:" /
+// "# User@Host:" headers, PostgreSQL continuation lines) and extracting
+// duration_ms, lock_ms, rows_sent, rows_examined, user, host and db into Fields.
+type SlowQuerySource struct {
+	path   string
+	follow bool
+	seq    atomic.Uint64
+}
+
+// NewSlowQuerySource creates a slow query log source. The format (MySQL or
+// PostgreSQL) is detected from the records themselves.
+func NewSlowQuerySource(path string, follow bool) *SlowQuerySource {
+	return &SlowQuerySource{path: path, follow: follow}
+}
+
+// Name returns the source identifier.
+func (s *SlowQuerySource) Name() string {
+	return "slowlog:" + s.path
+}
+
+// slowStatement accumulates the lines of one record.
+type slowStatement struct {
+	lines  []string
+	mysql  bool
+	source string
+}
+
+// Start reads the file through a FileSource and groups its lines.
+func (s *SlowQuerySource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	in, err := NewFileSource(s.path, s.follow).Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan entry.LogEntry, 256)
+
+	go func() {
+		defer close(ch)
+
+		var cur *slowStatement
+		flush := func() bool {
+			if cur == nil {
+				return true
+			}
+			e, ok := s.toEntry(cur)
+			cur = nil
+			if !ok {
+				return true
+			}
+			select {
+			case ch <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		idle := time.NewTimer(slowQueryIdleFlush)
+		defer idle.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-idle.C:
+				if !flush() {
+					return
+				}
+				continue
+			case e, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				line := e.Message
+				if isSlowLogNoise(line) {
+					continue
+				}
+
+				switch {
+				case strings.HasPrefix(line, "# Time:"):
+					// "# Time:" always opens a MySQL record.
+					if !flush() {
+						return
+					}
+					cur = &slowStatement{mysql: true, source: e.Source}
+				case strings.HasPrefix(line, "# User@Host:"):
+					// Records without a "# Time:" header start here.
+					if cur == nil || !cur.mysql || hasSlowHeader(cur, "# User@Host:") {
+						if !flush() {
+							return
+						}
+						cur = &slowStatement{mysql: true, source: e.Source}
+					}
+				case cur != nil && cur.mysql:
+					// Statement or stats line of the current MySQL record.
+				case cur != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+					// PostgreSQL continuation of a multi-line statement.
+				default:
+					if !flush() {
+						return
+					}
+					cur = &slowStatement{source: e.Source}
+				}
+				cur.lines = append(cur.lines, line)
+			}
+
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(slowQueryIdleFlush)
+		}
+	}()
+
+	return ch, nil
+}
+
+// isSlowLogNoise reports MySQL file banner lines that belong to no statement.
+func isSlowLogNoise(line string) bool {
+	if strings.TrimSpace(line) == "" || strings.Contains(line, ", Version: ") {
+		return true
+	}
+	for _, p := range mysqlFileNoise {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSlowHeader reports whether the record already contains a header line.
+func hasSlowHeader(st *slowStatement, prefix string) bool {
+	for _, l := range st.lines {
+		if strings.HasPrefix(l, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// toEntry builds the entry for a completed record. Records that carry no
+// statement (e.g. unrelated PostgreSQL log lines) are passed through as-is.
+func (s *SlowQuerySource) toEntry(st *slowStatement) (entry.LogEntry, bool) {
+	e := entry.LogEntry{
+		Timestamp: time.Now(),
+		Stream:    "slowlog",
+		Source:    "slowlog:" + strings.TrimPrefix(st.source, "file:"),
+		Fields:    make(map[string]string),
+		Raw:       []byte(strings.Join(st.lines, "\n")),
+		Seq:       s.seq.Add(1),
+	}
+	if st.mysql {
+		s.parseMySQL(st.lines, &e)
+	} else {
+		s.parsePostgres(st.lines, &e)
+	}
+	if e.Message == "" {
+		e.Message = strings.Join(st.lines, " ")
+	}
+	return e, len(st.lines) > 0
+}
+
+// parseMySQL extracts the header fields and statement of a MySQL record:
+//
+//	# Time: 2024-01-02T03:04:05.123456Z
+//	# User@Host: app[app] @ web-1 [10.0.0.5]  Id:    42
+//	# Query_time: 2.000167  Lock_time: 0.000012 Rows_sent: 1  Rows_examined: 1000
+//	SET timestamp=1704164645;
+//	SELECT ...;
+func (s *SlowQuerySource) parseMySQL(lines []string, e *entry.LogEntry) {
+	var stmt []string
+	haveTime := false
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "# Time:"):
+			v := strings.TrimSpace(strings.TrimPrefix(l, "# Time:"))
+			if ts, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				e.Timestamp, haveTime = ts, true
+			} else if ts, err := time.ParseInLocation("060102 15:04:05", strings.Join(strings.Fields(v), " "), time.Local); err == nil {
+				e.Timestamp, haveTime = ts, true
+			}
+		case strings.HasPrefix(l, "# User@Host:"):
+			if m := mysqlUserHost.FindStringSubmatch(l); m != nil {
+				e.Fields["user"] = m[1]
+				host := m[2]
+				if host == "" {
+					host = m[3]
+				}
+				e.Fields["host"] = host
+			}
+		case strings.HasPrefix(l, "# "):
+			for _, m := range mysqlStats.FindAllStringSubmatch(l, -1) {
+				switch m[1] {
+				case "Query_time":
+					e.Fields["duration_ms"] = secondsToMillis(m[2])
+				case "Lock_time":
+					e.Fields["lock_ms"] = secondsToMillis(m[2])
+				case "Rows_sent":
+					e.Fields["rows_sent"] = m[2]
+				case "Rows_examined":
+					e.Fields["rows_examined"] = m[2]
+				}
+			}
+		default:
+			if m := mysqlSetTime.FindStringSubmatch(l); m != nil {
+				// Second precision only; used when there is no "# Time:" header.
+				if sec, err := strconv.ParseInt(m[1], 10, 64); err == nil && !haveTime {
+					e.Timestamp = time.Unix(sec, 0)
+				}
+				continue
+			}
+			if m := mysqlUseDB.FindStringSubmatch(l); m != nil {
+				e.Fields["db"] = strings.Trim(m[1], "`")
+				continue
+			}
+			stmt = append(stmt, strings.TrimSpace(l))
+		}
+	}
+	e.Message = strings.Join(stmt, " ")
+}
+
+// parsePostgres extracts the duration and statement of a PostgreSQL
+// log_min_duration_statement record; user and db come from a "%u@%d"
+// log_line_prefix when present.
+func (s *SlowQuerySource) parsePostgres(lines []string, e *entry.LogEntry) {
+	first := lines[0]
+	if ts, ok := parseLineTime(first); ok {
+		e.Timestamp = ts
+	}
+	m := pgDuration.FindStringSubmatchIndex(first)
+	if m == nil {
+		e.Message = strings.Join(lines, " ")
+		return
+	}
+	e.Fields["duration_ms"] = first[m[2]:m[3]]
+	if um := pgUserDB.FindStringSubmatch(first[:m[0]]); um != nil {
+		e.Fields["user"] = um[1]
+		e.Fields["db"] = um[2]
+	}
+
+	stmt := []string{strings.TrimSpace(first[m[4]:m[5]])}
+	for _, l := range lines[1:] {
+		stmt = append(stmt, strings.TrimSpace(l))
+	}
+	e.Message = strings.Join(stmt, " ")
+}
+
+// secondsToMillis converts a decimal seconds value to milliseconds.
+func secondsToMillis(v string) string {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	return strconv.FormatFloat(math.Round(f*1e6)/1e3, 'f', -1, 64) // to the microsecond
+}
@@ -0,0 +1,86 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+)
+
+// tailChunkSize is how much is read per step when scanning a file backwards.
+const tailChunkSize = 64 * 1024
+
+// tailOffset returns the byte offset at which the last n lines of f begin.
+// It reads backwards from the end in chunks, so only the tail is touched
+// regardless of file size. A trailing newline does not count as a line.
+func tailOffset(f *os.File, n int) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if n <= 0 {
+		return size, nil
+	}
+
+	buf := make([]byte, tailChunkSize)
+	pos := size
+	seen := 0
+	trailing := true
+	for pos > 0 {
+		step := int64(len(buf))
+		if pos < step {
+			step = pos
+		}
+		pos -= step
+		chunk := buf[:step]
+		if _, err := f.ReadAt(chunk, pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		for i := len(chunk) - 1; i >= 0; i-- {
+			if chunk[i] != '\n' {
+				trailing = false
+				continue
+			}
+			if trailing {
+				// The newline terminating the last line.
+				trailing = false
+				continue
+			}
+			seen++
+			if seen == n {
+				return pos + int64(i) + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// tailReader reads r to the end and returns a reader over only its last n
+// lines. Used for compressed files, which cannot be read backwards.
+func tailReader(r io.Reader, n int) (io.Reader, error) {
+	lines := make([][]byte, 0, n)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if n <= 0 {
+			continue
+		}
+		if len(lines) == n {
+			copy(lines, lines[1:])
+			lines = lines[:n-1]
+		}
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	for _, l := range lines {
+		b.Write(l)
+		b.WriteByte('\n')
+	}
+	return &b, nil
+}
@@ -0,0 +1,84 @@
+package source
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TimeRange bounds the entries a source emits. A zero Since or Until leaves
+// that side open.
+type TimeRange struct {
+	Since time.Time
+	Until time.Time
+}
+
+// IsZero reports whether the range is unbounded.
+func (r TimeRange) IsZero() bool {
+	return r.Since.IsZero() && r.Until.IsZero()
+}
+
+// Contains reports whether t falls within the range (inclusive).
+func (r TimeRange) Contains(t time.Time) bool {
+	if !r.Since.IsZero() && t.Before(r.Since) {
+		return false
+	}
+	if !r.Until.IsZero() && t.After(r.Until) {
+		return false
+	}
+	return true
+}
+
+// lineTimeLayouts are tried, in order, against the start of a log line.
+var lineTimeLayouts = []struct {
+	re     *regexp.Regexp
+	layout string
+}{
+	// 2024-01-02T15:04:05.000Z, 2024-01-02T15:04:05+09:00
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), time.RFC3339Nano},
+	// 2024-01-02 15:04:05.000 / 2024-01-02T15:04:05 (local time)
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[ T]\d{2}:\d{2}:\d{2}([.,]\d+)?`), "2006-01-02 15:04:05.999999999"},
+	// 2024/01/02 15:04:05 (Go log package, nginx error log)
+	{regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`), "2006/01/02 15:04:05"},
+	// Jan  2 15:04:05 (syslog; the current year is assumed)
+	{regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`), time.Stamp},
+}
+
+// commonLogTime matches the bracketed timestamp of Apache/nginx access logs.
+var commonLogTime = regexp.MustCompile(`\[(\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4})\]`)
+
+// parseLineTime extracts the timestamp a log line starts with, if any.
+// Bracketed prefixes such as "[2024-01-02 15:04:05]" are unwrapped first.
+func parseLineTime(line string) (time.Time, bool) {
+	s := strings.TrimLeft(line, " [")
+	for _, l := range lineTimeLayouts {
+		m := l.re.FindString(s)
+		if m == "" {
+			continue
+		}
+		m = strings.Replace(m, ",", ".", 1)
+		if l.layout != time.RFC3339Nano {
+			m = strings.Replace(m, "T", " ", 1)
+		}
+		if l.layout == time.Stamp {
+			ts, err := time.ParseInLocation(time.Stamp, m, time.Local)
+			if err != nil {
+				return time.Time{}, false
+			}
+			now := time.Now()
+			ts = ts.AddDate(now.Year(), 0, 0)
+			if ts.After(now.Add(24 * time.Hour)) {
+				ts = ts.AddDate(-1, 0, 0) // December lines read in January
+			}
+			return ts, true
+		}
+		ts, err := time.ParseInLocation(l.layout, m, time.Local)
+		return ts, err == nil
+	}
+
+	if m := commonLogTime.FindStringSubmatch(line); m != nil {
+		ts, err := time.Parse("02/Jan/2006:15:04:05 -0700", m[1])
+		return ts, err == nil
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,126 @@
+package source
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Geun-Oh/lx/internal/entry"
+)
+
+// TracebackGroupSource wraps a source and folds Python tracebacks into
+// single ERROR entries:
+//
+//	Traceback (most recent call last):
+//	  File "/app/main.py", line 12, in <module>
+//	    main()
+//	  File "/app/main.py", line 8, in main
+//	    raise ValueError("bad input")
+//	ValueError: bad input
+//
+// Chained tracebacks ("During handling of the above exception, another
+// exception occurred:") stay in the same entry. The grouped entry keeps the
+// first line's timestamp and source and gets Fields "exception" (the class of
+// the exception finally raised), "exception_message", "frames" (the number of
+// stack frames) and "location" (file:line of the innermost frame).
+type TracebackGroupSource struct {
+	src Source
+}
+
+const tracebackStart = "Traceback (most recent call last):"
+
+var (
+	// tracebackChain matches the lines joining chained tracebacks.
+	tracebackChain = regexp.MustCompile(`^(?:During handling of the above exception, another exception occurred:|The above exception was the direct cause of the following exception:)$`)
+
+	// tracebackException matches the closing "Class: message" line.
+	tracebackException = regexp.MustCompile(`^([A-Za-z_][\w.]*)(?::\s?(.*))?$`)
+
+	tracebackFrame = regexp.MustCompile(`^\s+File "([^"]+)", line (\d+)`)
+)
+
+// NewTracebackGroupSource wraps src.
+func NewTracebackGroupSource(src Source) *TracebackGroupSource {
+	return &TracebackGroupSource{src: src}
+}
+
+// Name returns the wrapped source's name.
+func (s *TracebackGroupSource) Name() string {
+	return s.src.Name()
+}
+
+// Start starts the wrapped source and groups its entries.
+func (s *TracebackGroupSource) Start(ctx context.Context) (<-chan entry.LogEntry, error) {
+	in, err := s.src.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan entry.LogEntry, 256)
+	go groupEntries(ctx, in, out, &tracebackGrouper{})
+	return out, nil
+}
+
+// tracebackGrouper recognizes Python tracebacks. After the exception line
+// only blank lines and the start of a chained traceback continue it.
+type tracebackGrouper struct {
+	raised bool // the exception line has been seen
+}
+
+func (g *tracebackGrouper) start(line string) bool {
+	g.raised = false
+	return line == tracebackStart
+}
+
+func (g *tracebackGrouper) next(line string) bool {
+	switch {
+	case strings.TrimSpace(line) == "":
+		return true
+	case line == tracebackStart || tracebackChain.MatchString(line):
+		g.raised = false
+		return true
+	case g.raised:
+		return false
+	case line[0] == ' ' || line[0] == '\t':
+		return true
+	case tracebackException.MatchString(line):
+		g.raised = true
+		return true
+	}
+	return false
+}
+
+// finish builds the grouped entry from the traceback's lines.
+func (g *tracebackGrouper) finish(first entry.LogEntry, lines []string) entry.LogEntry {
+	lines = trimTrailingBlank(lines)
+
+	e := first
+	e.Message = strings.Join(lines, "\n")
+	e.Raw = []byte(e.Message)
+	e.Level = entry.LevelError
+	fields := copyFields(first.Fields, 4)
+
+	frames := 0
+	for _, l := range lines {
+		if m := tracebackFrame.FindStringSubmatch(l); m != nil {
+			frames++
+			fields["location"] = m[1] + ":" + m[2]
+			continue
+		}
+		if l == tracebackStart || l == "" || l[0] == ' ' || l[0] == '\t' || tracebackChain.MatchString(l) {
+			continue
+		}
+		if m := tracebackException.FindStringSubmatch(l); m != nil {
+			fields["exception"] = m[1]
+			delete(fields, "exception_message")
+			if m[2] != "" {
+				fields["exception_message"] = m[2]
+			}
+		}
+	}
+	if frames > 0 {
+		fields["frames"] = strconv.Itoa(frames)
+	}
+	e.Fields = fields
+	return e
+}
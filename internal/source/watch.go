@@ -0,0 +1,105 @@
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// pollInterval is used when filesystem notifications are unavailable.
+	pollInterval = 100 * time.Millisecond
+	// safetyPollInterval re-checks watched files in case an event was missed
+	// (e.g. on network filesystems that only partially support inotify).
+	safetyPollInterval = 2 * time.Second
+)
+
+// fileNotifier wakes followed files when the OS reports a write, replacing the
+// fixed-interval sleep loop. A single watcher is shared by all files of a source.
+type fileNotifier struct {
+	w    *fsnotify.Watcher
+	mu   sync.Mutex
+	subs map[string]chan struct{}
+}
+
+// newFileNotifier starts an fsnotify watcher. Returns nil when the platform
+// does not support it, in which case callers fall back to polling.
+func newFileNotifier(ctx context.Context) *fileNotifier {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil
+	}
+
+	n := &fileNotifier{
+		w:    w,
+		subs: make(map[string]chan struct{}),
+	}
+	go n.run(ctx)
+	return n
+}
+
+// subscribe registers path and returns a channel signalled on each change.
+// Returns nil if the file cannot be watched; a nil channel never fires, so
+// callers transparently degrade to their poll timer.
+func (n *fileNotifier) subscribe(path string) <-chan struct{} {
+	if n == nil {
+		return nil
+	}
+
+	path = filepath.Clean(path)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if ch, ok := n.subs[path]; ok {
+		return ch
+	}
+	if err := n.w.Add(path); err != nil {
+		return nil
+	}
+	ch := make(chan struct{}, 1)
+	n.subs[path] = ch
+	return ch
+}
+
+// interval returns how long a follower should sleep between checks, given
+// whether it holds a notification channel.
+func (n *fileNotifier) interval(wake <-chan struct{}) time.Duration {
+	if wake == nil {
+		return pollInterval
+	}
+	return safetyPollInterval
+}
+
+// run dispatches watcher events to subscribers until ctx is cancelled.
+func (n *fileNotifier) run(ctx context.Context) {
+	defer n.w.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-n.w.Events:
+			if !ok {
+				return
+			}
+			n.mu.Lock()
+			ch := n.subs[filepath.Clean(ev.Name)]
+			n.mu.Unlock()
+			if ch == nil {
+				continue
+			}
+			// Coalesce bursts: one pending wake-up is enough.
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		case _, ok := <-n.w.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
@@ -3,12 +3,15 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/Geun-Oh/lx/internal/buffer"
 	"github.com/Geun-Oh/lx/internal/entry"
+	"github.com/Geun-Oh/lx/internal/filter"
 	"github.com/Geun-Oh/lx/internal/monitor"
+	"github.com/Geun-Oh/lx/internal/sink"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -47,6 +50,15 @@ var (
 			Foreground(lipgloss.Color("#FF6600")).
 			Bold(true)
 
+	criticalStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FFFFFF")).
+			Background(lipgloss.Color("#CC0000")).
+			Bold(true)
+
+	resolvedStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#2EB67D")).
+			Bold(true)
+
 	helpStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#888888"))
 )
@@ -58,13 +70,34 @@ type LogMsg entry.LogEntry
 
 // AlertMsg notifies the TUI that an alert was triggered.
 type AlertMsg struct {
+	Rules    []string
+	Entry    entry.LogEntry
+	Severity string // highest severity of the rules; "" counts as warning
+}
+
+// ResolvedMsg notifies the TUI that alert rules stopped firing.
+type ResolvedMsg struct {
 	Rules []string
 	Entry entry.LogEntry
 }
 
+// TemplateMsg notifies the TUI of a log template first seen after the
+// learning period.
+type TemplateMsg struct {
+	Template string
+}
+
 // SpikeMsg notifies the TUI that a rate spike was detected.
 type SpikeMsg struct {
-	Rate float64
+	Rate   float64
+	Levels string // e.g. "ERROR/FATAL" when spike detection is restricted
+}
+
+// ExportMsg reports a snapshot of the buffer saved to a file.
+type ExportMsg struct {
+	Path  string
+	Lines int
+	Err   error
 }
 
 // TickMsg triggers periodic UI updates.
@@ -86,10 +119,12 @@ type Model struct {
 	paused     bool
 	pauseQueue []string
 
-	// Search state.
-	searching    bool
-	searchQuery  string
-	searchResult []int // indices into logs that match
+	// Search state. A search runs over the ring buffer and shows its
+	// matches instead of the live lines until esc.
+	searching   bool
+	searchQuery string
+	results     []string      // formatted matches, nil when not showing a search
+	search      filter.Filter // the filter results came from
 
 	// Monitoring.
 	Stats   *monitor.Stats
@@ -98,9 +133,24 @@ type Model struct {
 	RingBuf *buffer.Ring
 	Source  string
 
+	// ShowSource prefixes each line with the entry's source (merged inputs).
+	ShowSource bool
+
+	// ExportDir is where snapshots of the buffer are saved (keys e and E).
+	ExportDir string
+
+	// Top messages panel, when Stats tracks them, and sources panel, for
+	// merged inputs.
+	showTop     bool
+	showSources bool
+
+	// Alert history panel, when there are alert rules.
+	showAlerts bool
+
 	// Alert display.
 	lastAlert  string
-	alertFlash int // countdown for alert flash
+	alertFlash int    // countdown for alert flash
+	alertStyle string // severity of an alert being shown, "resolved" for resolved rules, "" for other messages
 
 	// Level counters.
 	errorCount int
@@ -144,13 +194,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleLog(msg)
 
 	case AlertMsg:
-		m.lastAlert = fmt.Sprintf("⚠ ALERT [%s]: %s", strings.Join(msg.Rules, ","), truncate(msg.Entry.Message, 60))
-		m.alertFlash = 10
+		// A less severe alert does not cover one still flashing.
+		if m.alertFlash > 0 && m.alertStyle != "" && monitor.SeverityRank(msg.Severity) < monitor.SeverityRank(m.alertStyle) {
+			return m, nil
+		}
+		severity := msg.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		m.lastAlert = fmt.Sprintf("⚠ %s ALERT [%s]: %s", strings.ToUpper(severity), strings.Join(msg.Rules, ","), truncate(msg.Entry.Message, 60))
+		m.alertStyle = severity
+		switch monitor.SeverityRank(severity) {
+		case 1:
+			m.alertFlash = 6
+		case 3:
+			m.alertFlash = 30
+		default:
+			m.alertFlash = 10
+		}
+		return m, nil
+
+	case ResolvedMsg:
+		// Nor does a resolve.
+		if m.alertFlash > 0 && m.alertStyle != "" && m.alertStyle != "resolved" {
+			return m, nil
+		}
+		m.lastAlert = fmt.Sprintf("✔ RESOLVED [%s]: %s", strings.Join(msg.Rules, ","), truncate(msg.Entry.Message, 60))
+		m.alertStyle = "resolved"
+		m.alertFlash = 8
 		return m, nil
 
 	case SpikeMsg:
 		m.lastAlert = fmt.Sprintf("📈 SPIKE: %.0f lines/s", msg.Rate)
+		if msg.Levels != "" {
+			m.lastAlert = fmt.Sprintf("📈 %s SPIKE: %.1f lines/s", msg.Levels, msg.Rate)
+		}
+		m.alertFlash = 8
+		m.alertStyle = ""
+		return m, nil
+
+	case TemplateMsg:
+		m.lastAlert = fmt.Sprintf("🆕 NEW TEMPLATE: %s", truncate(msg.Template, 60))
 		m.alertFlash = 8
+		m.alertStyle = ""
+		return m, nil
+
+	case ExportMsg:
+		if msg.Err != nil {
+			m.lastAlert = "💾 " + msg.Err.Error()
+		} else {
+			m.lastAlert = fmt.Sprintf("💾 Saved %d lines to %s", msg.Lines, msg.Path)
+		}
+		m.alertFlash = 10
+		m.alertStyle = ""
 		return m, nil
 
 	case TickMsg:
@@ -174,7 +270,6 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "esc":
 			m.searching = false
 			m.searchQuery = ""
-			m.searchResult = nil
 			return m, nil
 		case "enter":
 			m.searching = false
@@ -207,10 +302,21 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "/":
 		m.searching = true
 		m.searchQuery = ""
-		m.searchResult = nil
+		return m, nil
+	case "e", "E":
+		format := "json"
+		if msg.String() == "E" {
+			format = "text"
+		}
+		return m, m.export(format)
+	case "esc":
+		m.results = nil
+		m.search = nil
+		m.searchQuery = ""
+		m.scrollPos = 0
 		return m, nil
 	case "up", "k":
-		if m.scrollPos < len(m.logs)-1 {
+		if m.scrollPos < len(m.lines())-1 {
 			m.scrollPos++
 		}
 		return m, nil
@@ -219,11 +325,20 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.scrollPos--
 		}
 		return m, nil
+	case "t":
+		m.showTop = !m.showTop && m.Stats.TracksTopMessages()
+		return m, nil
+	case "s":
+		m.showSources = !m.showSources && m.ShowSource
+		return m, nil
+	case "a":
+		m.showAlerts = !m.showAlerts && m.Alerts != nil
+		return m, nil
 	case "g":
 		m.scrollPos = 0 // jump to bottom (latest)
 		return m, nil
 	case "G":
-		m.scrollPos = len(m.logs) - 1 // jump to top (oldest)
+		m.scrollPos = len(m.lines()) - 1 // jump to top (oldest)
 		return m, nil
 	}
 
@@ -281,16 +396,28 @@ func (m Model) View() string {
 
 	// Alert bar (if active).
 	if m.alertFlash > 0 && m.lastAlert != "" {
-		alertBar := highlightStyle.Render(m.lastAlert)
+		style := highlightStyle
+		switch m.alertStyle {
+		case "info":
+			style = infoStyle
+		case "critical":
+			style = criticalStyle
+		case "resolved":
+			style = resolvedStyle
+		}
+		alertBar := style.Render(padRight(m.lastAlert, m.width))
 		sb.WriteString(alertBar)
 		sb.WriteString("\n")
 	}
 
-	// Search bar (if searching).
+	// Search bar (if searching), or what the search found.
 	if m.searching {
 		searchBar := fmt.Sprintf(" 🔍 Search: %s█", m.searchQuery)
 		sb.WriteString(searchBar)
 		sb.WriteString("\n")
+	} else if m.results != nil {
+		sb.WriteString(fmt.Sprintf(" 🔍 %d matches for %q in the last %d lines  [esc] back to live", len(m.results), m.searchQuery, m.RingBuf.Len()))
+		sb.WriteString("\n")
 	}
 
 	// Calculate viewport height.
@@ -298,10 +425,25 @@ func (m Model) View() string {
 	if m.alertFlash > 0 {
 		headerLines++
 	}
-	if m.searching {
+	if m.searching || m.results != nil {
 		headerLines++
 	}
-	footerLines := 2 // stats bar + help bar
+	footerLines := 2 + len(m.Stats.Groups()) // counts by field + stats bar + help bar
+	var topPanel []string
+	if m.showTop {
+		topPanel = m.renderTopPanel()
+		footerLines += len(topPanel)
+	}
+	var sourcesPanel []string
+	if m.showSources {
+		sourcesPanel = m.renderSourcesPanel()
+		footerLines += len(sourcesPanel)
+	}
+	var alertsPanel []string
+	if m.showAlerts {
+		alertsPanel = m.renderAlertsPanel()
+		footerLines += len(alertsPanel)
+	}
 	viewportHeight := m.height - headerLines - footerLines
 	if viewportHeight < 1 {
 		viewportHeight = 1
@@ -319,13 +461,39 @@ func (m Model) View() string {
 		sb.WriteString("\n")
 	}
 
+	// Top messages, sources and alert history panels.
+	panels := append(append(topPanel, sourcesPanel...), alertsPanel...)
+	for _, line := range panels {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	// Counts by field.
+	for _, g := range m.Stats.Groups() {
+		sb.WriteString(statusBarStyle.Render(padRight(" "+truncate(g.Line(8), m.width-1), m.width)))
+		sb.WriteString("\n")
+	}
+
 	// Stats bar.
 	rate := m.Rate.CurrentRate()
 	rateBar := m.renderRateBar(rate, 10)
-	statsLine := fmt.Sprintf(" Rate: %s %.0f/s │ ERR: %d │ WARN: %d │ Total: %d",
-		rateBar, rate, m.errorCount, m.warnCount, m.totalCount)
+	errRate := m.Rate.LevelRate(entry.LevelError, entry.LevelFatal)
+	statsLine := fmt.Sprintf(" Rate: %s %.0f/s │ ERR: %d (%.1f/s) │ WARN: %d │ Total: %d",
+		rateBar, rate, m.errorCount, errRate, m.warnCount, m.totalCount)
+	if series, _ := m.Stats.Histogram().Series(entry.LevelError, entry.LevelFatal); len(series) > 1 {
+		if len(series) > 20 {
+			series = series[len(series)-20:]
+		}
+		statsLine += " │ ERR/" + m.Stats.Histogram().Bucket().String() + " " + monitor.Sparkline(series, 20)
+	}
+	if lat := m.Stats.Latency(); lat.Count() > 0 {
+		statsLine += " │ " + lat.String()
+	}
 	if m.Alerts != nil && m.Alerts.TotalAlerts() > 0 {
 		statsLine += fmt.Sprintf(" │ Alerts: %d", m.Alerts.TotalAlerts())
+		if firing := len(m.Alerts.Active()); firing > 0 {
+			statsLine += fmt.Sprintf(" (%d firing)", firing)
+		}
 	}
 	if m.scrollPos > 0 {
 		statsLine += fmt.Sprintf(" │ ↑ %d", m.scrollPos)
@@ -334,7 +502,17 @@ func (m Model) View() string {
 	sb.WriteString("\n")
 
 	// Help bar.
-	helpText := " [/]Search  [p]Pause  [↑↓]Scroll  [g]Bottom  [q]Quit"
+	helpText := " [/]Search  [p]Pause  [↑↓]Scroll  [g]Bottom  [e/E]Save"
+	if m.Stats.TracksTopMessages() {
+		helpText += "  [t]Top"
+	}
+	if m.ShowSource {
+		helpText += "  [s]Sources"
+	}
+	if m.Alerts != nil {
+		helpText += "  [a]Alerts"
+	}
+	helpText += "  [q]Quit"
 	if m.paused {
 		helpText += fmt.Sprintf("  (queued: %d)", len(m.pauseQueue))
 	}
@@ -365,16 +543,120 @@ func (m *Model) formatLogLine(e *entry.LogEntry) string {
 		levelStr = e.Level.String() + " "
 	}
 
-	msg := truncate(e.Message, m.width-25)
-	return style.Render(fmt.Sprintf("%s [%s] %s%s", ts, e.Stream, levelStr, msg))
+	stream := e.Stream
+	if m.ShowSource && e.Source != "" {
+		stream = e.Source + "][" + e.Stream
+	}
+
+	msg := truncate(e.Message, m.width-22-len(stream))
+	return style.Render(fmt.Sprintf("%s [%s] %s%s", ts, stream, levelStr, msg))
+}
+
+// renderTopPanel lists the most frequent message templates, using at most a
+// third of the screen.
+func (m *Model) renderTopPanel() []string {
+	top := m.Stats.TopMessages()
+	if max := m.height/3 - 1; len(top) > max {
+		if max < 0 {
+			max = 0
+		}
+		top = top[:max]
+	}
+	lines := []string{titleStyle.Render(padRight(" Top messages", m.width))}
+	for _, t := range top {
+		tmpl, _, _ := strings.Cut(t.Template, "\n")
+		lines = append(lines, fmt.Sprintf(" %8d  %s", t.Count, truncate(tmpl, m.width-12)))
+	}
+	return lines
+}
+
+// renderSourcesPanel lists the sources of merged inputs, busiest right now
+// first, using at most a third of the screen.
+func (m *Model) renderSourcesPanel() []string {
+	sources := m.Stats.Sources()
+	sort.SliceStable(sources, func(i, j int) bool { return sources[i].Rate > sources[j].Rate })
+	if max := m.height/3 - 1; len(sources) > max {
+		if max < 0 {
+			max = 0
+		}
+		sources = sources[:max]
+	}
+	nameWidth := min(max(m.width-46, 12), 40)
+	header := fmt.Sprintf("%-*s %9s %10s %10s %10s", nameWidth, "Sources", "lines/s", "lines", "matched", "filtered")
+	lines := []string{titleStyle.Render(padRight(header, m.width))}
+	for _, s := range sources {
+		lines = append(lines, fmt.Sprintf(" %-*s %9.1f %10d %10d %10d",
+			nameWidth, truncate(s.Source, nameWidth), s.Rate, s.Lines, s.Matched, s.Filtered))
+	}
+	return lines
+}
+
+// renderAlertsPanel shows the latest alert firings, newest first, each with
+// the lines around its trigger, using at most a third of the screen.
+func (m *Model) renderAlertsPanel() []string {
+	max := m.height/3 - 1
+	if max < 1 {
+		max = 1
+	}
+	firings := m.Alerts.LastFirings(max)
+	lines := []string{titleStyle.Render(padRight(fmt.Sprintf("Alerts (%d)", m.Alerts.TotalAlerts()), m.width))}
+	for i := len(firings) - 1; i >= 0 && len(lines) <= max; i-- {
+		f := firings[i]
+		severity := f.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		style := highlightStyle
+		switch severity {
+		case "info":
+			style = infoStyle
+		case "critical":
+			style = errorStyle
+		}
+		line, _, _ := strings.Cut(f.Line, "\n")
+		rule := f.Rule
+		if !f.Resolved.IsZero() {
+			rule += " (resolved " + f.Resolved.Format("15:04:05") + ")"
+		}
+		lines = append(lines, style.Render(truncate(fmt.Sprintf(" %s %-8s %s: %s",
+			f.Time.Format("15:04:05"), severity, rule, line), m.width)))
+		var context []string
+		for _, e := range f.Before {
+			context = append(context, "   "+e.Message)
+		}
+		if len(f.Before) > 0 || len(f.After) > 0 {
+			context = append(context, " ▶ "+f.Line)
+		}
+		for _, e := range f.After {
+			context = append(context, "   "+e.Message)
+		}
+		for _, c := range context {
+			if len(lines) > max {
+				break
+			}
+			c, _, _ = strings.Cut(c, "\n")
+			lines = append(lines, dimStyle.Render(truncate(c, m.width)))
+		}
+	}
+	return lines
+}
+
+// lines returns the lines the viewport shows: the search results, if any,
+// else the live lines.
+func (m *Model) lines() []string {
+	if m.results != nil {
+		return m.results
+	}
+	return m.logs
 }
 
 func (m *Model) getVisibleLogs(height int) []string {
-	if len(m.logs) == 0 {
+	lines := m.lines()
+	if len(lines) == 0 {
 		return nil
 	}
 
-	end := len(m.logs) - m.scrollPos
+	end := len(lines) - m.scrollPos
 	if end < 0 {
 		end = 0
 	}
@@ -386,7 +668,7 @@ func (m *Model) getVisibleLogs(height int) []string {
 	// Highlight search results.
 	result := make([]string, 0, end-start)
 	for i := start; i < end; i++ {
-		line := m.logs[i]
+		line := lines[i]
 		if m.searchQuery != "" && strings.Contains(line, m.searchQuery) {
 			line = strings.ReplaceAll(line, m.searchQuery, highlightStyle.Render(m.searchQuery))
 		}
@@ -395,21 +677,53 @@ func (m *Model) getVisibleLogs(height int) []string {
 	return result
 }
 
+// performSearch queries the ring buffer for the search, a filter
+// expression such as `level >= ERROR && fields.status == 500` or else text
+// in the message, and shows the latest matches.
 func (m *Model) performSearch() {
-	m.searchResult = nil
-	if m.searchQuery == "" {
+	m.results, m.search = nil, nil
+	if m.searchQuery == "" || m.RingBuf == nil {
 		return
 	}
-	for i, line := range m.logs {
-		if strings.Contains(line, m.searchQuery) {
-			m.searchResult = append(m.searchResult, i)
-		}
+	m.search = searchFilter(m.searchQuery)
+	matches := m.RingBuf.Query(m.search, time.Time{}, time.Time{}, m.maxLines)
+	m.results = make([]string, len(matches))
+	for i := range matches {
+		m.results[i] = m.formatLogLine(&matches[i])
+	}
+	m.scrollPos = 0
+}
+
+// export saves what is being looked at, the search matches or else the
+// whole buffer, to a timestamped file in ExportDir: JSONL for format "json",
+// else plain text.
+func (m *Model) export(format string) tea.Cmd {
+	if m.RingBuf == nil {
+		return nil
 	}
-	// Scroll to last match.
-	if len(m.searchResult) > 0 {
-		lastMatch := m.searchResult[len(m.searchResult)-1]
-		m.scrollPos = len(m.logs) - lastMatch - 1
+	ring, search, dir := m.RingBuf, m.search, m.ExportDir
+	return func() tea.Msg {
+		entries := ring.Query(search, time.Time{}, time.Time{}, 0)
+		path, err := sink.Export(dir, format, entries)
+		return ExportMsg{Path: path, Lines: len(entries), Err: err}
+	}
+}
+
+// exprOperators mark a search as a filter expression rather than text.
+var exprOperators = []string{"==", "!=", ">", "<", "=~", "&&", "||", " contains ", " matches ", " startswith ", " endswith "}
+
+// searchFilter compiles a search: a filter expression if it has an operator
+// and compiles, else the text to find in messages.
+func searchFilter(query string) filter.Filter {
+	for _, op := range exprOperators {
+		if strings.Contains(query, op) {
+			if f, err := filter.NewExprFilter(query); err == nil {
+				return f
+			}
+			break
+		}
 	}
+	return filter.NewKeywordFilter(query)
 }
 
 func (m *Model) trimLogs() {
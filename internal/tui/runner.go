@@ -3,14 +3,15 @@ package tui
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"sync"
+	"time"
 
-	"github.com/Geun-Oh/lx/internal/buffer"
 	"github.com/Geun-Oh/lx/internal/entry"
 	"github.com/Geun-Oh/lx/internal/filter"
 	"github.com/Geun-Oh/lx/internal/monitor"
-	"github.com/Geun-Oh/lx/internal/parser"
+	"github.com/Geun-Oh/lx/internal/pipeline"
 	"github.com/Geun-Oh/lx/internal/source"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -20,11 +21,18 @@ type RunConfig struct {
 	Source  source.Source
 	Filters *filter.Chain
 	Context *filter.ContextBuffer
-	Stats   *monitor.Stats
 	Rate    *monitor.RateDetector
-	Alerts  *monitor.AlertEngine
-	RingBuf *buffer.Ring
-	Grok    *parser.GrokParser
+
+	// Stages parse and enrich each line before filtering, and record the
+	// matches, as in the standard pipeline.
+	pipeline.Stages
+
+	// ShowSource prefixes each log line with its source name.
+	ShowSource bool
+
+	// ExportDir is where the e and E keys save the buffer (default: the
+	// current directory).
+	ExportDir string
 }
 
 // Run starts the TUI dashboard with a live source pipeline.
@@ -35,7 +43,10 @@ func Run(ctx context.Context, cfg *RunConfig) error {
 	defer cancel()
 
 	model := NewModel(cfg.Stats, cfg.Rate, cfg.Alerts, cfg.RingBuf, cfg.Source.Name())
+	model.ShowSource = cfg.ShowSource
+	model.ExportDir = cfg.ExportDir
 	program := tea.NewProgram(model, tea.WithAltScreen())
+	cfg.OnTemplate = func(tmpl string) { program.Send(TemplateMsg{Template: tmpl}) }
 
 	// Start the source and feed entries to the TUI via tea.Program.Send.
 	ch, err := cfg.Source.Start(ctx)
@@ -48,31 +59,19 @@ func Run(ctx context.Context, cfg *RunConfig) error {
 	go func() {
 		defer wg.Done()
 		for e := range ch {
-			cfg.Stats.RecordLine()
-
-			// Auto-detect level.
-			if e.Level == entry.LevelUnknown {
-				e.Level = filter.DetectLevel(e.Message)
-			}
-
-			// Parse structured fields via Grok (if configured).
-			if cfg.Grok != nil {
-				cfg.Grok.Parse(&e)
-			}
-
-			// Store in ring buffer.
-			if cfg.RingBuf != nil {
-				cfg.RingBuf.Push(e)
-			}
+			cfg.Enrich(&e)
 
 			// Apply context buffer.
 			if cfg.Context != nil {
 				entries := cfg.Context.Process(&e)
+				if cfg.Context.Rejected() {
+					cfg.Reject(&e)
+				}
 				for i := range entries {
-					cfg.Stats.RecordMatch()
+					triggered := cfg.Release(&entries[i])
 					program.Send(LogMsg(entries[i]))
-					cfg.Rate.Record()
-					checkAlerts(program, cfg.Alerts, &entries[i])
+					cfg.Rate.RecordEntry(&entries[i])
+					sendAlert(program, cfg, triggered, &entries[i])
 				}
 				continue
 			}
@@ -80,42 +79,91 @@ func Run(ctx context.Context, cfg *RunConfig) error {
 			// Apply filter chain.
 			if cfg.Filters != nil && cfg.Filters.Len() > 0 {
 				if !cfg.Filters.Match(&e) {
+					cfg.Reject(&e)
 					continue
 				}
 			}
 
-			cfg.Stats.RecordMatch()
+			triggered := cfg.Release(&e)
 
 			// Track rate and detect spikes.
-			if spiking := cfg.Rate.Record(); spiking {
-				program.Send(SpikeMsg{Rate: cfg.Rate.CurrentRate()})
+			if spike, ok := cfg.Rate.RecordEntry(&e); ok {
+				program.Send(spikeMsg(spike))
 			}
 
-			// Check alerts.
-			checkAlerts(program, cfg.Alerts, &e)
+			sendAlert(program, cfg, triggered, &e)
 
 			// Send to TUI.
 			program.Send(LogMsg(e))
 		}
 
+		if cfg.Context != nil {
+			for _, e := range cfg.Context.Flush() {
+				triggered := cfg.Release(&e)
+				program.Send(LogMsg(e))
+				sendAlert(program, cfg, triggered, &e)
+			}
+		}
+
 		program.Send(DoneMsg{})
 	}()
 
+	if cfg.Alerts != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			watchTimed(ctx, program, cfg)
+		}()
+	}
+
 	_, err = program.Run()
 
 	// Ensure source is stopped and consumer finishes.
 	cancel()
 	wg.Wait()
+	cfg.Notify.Close()
 
 	return err
 }
 
-func checkAlerts(p *tea.Program, alerts *monitor.AlertEngine, e *entry.LogEntry) {
-	if alerts == nil {
-		return
+// spikeMsg describes a detected spike, naming the detector's levels if it
+// has any.
+func spikeMsg(s monitor.Spike) SpikeMsg {
+	names := make([]string, len(s.Levels))
+	for i, l := range s.Levels {
+		names[i] = l.String()
 	}
-	triggered := alerts.Check(e)
+	return SpikeMsg{Rate: s.Rate, Levels: strings.Join(names, "/")}
+}
+
+// watchTimed checks the silence and rate alert rules and resolves the rules
+// that stopped firing every second until ctx is done.
+func watchTimed(ctx context.Context, p *tea.Program, cfg *RunConfig) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			triggered, resolved := cfg.Alerts.CheckTimed(now)
+			if len(triggered) > 0 {
+				e := cfg.Alerts.TimedEntry(triggered, now)
+				p.Send(AlertMsg{Rules: triggered, Entry: e, Severity: cfg.Alerts.Severity(triggered)})
+				cfg.Notify.Alert(triggered, &e, cfg.RingBuf)
+			}
+			if len(resolved) > 0 {
+				e := cfg.Alerts.ResolvedEntry(resolved, now)
+				p.Send(ResolvedMsg{Rules: resolved, Entry: e})
+				cfg.Notify.Resolve(resolved, &e)
+			}
+		}
+	}
+}
+
+// sendAlert shows the rules e triggered, if any, in the alert bar.
+func sendAlert(p *tea.Program, cfg *RunConfig, triggered []string, e *entry.LogEntry) {
 	if len(triggered) > 0 {
-		p.Send(AlertMsg{Rules: triggered, Entry: *e})
+		p.Send(AlertMsg{Rules: triggered, Entry: *e, Severity: cfg.Alerts.Severity(triggered)})
 	}
 }